@@ -2,11 +2,14 @@ package main
 
 import (
 	"log"
+	"os"
 	"time"
 
+	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/clickstream"
 	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/client"
 	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/handler"
 	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc"
 )
 
@@ -35,6 +38,18 @@ func main() {
 	client.InitOrderClient(orderConn)
 	client.InitStatisticsClient(statsConn)
 
+	// Clickstream events, kept out of the way of requests while NATS is down
+	natsURL := os.Getenv("NATS_HOSTS")
+	if natsURL == "" {
+		natsURL = nats.DefaultURL
+	}
+	nc, err := nats.Connect(natsURL, nats.RetryOnFailedConnect(true), nats.MaxReconnects(-1))
+	if err != nil {
+		log.Fatal("failed to connect to NATS:", err)
+	}
+	defer nc.Close()
+	clickstream.Init(nc)
+
 	r := gin.Default()
 	api := r.Group("/v1")
 	{
@@ -45,12 +60,17 @@ func main() {
 			inventory.PUT("/product", handler.UpdateProduct)
 			inventory.DELETE("/product/:id", handler.DeleteProduct)
 			inventory.GET("/products", handler.ListProducts)
+			inventory.GET("/products/batch", handler.BatchGetProducts)
 
 			inventory.GET("/category/:id", handler.GetCategoryByID)
 			inventory.POST("/category", handler.CreateCategory)
 			inventory.PUT("/category", handler.UpdateCategory)
 			inventory.DELETE("/category/:id", handler.DeleteCategory)
 			inventory.GET("/categories", handler.ListCategories)
+			inventory.GET("/categories/children", handler.ListChildCategories)
+			inventory.GET("/category/:id/subtree", handler.GetCategorySubtree)
+			inventory.GET("/category/:id/breadcrumbs", handler.GetCategoryBreadcrumbs)
+			inventory.PUT("/category/:id/parent", handler.MoveCategory)
 		}
 
 		orders := api.Group("/orders")
@@ -71,6 +91,15 @@ func main() {
 		{
 			statistics.GET("/user/:userId/orders", handler.GetUserOrdersStatistics)
 			statistics.GET("/users", handler.GetUserStatistics)
+			statistics.GET("/sales", handler.GetSalesStatistics)
+			statistics.GET("/top-products", handler.GetTopProducts)
+			statistics.GET("/heatmap", handler.GetOrderHeatmap)
+			statistics.GET("/active-users", handler.GetActiveUsers)
+			statistics.GET("/retention", handler.GetRetentionCohorts)
+			statistics.GET("/customers/:userId", handler.GetCustomerProfile)
+			statistics.GET("/segments/:segment", handler.ListSegmentMembers)
+			statistics.GET("/funnel", handler.GetConversionFunnel)
+			statistics.GET("/live", handler.WatchStatistics)
 		}
 	}
 
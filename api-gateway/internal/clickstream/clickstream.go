@@ -0,0 +1,89 @@
+// Package clickstream publishes what users do through the gateway to NATS,
+// where the statistics service turns it into conversion funnels.
+package clickstream
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nuid"
+)
+
+// Subjects of the events.
+const (
+	CategoryBrowsed  = "clickstream.category.browsed"
+	ProductViewed    = "clickstream.product.viewed"
+	OrderCreated     = "clickstream.order.created"
+	PaymentAttempted = "clickstream.payment.attempted"
+	PaymentCompleted = "clickstream.payment.completed"
+)
+
+// Headers identifying who a request is from. A session ID keeps the steps
+// of a visitor together from before they log in.
+const (
+	UserIDHeader    = "X-User-Id"
+	SessionIDHeader = "X-Session-Id"
+)
+
+// Event is the JSON published on the subject of its type.
+type Event struct {
+	EventID    string            `json:"event_id"`
+	UserID     string            `json:"user_id,omitempty"`
+	SessionID  string            `json:"session_id,omitempty"`
+	EntityKey  string            `json:"entity_key"`
+	EntityID   string            `json:"entity_id"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       map[string]string `json:"data,omitempty"`
+}
+
+var conn *nats.Conn
+
+// Init sets the connection events are published on. Without one nothing is
+// published.
+func Init(nc *nats.Conn) {
+	conn = nc
+}
+
+// Enabled reports whether events are published.
+func Enabled() bool {
+	return conn != nil
+}
+
+// Publish publishes the event of the request c without waiting for NATS.
+// userID is taken when the request has no user header. Events of nobody in
+// particular and failures are dropped, the request goes on regardless.
+func Publish(c *gin.Context, subject, userID, entityKey, entityID string, data map[string]string) {
+	if conn == nil {
+		return
+	}
+	evt := Event{
+		EventID:    nuid.Next(),
+		UserID:     c.GetHeader(UserIDHeader),
+		SessionID:  c.GetHeader(SessionIDHeader),
+		EntityKey:  entityKey,
+		EntityID:   entityID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+	if evt.UserID == "" {
+		evt.UserID = userID
+	}
+	if evt.UserID == "" && evt.SessionID == "" {
+		return
+	}
+
+	body, err := json.Marshal(evt)
+	if err != nil {
+		log.Printf("[Clickstream] marshal %s: %v", subject, err)
+		return
+	}
+	msg := nats.NewMsg(subject)
+	msg.Header.Set(nats.MsgIdHdr, evt.EventID)
+	msg.Data = body
+	if err := conn.PublishMsg(msg); err != nil {
+		log.Printf("[Clickstream] publish %s: %v", subject, err)
+	}
+}
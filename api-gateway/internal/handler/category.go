@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/clickstream"
 	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/client"
 	inventorypb "github.com/Neroframe/ecommerce-platform/api-gateway/proto/inventory"
 	"github.com/gin-gonic/gin"
@@ -47,6 +48,7 @@ func GetCategoryByID(c *gin.Context) {
 		return
 	}
 
+	clickstream.Publish(c, clickstream.CategoryBrowsed, "", "category_id", id, nil)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -102,3 +104,79 @@ func ListCategories(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+func ListChildCategories(c *gin.Context) {
+	parentID := c.Query("parent_id")
+
+	log.Printf("Listing child categories of: %q", parentID)
+
+	resp, err := client.Inventory.ListChildCategories(context.Background(), &inventorypb.ListChildCategoriesRequest{
+		ParentId: parentID,
+	})
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error listing child categories: %v", st.Message())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetCategorySubtree(c *gin.Context) {
+	id := c.Param("id")
+
+	log.Printf("Fetching category subtree: %s", id)
+
+	resp, err := client.Inventory.GetCategorySubtree(context.Background(), &inventorypb.GetCategorySubtreeRequest{
+		Id: id,
+	})
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching category subtree: %v", st.Message())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func GetCategoryBreadcrumbs(c *gin.Context) {
+	id := c.Param("id")
+
+	log.Printf("Fetching category breadcrumbs: %s", id)
+
+	resp, err := client.Inventory.GetCategoryBreadcrumbs(context.Background(), &inventorypb.GetCategoryBreadcrumbsRequest{
+		Id: id,
+	})
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching category breadcrumbs: %v", st.Message())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func MoveCategory(c *gin.Context) {
+	var req inventorypb.MoveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Error binding category move data: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category data"})
+		return
+	}
+	req.Id = c.Param("id")
+
+	log.Printf("Moving category %s under %q", req.Id, req.NewParentId)
+
+	resp, err := client.Inventory.MoveCategory(context.Background(), &req)
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error moving category: %v", st.Message())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -5,9 +5,11 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/clickstream"
 	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/client"
 	orderpb "github.com/Neroframe/ecommerce-platform/api-gateway/proto/order"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -25,10 +27,15 @@ func CreateOrder(c *gin.Context) {
 	if err != nil {
 		st, _ := status.FromError(err)
 		log.Printf("Error creating order: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
 		return
 	}
 
+	clickstream.Publish(c, clickstream.OrderCreated, req.UserId, "order_id", resp.Id, nil)
 	c.JSON(http.StatusCreated, resp)
 }
 
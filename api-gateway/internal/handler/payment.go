@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/clickstream"
 	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/client"
 	orderpb "github.com/Neroframe/ecommerce-platform/api-gateway/proto/order"
 	"github.com/gin-gonic/gin"
@@ -20,6 +21,10 @@ func CreatePayment(c *gin.Context) {
 	}
 
 	log.Printf("Creating payment for order ID: %s", req.OrderId)
+	userID := orderUser(c, req.OrderId)
+	clickstream.Publish(c, clickstream.PaymentAttempted, userID, "order_id", req.OrderId, map[string]string{
+		"method": req.PaymentMethod,
+	})
 
 	resp, err := client.Payment.CreatePayment(context.Background(), &req)
 	if err != nil {
@@ -29,9 +34,29 @@ func CreatePayment(c *gin.Context) {
 		return
 	}
 
+	if resp.Status == "Completed" {
+		clickstream.Publish(c, clickstream.PaymentCompleted, userID, "payment_id", resp.PaymentId, map[string]string{
+			"order_id": req.OrderId,
+		})
+	}
 	c.JSON(http.StatusCreated, resp)
 }
 
+// orderUser returns the user of the order for the clickstream events of a
+// payment made without the user header, "" if it cannot be looked up.
+func orderUser(c *gin.Context, orderID string) string {
+	if !clickstream.Enabled() || c.GetHeader(clickstream.UserIDHeader) != "" {
+		return ""
+	}
+	resp, err := client.Order.GetOrderByID(context.Background(), &orderpb.GetOrderRequest{Id: orderID})
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching order of payment: %v", st.Message())
+		return ""
+	}
+	return resp.UserId
+}
+
 func GetPaymentByID(c *gin.Context) {
 	id := c.Param("id")
 	log.Printf("Fetching payment by ID: %s", id)
@@ -2,12 +2,19 @@ package handler
 
 import (
 	"context"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/clickstream"
 	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/client"
 	inventorypb "github.com/Neroframe/ecommerce-platform/api-gateway/proto/inventory"
+	statpb "github.com/Neroframe/ecommerce-platform/api-gateway/proto/statistics"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func CreateProduct(c *gin.Context) {
@@ -26,6 +33,16 @@ func CreateProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// relatedTimeout bounds the wait for related products, a product page is
+// served without them rather than late.
+const relatedTimeout = 300 * time.Millisecond
+
+// productDetail is a product with the products bought together with it.
+type productDetail struct {
+	*inventorypb.ProductResponse
+	RelatedProducts []*statpb.RelatedProduct `json:"related_products"`
+}
+
 func GetProductByID(c *gin.Context) {
 	id := c.Param("id")
 
@@ -37,7 +54,19 @@ func GetProductByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	detail := productDetail{ProductResponse: resp, RelatedProducts: []*statpb.RelatedProduct{}}
+	ctx, cancel := context.WithTimeout(context.Background(), relatedTimeout)
+	defer cancel()
+	related, err := client.Statistics.GetRelatedProducts(ctx, &statpb.RelatedProductsRequest{ProductId: id})
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching related products of %s: %v", id, st.Message())
+	} else if related.Products != nil {
+		detail.RelatedProducts = related.Products
+	}
+
+	clickstream.Publish(c, clickstream.ProductViewed, "", "product_id", id, nil)
+	c.JSON(http.StatusOK, detail)
 }
 
 func UpdateProduct(c *gin.Context) {
@@ -49,7 +78,17 @@ func UpdateProduct(c *gin.Context) {
 
 	resp, err := client.Inventory.UpdateProduct(context.Background(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update product"})
+		st, _ := status.FromError(err)
+		switch st.Code() {
+		case codes.Aborted:
+			c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+		case codes.NotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+		case codes.InvalidArgument:
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update product"})
+		}
 		return
 	}
 
@@ -72,7 +111,10 @@ func DeleteProduct(c *gin.Context) {
 }
 
 func ListProducts(c *gin.Context) {
-	req := &inventorypb.ListProductsRequest{}
+	req := &inventorypb.ListProductsRequest{
+		Category:           c.Query("category"),
+		IncludeDescendants: c.Query("include_descendants") == "true",
+	}
 
 	resp, err := client.Inventory.ListProducts(context.Background(), req)
 	if err != nil {
@@ -80,5 +122,36 @@ func ListProducts(c *gin.Context) {
 		return
 	}
 
+	if req.Category != "" {
+		clickstream.Publish(c, clickstream.CategoryBrowsed, "", "category_id", req.Category, nil)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// BatchGetProducts serves GET /products/batch?ids=a,b,c.
+func BatchGetProducts(c *gin.Context) {
+	var ids []string
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids query parameter is required"})
+		return
+	}
+
+	resp, err := client.Inventory.BatchGetProducts(context.Background(), &inventorypb.BatchGetProductsRequest{Ids: ids})
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching products batch: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
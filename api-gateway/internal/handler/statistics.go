@@ -2,24 +2,41 @@ package handler
 
 import (
 	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Neroframe/ecommerce-platform/api-gateway/internal/client"
 	statpb "github.com/Neroframe/ecommerce-platform/api-gateway/proto/statistics"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// GetUserOrdersStatistics serves GET /statistics/user/:userId/orders?tz=Europe/Berlin,
+// tz is the time zone of the peak order hour, UTC by default.
 func GetUserOrdersStatistics(c *gin.Context) {
 	userId := c.Param("userId")
 
 	resp, err := client.Statistics.GetUserOrdersStatistics(context.Background(), &statpb.UserOrderStatisticsRequest{
-		UserId: userId,
+		UserId:   userId,
+		TimeZone: c.Query("tz"),
 	})
 	if err != nil {
 		st, _ := status.FromError(err)
 		log.Printf("Error fetching user order stats: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
 		return
 	}
@@ -38,3 +55,504 @@ func GetUserStatistics(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+var granularities = map[string]statpb.Granularity{
+	"":      statpb.Granularity_GRANULARITY_DAY,
+	"day":   statpb.Granularity_GRANULARITY_DAY,
+	"week":  statpb.Granularity_GRANULARITY_WEEK,
+	"month": statpb.Granularity_GRANULARITY_MONTH,
+}
+
+// GetSalesStatistics serves
+// GET /statistics/sales?from=2025-01-01&to=2025-02-01&granularity=week&currency=USD.
+// from and to are RFC 3339 timestamps or dates in UTC, all parameters are
+// optional.
+func GetSalesStatistics(c *gin.Context) {
+	granularity, ok := granularities[c.Query("granularity")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be day, week or month"})
+		return
+	}
+	req := &statpb.SalesStatisticsRequest{
+		Granularity:  granularity,
+		CurrencyCode: c.Query("currency"),
+	}
+
+	var err error
+	if req.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := client.Statistics.GetSalesStatistics(context.Background(), req)
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching sales stats: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+var rankingMetrics = map[string]statpb.RankingMetric{
+	"":        statpb.RankingMetric_RANKING_METRIC_UNITS_SOLD,
+	"units":   statpb.RankingMetric_RANKING_METRIC_UNITS_SOLD,
+	"revenue": statpb.RankingMetric_RANKING_METRIC_REVENUE,
+	"orders":  statpb.RankingMetric_RANKING_METRIC_ORDER_COUNT,
+}
+
+var rankingDimensions = map[string]statpb.RankingDimension{
+	"":         statpb.RankingDimension_RANKING_DIMENSION_PRODUCT,
+	"product":  statpb.RankingDimension_RANKING_DIMENSION_PRODUCT,
+	"category": statpb.RankingDimension_RANKING_DIMENSION_CATEGORY,
+}
+
+// GetTopProducts serves
+// GET /statistics/top-products?from=2025-01-01&to=2025-02-01&metric=revenue&by=category&limit=5&currency=USD.
+// All parameters are optional, see GetSalesStatistics for from and to.
+func GetTopProducts(c *gin.Context) {
+	metric, ok := rankingMetrics[c.Query("metric")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metric must be units, revenue or orders"})
+		return
+	}
+	dimension, ok := rankingDimensions[c.Query("by")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "by must be product or category"})
+		return
+	}
+	req := &statpb.TopProductsRequest{
+		Metric:       metric,
+		Dimension:    dimension,
+		CurrencyCode: c.Query("currency"),
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+			return
+		}
+		req.Limit = int32(limit)
+	}
+
+	var err error
+	if req.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := client.Statistics.GetTopProducts(context.Background(), req)
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching top products: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetOrderHeatmap serves
+// GET /statistics/heatmap?user_id=user123&tz=Europe/Berlin&from=2025-01-01&to=2025-02-01.
+// Without user_id it covers all users, see GetSalesStatistics for from and to.
+func GetOrderHeatmap(c *gin.Context) {
+	req := &statpb.OrderHeatmapRequest{
+		UserId:   c.Query("user_id"),
+		TimeZone: c.Query("tz"),
+	}
+
+	var err error
+	if req.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := client.Statistics.GetOrderHeatmap(context.Background(), req)
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching order heatmap: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+var countModes = map[string]statpb.CountMode{
+	"":            statpb.CountMode_COUNT_MODE_AUTO,
+	"auto":        statpb.CountMode_COUNT_MODE_AUTO,
+	"exact":       statpb.CountMode_COUNT_MODE_EXACT,
+	"approximate": statpb.CountMode_COUNT_MODE_APPROXIMATE,
+}
+
+// GetActiveUsers serves
+// GET /statistics/active-users?from=2025-01-01&to=2025-04-01&mode=approximate.
+// The range is widened to whole UTC days and defaults to the last 30 days;
+// mode is auto, exact or approximate.
+func GetActiveUsers(c *gin.Context) {
+	mode, ok := countModes[c.Query("mode")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be auto, exact or approximate"})
+		return
+	}
+	req := &statpb.ActiveUsersRequest{Mode: mode}
+
+	var err error
+	if req.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := client.Statistics.GetActiveUsers(context.Background(), req)
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching active users: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetCustomerProfile serves GET /statistics/customers/:userId, the RFM
+// segment and lifetime value of a user.
+func GetCustomerProfile(c *gin.Context) {
+	resp, err := client.Statistics.GetCustomerProfile(context.Background(), &statpb.CustomerProfileRequest{
+		UserId: c.Param("userId"),
+	})
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching customer profile: %v", st.Message())
+		switch st.Code() {
+		case codes.NotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+		case codes.InvalidArgument:
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+var customerSegments = map[string]statpb.CustomerSegment{
+	"champions":       statpb.CustomerSegment_CUSTOMER_SEGMENT_CHAMPIONS,
+	"loyal":           statpb.CustomerSegment_CUSTOMER_SEGMENT_LOYAL,
+	"new":             statpb.CustomerSegment_CUSTOMER_SEGMENT_NEW,
+	"promising":       statpb.CustomerSegment_CUSTOMER_SEGMENT_PROMISING,
+	"needs_attention": statpb.CustomerSegment_CUSTOMER_SEGMENT_NEEDS_ATTENTION,
+	"at_risk":         statpb.CustomerSegment_CUSTOMER_SEGMENT_AT_RISK,
+	"hibernating":     statpb.CustomerSegment_CUSTOMER_SEGMENT_HIBERNATING,
+}
+
+// ListSegmentMembers serves
+// GET /statistics/segments/:segment?limit=100&page_token=USER_ID, the
+// customers of a segment by user ID.
+func ListSegmentMembers(c *gin.Context) {
+	segment, ok := customerSegments[c.Param("segment")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "segment must be champions, loyal, new, promising, needs_attention, at_risk or hibernating"})
+		return
+	}
+	req := &statpb.SegmentMembersRequest{
+		Segment:   segment,
+		PageToken: c.Query("page_token"),
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+			return
+		}
+		req.Limit = int32(limit)
+	}
+
+	resp, err := client.Statistics.ListSegmentMembers(context.Background(), req)
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching segment members: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+var cohortGranularities = map[string]statpb.Granularity{
+	"":      statpb.Granularity_GRANULARITY_WEEK,
+	"week":  statpb.Granularity_GRANULARITY_WEEK,
+	"month": statpb.Granularity_GRANULARITY_MONTH,
+}
+
+// GetRetentionCohorts serves
+// GET /statistics/retention?granularity=month&periods=6&from=2025-01-01&to=2025-07-01&format=csv.
+// All parameters are optional, see GetSalesStatistics for from and to;
+// format=csv returns the retention shares as a CSV file, one row per cohort.
+func GetRetentionCohorts(c *gin.Context) {
+	granularity, ok := cohortGranularities[c.Query("granularity")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be week or month"})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+	req := &statpb.RetentionCohortsRequest{Granularity: granularity}
+	if v := c.Query("periods"); v != "" {
+		periods, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "periods must be a number"})
+			return
+		}
+		req.Periods = int32(periods)
+	}
+
+	var err error
+	if req.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := client.Statistics.GetRetentionCohorts(context.Background(), req)
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching retention cohorts: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	unit := "week"
+	if resp.Granularity == statpb.Granularity_GRANULARITY_MONTH {
+		unit = "month"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="retention_%s.csv"`, unit))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := writeRetentionCSV(c.Writer, unit, resp); err != nil {
+		log.Printf("Error writing retention CSV: %v", err)
+	}
+}
+
+// writeRetentionCSV writes a header and one row per cohort: the start of the
+// cohort, its size and the share retained in each period after it, empty
+// for periods that have not begun.
+func writeRetentionCSV(out io.Writer, unit string, resp *statpb.RetentionCohortsResponse) error {
+	w := csv.NewWriter(out)
+	header := []string{"cohort", "size"}
+	for k := 1; k <= int(resp.Periods); k++ {
+		header = append(header, fmt.Sprintf("%s_%d", unit, k))
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, cohort := range resp.Cohorts {
+		row := make([]string, len(header))
+		row[0] = cohort.PeriodStart.AsTime().Format(time.DateOnly)
+		row[1] = strconv.FormatInt(cohort.Size, 10)
+		for k, share := range cohort.Retention {
+			row[2+k] = strconv.FormatFloat(share, 'f', 4, 64)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+var funnelSteps = map[string]statpb.FunnelStep{
+	"category_browsed":  statpb.FunnelStep_FUNNEL_STEP_CATEGORY_BROWSED,
+	"product_viewed":    statpb.FunnelStep_FUNNEL_STEP_PRODUCT_VIEWED,
+	"order_created":     statpb.FunnelStep_FUNNEL_STEP_ORDER_CREATED,
+	"payment_attempted": statpb.FunnelStep_FUNNEL_STEP_PAYMENT_ATTEMPTED,
+	"payment_completed": statpb.FunnelStep_FUNNEL_STEP_PAYMENT_COMPLETED,
+}
+
+// GetConversionFunnel serves
+// GET /statistics/funnel?from=2025-04-01&to=2025-05-01&steps=category_browsed,product_viewed,order_created.
+// The range defaults to the last 30 days and the steps to product_viewed,
+// order_created, payment_attempted and payment_completed.
+func GetConversionFunnel(c *gin.Context) {
+	req := &statpb.ConversionFunnelRequest{}
+	if steps := c.Query("steps"); steps != "" {
+		for _, name := range strings.Split(steps, ",") {
+			step, ok := funnelSteps[strings.TrimSpace(name)]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown funnel step %q", name)})
+				return
+			}
+			req.Steps = append(req.Steps, step)
+		}
+	}
+
+	var err error
+	if req.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := client.Statistics.GetConversionFunnel(context.Background(), req)
+	if err != nil {
+		st, _ := status.FromError(err)
+		log.Printf("Error fetching conversion funnel: %v", st.Message())
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// watchReconnectWait is the pause before the stream of live statistics is
+// opened again after the service closed it.
+const watchReconnectWait = time.Second
+
+// WatchStatistics serves GET /statistics/live?interval=5s as server-sent
+// events: a "statistics" event with the live statistics whenever they
+// change, at most once per interval. The service closes long streams, they
+// are opened again until the client goes away.
+func WatchStatistics(c *gin.Context) {
+	req := &statpb.WatchStatisticsRequest{}
+	if v := c.Query("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be a duration such as 5s"})
+			return
+		}
+		req.MinInterval = durationpb.New(d)
+	}
+
+	ctx := c.Request.Context()
+	started := false
+	for {
+		err := relayStatistics(ctx, c, req, &started)
+		if ctx.Err() != nil {
+			return
+		}
+		st, _ := status.FromError(err)
+		if !started {
+			log.Printf("Error watching statistics: %v", st.Message())
+			if st.Code() == codes.InvalidArgument {
+				c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+			return
+		}
+		if err != nil && st.Code() != codes.Unavailable {
+			log.Printf("Error watching statistics: %v", st.Message())
+			c.SSEvent("error", gin.H{"error": st.Message()})
+			c.Writer.Flush()
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchReconnectWait):
+		}
+	}
+}
+
+// relayStatistics opens a stream of live statistics and writes them as
+// events until it ends, which returns nil. The event stream headers are
+// written with the first statistics, and started is set then.
+func relayStatistics(ctx context.Context, c *gin.Context, req *statpb.WatchStatisticsRequest, started *bool) error {
+	stream, err := client.Statistics.WatchStatistics(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		stats, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !*started {
+			c.Header("Content-Type", "text/event-stream")
+			c.Header("Cache-Control", "no-cache")
+			c.Header("Connection", "keep-alive")
+			c.Header("X-Accel-Buffering", "no")
+			c.Status(http.StatusOK)
+			*started = true
+		}
+		c.SSEvent("statistics", stats)
+		c.Writer.Flush()
+	}
+}
+
+// parseTimeQuery returns nil if the query parameter is not set.
+func parseTimeQuery(c *gin.Context, key string) (*timestamppb.Timestamp, error) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return timestamppb.New(t), nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return timestamppb.New(t), nil
+	}
+	return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", key)
+}
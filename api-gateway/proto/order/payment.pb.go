@@ -2,7 +2,7 @@
 // versions:
 // 	protoc-gen-go v1.36.6
 // 	protoc        v3.12.4
-// source: order/payment.proto
+// source: proto/payment.proto
 
 package orderpb
 
@@ -24,15 +24,15 @@ const (
 type CreatePaymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
 	PaymentMethod string                 `protobuf:"bytes,3,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
+	Amount        *Money                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreatePaymentRequest) Reset() {
 	*x = CreatePaymentRequest{}
-	mi := &file_order_payment_proto_msgTypes[0]
+	mi := &file_proto_payment_proto_msgTypes[0]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -44,7 +44,7 @@ func (x *CreatePaymentRequest) String() string {
 func (*CreatePaymentRequest) ProtoMessage() {}
 
 func (x *CreatePaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_payment_proto_msgTypes[0]
+	mi := &file_proto_payment_proto_msgTypes[0]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -57,7 +57,7 @@ func (x *CreatePaymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreatePaymentRequest.ProtoReflect.Descriptor instead.
 func (*CreatePaymentRequest) Descriptor() ([]byte, []int) {
-	return file_order_payment_proto_rawDescGZIP(), []int{0}
+	return file_proto_payment_proto_rawDescGZIP(), []int{0}
 }
 
 func (x *CreatePaymentRequest) GetOrderId() string {
@@ -67,18 +67,18 @@ func (x *CreatePaymentRequest) GetOrderId() string {
 	return ""
 }
 
-func (x *CreatePaymentRequest) GetAmount() float64 {
+func (x *CreatePaymentRequest) GetPaymentMethod() string {
 	if x != nil {
-		return x.Amount
+		return x.PaymentMethod
 	}
-	return 0
+	return ""
 }
 
-func (x *CreatePaymentRequest) GetPaymentMethod() string {
+func (x *CreatePaymentRequest) GetAmount() *Money {
 	if x != nil {
-		return x.PaymentMethod
+		return x.Amount
 	}
-	return ""
+	return nil
 }
 
 type PaymentResponse struct {
@@ -86,13 +86,14 @@ type PaymentResponse struct {
 	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
 	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
 	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Amount        *Money                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PaymentResponse) Reset() {
 	*x = PaymentResponse{}
-	mi := &file_order_payment_proto_msgTypes[1]
+	mi := &file_proto_payment_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -104,7 +105,7 @@ func (x *PaymentResponse) String() string {
 func (*PaymentResponse) ProtoMessage() {}
 
 func (x *PaymentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_payment_proto_msgTypes[1]
+	mi := &file_proto_payment_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -117,7 +118,7 @@ func (x *PaymentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PaymentResponse.ProtoReflect.Descriptor instead.
 func (*PaymentResponse) Descriptor() ([]byte, []int) {
-	return file_order_payment_proto_rawDescGZIP(), []int{1}
+	return file_proto_payment_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *PaymentResponse) GetPaymentId() string {
@@ -141,6 +142,13 @@ func (x *PaymentResponse) GetMessage() string {
 	return ""
 }
 
+func (x *PaymentResponse) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
 type GetPaymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
@@ -150,7 +158,7 @@ type GetPaymentRequest struct {
 
 func (x *GetPaymentRequest) Reset() {
 	*x = GetPaymentRequest{}
-	mi := &file_order_payment_proto_msgTypes[2]
+	mi := &file_proto_payment_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -162,7 +170,7 @@ func (x *GetPaymentRequest) String() string {
 func (*GetPaymentRequest) ProtoMessage() {}
 
 func (x *GetPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_payment_proto_msgTypes[2]
+	mi := &file_proto_payment_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -175,7 +183,7 @@ func (x *GetPaymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPaymentRequest.ProtoReflect.Descriptor instead.
 func (*GetPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_order_payment_proto_rawDescGZIP(), []int{2}
+	return file_proto_payment_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *GetPaymentRequest) GetPaymentId() string {
@@ -185,20 +193,21 @@ func (x *GetPaymentRequest) GetPaymentId() string {
 	return ""
 }
 
-var File_order_payment_proto protoreflect.FileDescriptor
+var File_proto_payment_proto protoreflect.FileDescriptor
 
-const file_order_payment_proto_rawDesc = "" +
+const file_proto_payment_proto_rawDesc = "" +
 	"\n" +
-	"\x13order/payment.proto\x12\x05order\"p\n" +
+	"\x13proto/payment.proto\x12\x05order\x1a\x11proto/money.proto\"\x84\x01\n" +
 	"\x14CreatePaymentRequest\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x01R\x06amount\x12%\n" +
-	"\x0epayment_method\x18\x03 \x01(\tR\rpaymentMethod\"b\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12%\n" +
+	"\x0epayment_method\x18\x03 \x01(\tR\rpaymentMethod\x12$\n" +
+	"\x06amount\x18\x04 \x01(\v2\f.order.MoneyR\x06amountJ\x04\b\x02\x10\x03\"\x88\x01\n" +
 	"\x0fPaymentResponse\x12\x1d\n" +
 	"\n" +
 	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"2\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12$\n" +
+	"\x06amount\x18\x04 \x01(\v2\f.order.MoneyR\x06amount\"2\n" +
 	"\x11GetPaymentRequest\x12\x1d\n" +
 	"\n" +
 	"payment_id\x18\x01 \x01(\tR\tpaymentId2\x9a\x01\n" +
@@ -207,55 +216,59 @@ const file_order_payment_proto_rawDesc = "" +
 	"\x0eGetPaymentByID\x12\x18.order.GetPaymentRequest\x1a\x16.order.PaymentResponseBEZCgithub.com/Neroframe/ecommerce-platform/order-service/proto;orderpbb\x06proto3"
 
 var (
-	file_order_payment_proto_rawDescOnce sync.Once
-	file_order_payment_proto_rawDescData []byte
+	file_proto_payment_proto_rawDescOnce sync.Once
+	file_proto_payment_proto_rawDescData []byte
 )
 
-func file_order_payment_proto_rawDescGZIP() []byte {
-	file_order_payment_proto_rawDescOnce.Do(func() {
-		file_order_payment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_order_payment_proto_rawDesc), len(file_order_payment_proto_rawDesc)))
+func file_proto_payment_proto_rawDescGZIP() []byte {
+	file_proto_payment_proto_rawDescOnce.Do(func() {
+		file_proto_payment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_payment_proto_rawDesc), len(file_proto_payment_proto_rawDesc)))
 	})
-	return file_order_payment_proto_rawDescData
+	return file_proto_payment_proto_rawDescData
 }
 
-var file_order_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
-var file_order_payment_proto_goTypes = []any{
+var file_proto_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_payment_proto_goTypes = []any{
 	(*CreatePaymentRequest)(nil), // 0: order.CreatePaymentRequest
 	(*PaymentResponse)(nil),      // 1: order.PaymentResponse
 	(*GetPaymentRequest)(nil),    // 2: order.GetPaymentRequest
+	(*Money)(nil),                // 3: order.Money
 }
-var file_order_payment_proto_depIdxs = []int32{
-	0, // 0: order.PaymentService.CreatePayment:input_type -> order.CreatePaymentRequest
-	2, // 1: order.PaymentService.GetPaymentByID:input_type -> order.GetPaymentRequest
-	1, // 2: order.PaymentService.CreatePayment:output_type -> order.PaymentResponse
-	1, // 3: order.PaymentService.GetPaymentByID:output_type -> order.PaymentResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+var file_proto_payment_proto_depIdxs = []int32{
+	3, // 0: order.CreatePaymentRequest.amount:type_name -> order.Money
+	3, // 1: order.PaymentResponse.amount:type_name -> order.Money
+	0, // 2: order.PaymentService.CreatePayment:input_type -> order.CreatePaymentRequest
+	2, // 3: order.PaymentService.GetPaymentByID:input_type -> order.GetPaymentRequest
+	1, // 4: order.PaymentService.CreatePayment:output_type -> order.PaymentResponse
+	1, // 5: order.PaymentService.GetPaymentByID:output_type -> order.PaymentResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
-func init() { file_order_payment_proto_init() }
-func file_order_payment_proto_init() {
-	if File_order_payment_proto != nil {
+func init() { file_proto_payment_proto_init() }
+func file_proto_payment_proto_init() {
+	if File_proto_payment_proto != nil {
 		return
 	}
+	file_proto_money_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: unsafe.Slice(unsafe.StringData(file_order_payment_proto_rawDesc), len(file_order_payment_proto_rawDesc)),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_payment_proto_rawDesc), len(file_proto_payment_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
-		GoTypes:           file_order_payment_proto_goTypes,
-		DependencyIndexes: file_order_payment_proto_depIdxs,
-		MessageInfos:      file_order_payment_proto_msgTypes,
+		GoTypes:           file_proto_payment_proto_goTypes,
+		DependencyIndexes: file_proto_payment_proto_depIdxs,
+		MessageInfos:      file_proto_payment_proto_msgTypes,
 	}.Build()
-	File_order_payment_proto = out.File
-	file_order_payment_proto_goTypes = nil
-	file_order_payment_proto_depIdxs = nil
+	File_proto_payment_proto = out.File
+	file_proto_payment_proto_goTypes = nil
+	file_proto_payment_proto_depIdxs = nil
 }
@@ -2,7 +2,7 @@
 // versions:
 // - protoc-gen-go-grpc v1.5.1
 // - protoc             v3.12.4
-// source: order/payment.proto
+// source: proto/payment.proto
 
 package orderpb
 
@@ -155,5 +155,5 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
-	Metadata: "order/payment.proto",
+	Metadata: "proto/payment.proto",
 }
@@ -21,6 +21,17 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	StatisticsService_GetUserOrdersStatistics_FullMethodName = "/statistics.StatisticsService/GetUserOrdersStatistics"
 	StatisticsService_GetUserStatistics_FullMethodName       = "/statistics.StatisticsService/GetUserStatistics"
+	StatisticsService_GetSalesStatistics_FullMethodName      = "/statistics.StatisticsService/GetSalesStatistics"
+	StatisticsService_GetTopProducts_FullMethodName          = "/statistics.StatisticsService/GetTopProducts"
+	StatisticsService_GetOrderHeatmap_FullMethodName         = "/statistics.StatisticsService/GetOrderHeatmap"
+	StatisticsService_GetActiveUsers_FullMethodName          = "/statistics.StatisticsService/GetActiveUsers"
+	StatisticsService_GetRetentionCohorts_FullMethodName     = "/statistics.StatisticsService/GetRetentionCohorts"
+	StatisticsService_GetCustomerProfile_FullMethodName      = "/statistics.StatisticsService/GetCustomerProfile"
+	StatisticsService_ListSegmentMembers_FullMethodName      = "/statistics.StatisticsService/ListSegmentMembers"
+	StatisticsService_GetConversionFunnel_FullMethodName     = "/statistics.StatisticsService/GetConversionFunnel"
+	StatisticsService_GetRelatedProducts_FullMethodName      = "/statistics.StatisticsService/GetRelatedProducts"
+	StatisticsService_WatchStatistics_FullMethodName         = "/statistics.StatisticsService/WatchStatistics"
+	StatisticsService_RebuildRollups_FullMethodName          = "/statistics.StatisticsService/RebuildRollups"
 )
 
 // StatisticsServiceClient is the client API for StatisticsService service.
@@ -29,6 +40,18 @@ const (
 type StatisticsServiceClient interface {
 	GetUserOrdersStatistics(ctx context.Context, in *UserOrderStatisticsRequest, opts ...grpc.CallOption) (*UserOrderStatisticsResponse, error)
 	GetUserStatistics(ctx context.Context, in *UserStatisticsRequest, opts ...grpc.CallOption) (*UserStatisticsResponse, error)
+	GetSalesStatistics(ctx context.Context, in *SalesStatisticsRequest, opts ...grpc.CallOption) (*SalesStatisticsResponse, error)
+	GetTopProducts(ctx context.Context, in *TopProductsRequest, opts ...grpc.CallOption) (*TopProductsResponse, error)
+	GetOrderHeatmap(ctx context.Context, in *OrderHeatmapRequest, opts ...grpc.CallOption) (*OrderHeatmapResponse, error)
+	GetActiveUsers(ctx context.Context, in *ActiveUsersRequest, opts ...grpc.CallOption) (*ActiveUsersResponse, error)
+	GetRetentionCohorts(ctx context.Context, in *RetentionCohortsRequest, opts ...grpc.CallOption) (*RetentionCohortsResponse, error)
+	GetCustomerProfile(ctx context.Context, in *CustomerProfileRequest, opts ...grpc.CallOption) (*CustomerProfileResponse, error)
+	ListSegmentMembers(ctx context.Context, in *SegmentMembersRequest, opts ...grpc.CallOption) (*SegmentMembersResponse, error)
+	GetConversionFunnel(ctx context.Context, in *ConversionFunnelRequest, opts ...grpc.CallOption) (*ConversionFunnelResponse, error)
+	GetRelatedProducts(ctx context.Context, in *RelatedProductsRequest, opts ...grpc.CallOption) (*RelatedProductsResponse, error)
+	WatchStatistics(ctx context.Context, in *WatchStatisticsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LiveStatistics], error)
+	// admin
+	RebuildRollups(ctx context.Context, in *RebuildRollupsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RebuildRollupsProgress], error)
 }
 
 type statisticsServiceClient struct {
@@ -59,12 +82,152 @@ func (c *statisticsServiceClient) GetUserStatistics(ctx context.Context, in *Use
 	return out, nil
 }
 
+func (c *statisticsServiceClient) GetSalesStatistics(ctx context.Context, in *SalesStatisticsRequest, opts ...grpc.CallOption) (*SalesStatisticsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SalesStatisticsResponse)
+	err := c.cc.Invoke(ctx, StatisticsService_GetSalesStatistics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticsServiceClient) GetTopProducts(ctx context.Context, in *TopProductsRequest, opts ...grpc.CallOption) (*TopProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TopProductsResponse)
+	err := c.cc.Invoke(ctx, StatisticsService_GetTopProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticsServiceClient) GetOrderHeatmap(ctx context.Context, in *OrderHeatmapRequest, opts ...grpc.CallOption) (*OrderHeatmapResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderHeatmapResponse)
+	err := c.cc.Invoke(ctx, StatisticsService_GetOrderHeatmap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticsServiceClient) GetActiveUsers(ctx context.Context, in *ActiveUsersRequest, opts ...grpc.CallOption) (*ActiveUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ActiveUsersResponse)
+	err := c.cc.Invoke(ctx, StatisticsService_GetActiveUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticsServiceClient) GetRetentionCohorts(ctx context.Context, in *RetentionCohortsRequest, opts ...grpc.CallOption) (*RetentionCohortsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RetentionCohortsResponse)
+	err := c.cc.Invoke(ctx, StatisticsService_GetRetentionCohorts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticsServiceClient) GetCustomerProfile(ctx context.Context, in *CustomerProfileRequest, opts ...grpc.CallOption) (*CustomerProfileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CustomerProfileResponse)
+	err := c.cc.Invoke(ctx, StatisticsService_GetCustomerProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticsServiceClient) ListSegmentMembers(ctx context.Context, in *SegmentMembersRequest, opts ...grpc.CallOption) (*SegmentMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SegmentMembersResponse)
+	err := c.cc.Invoke(ctx, StatisticsService_ListSegmentMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticsServiceClient) GetConversionFunnel(ctx context.Context, in *ConversionFunnelRequest, opts ...grpc.CallOption) (*ConversionFunnelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConversionFunnelResponse)
+	err := c.cc.Invoke(ctx, StatisticsService_GetConversionFunnel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticsServiceClient) GetRelatedProducts(ctx context.Context, in *RelatedProductsRequest, opts ...grpc.CallOption) (*RelatedProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RelatedProductsResponse)
+	err := c.cc.Invoke(ctx, StatisticsService_GetRelatedProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticsServiceClient) WatchStatistics(ctx context.Context, in *WatchStatisticsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LiveStatistics], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StatisticsService_ServiceDesc.Streams[0], StatisticsService_WatchStatistics_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchStatisticsRequest, LiveStatistics]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StatisticsService_WatchStatisticsClient = grpc.ServerStreamingClient[LiveStatistics]
+
+func (c *statisticsServiceClient) RebuildRollups(ctx context.Context, in *RebuildRollupsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RebuildRollupsProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StatisticsService_ServiceDesc.Streams[1], StatisticsService_RebuildRollups_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RebuildRollupsRequest, RebuildRollupsProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StatisticsService_RebuildRollupsClient = grpc.ServerStreamingClient[RebuildRollupsProgress]
+
 // StatisticsServiceServer is the server API for StatisticsService service.
 // All implementations must embed UnimplementedStatisticsServiceServer
 // for forward compatibility.
 type StatisticsServiceServer interface {
 	GetUserOrdersStatistics(context.Context, *UserOrderStatisticsRequest) (*UserOrderStatisticsResponse, error)
 	GetUserStatistics(context.Context, *UserStatisticsRequest) (*UserStatisticsResponse, error)
+	GetSalesStatistics(context.Context, *SalesStatisticsRequest) (*SalesStatisticsResponse, error)
+	GetTopProducts(context.Context, *TopProductsRequest) (*TopProductsResponse, error)
+	GetOrderHeatmap(context.Context, *OrderHeatmapRequest) (*OrderHeatmapResponse, error)
+	GetActiveUsers(context.Context, *ActiveUsersRequest) (*ActiveUsersResponse, error)
+	GetRetentionCohorts(context.Context, *RetentionCohortsRequest) (*RetentionCohortsResponse, error)
+	GetCustomerProfile(context.Context, *CustomerProfileRequest) (*CustomerProfileResponse, error)
+	ListSegmentMembers(context.Context, *SegmentMembersRequest) (*SegmentMembersResponse, error)
+	GetConversionFunnel(context.Context, *ConversionFunnelRequest) (*ConversionFunnelResponse, error)
+	GetRelatedProducts(context.Context, *RelatedProductsRequest) (*RelatedProductsResponse, error)
+	WatchStatistics(*WatchStatisticsRequest, grpc.ServerStreamingServer[LiveStatistics]) error
+	// admin
+	RebuildRollups(*RebuildRollupsRequest, grpc.ServerStreamingServer[RebuildRollupsProgress]) error
 	mustEmbedUnimplementedStatisticsServiceServer()
 }
 
@@ -81,6 +244,39 @@ func (UnimplementedStatisticsServiceServer) GetUserOrdersStatistics(context.Cont
 func (UnimplementedStatisticsServiceServer) GetUserStatistics(context.Context, *UserStatisticsRequest) (*UserStatisticsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserStatistics not implemented")
 }
+func (UnimplementedStatisticsServiceServer) GetSalesStatistics(context.Context, *SalesStatisticsRequest) (*SalesStatisticsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSalesStatistics not implemented")
+}
+func (UnimplementedStatisticsServiceServer) GetTopProducts(context.Context, *TopProductsRequest) (*TopProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTopProducts not implemented")
+}
+func (UnimplementedStatisticsServiceServer) GetOrderHeatmap(context.Context, *OrderHeatmapRequest) (*OrderHeatmapResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderHeatmap not implemented")
+}
+func (UnimplementedStatisticsServiceServer) GetActiveUsers(context.Context, *ActiveUsersRequest) (*ActiveUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActiveUsers not implemented")
+}
+func (UnimplementedStatisticsServiceServer) GetRetentionCohorts(context.Context, *RetentionCohortsRequest) (*RetentionCohortsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRetentionCohorts not implemented")
+}
+func (UnimplementedStatisticsServiceServer) GetCustomerProfile(context.Context, *CustomerProfileRequest) (*CustomerProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCustomerProfile not implemented")
+}
+func (UnimplementedStatisticsServiceServer) ListSegmentMembers(context.Context, *SegmentMembersRequest) (*SegmentMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSegmentMembers not implemented")
+}
+func (UnimplementedStatisticsServiceServer) GetConversionFunnel(context.Context, *ConversionFunnelRequest) (*ConversionFunnelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConversionFunnel not implemented")
+}
+func (UnimplementedStatisticsServiceServer) GetRelatedProducts(context.Context, *RelatedProductsRequest) (*RelatedProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRelatedProducts not implemented")
+}
+func (UnimplementedStatisticsServiceServer) WatchStatistics(*WatchStatisticsRequest, grpc.ServerStreamingServer[LiveStatistics]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStatistics not implemented")
+}
+func (UnimplementedStatisticsServiceServer) RebuildRollups(*RebuildRollupsRequest, grpc.ServerStreamingServer[RebuildRollupsProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method RebuildRollups not implemented")
+}
 func (UnimplementedStatisticsServiceServer) mustEmbedUnimplementedStatisticsServiceServer() {}
 func (UnimplementedStatisticsServiceServer) testEmbeddedByValue()                           {}
 
@@ -138,6 +334,190 @@ func _StatisticsService_GetUserStatistics_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StatisticsService_GetSalesStatistics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SalesStatisticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticsServiceServer).GetSalesStatistics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatisticsService_GetSalesStatistics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticsServiceServer).GetSalesStatistics(ctx, req.(*SalesStatisticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticsService_GetTopProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticsServiceServer).GetTopProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatisticsService_GetTopProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticsServiceServer).GetTopProducts(ctx, req.(*TopProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticsService_GetOrderHeatmap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderHeatmapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticsServiceServer).GetOrderHeatmap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatisticsService_GetOrderHeatmap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticsServiceServer).GetOrderHeatmap(ctx, req.(*OrderHeatmapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticsService_GetActiveUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActiveUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticsServiceServer).GetActiveUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatisticsService_GetActiveUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticsServiceServer).GetActiveUsers(ctx, req.(*ActiveUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticsService_GetRetentionCohorts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetentionCohortsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticsServiceServer).GetRetentionCohorts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatisticsService_GetRetentionCohorts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticsServiceServer).GetRetentionCohorts(ctx, req.(*RetentionCohortsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticsService_GetCustomerProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CustomerProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticsServiceServer).GetCustomerProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatisticsService_GetCustomerProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticsServiceServer).GetCustomerProfile(ctx, req.(*CustomerProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticsService_ListSegmentMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SegmentMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticsServiceServer).ListSegmentMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatisticsService_ListSegmentMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticsServiceServer).ListSegmentMembers(ctx, req.(*SegmentMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticsService_GetConversionFunnel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConversionFunnelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticsServiceServer).GetConversionFunnel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatisticsService_GetConversionFunnel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticsServiceServer).GetConversionFunnel(ctx, req.(*ConversionFunnelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticsService_GetRelatedProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelatedProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticsServiceServer).GetRelatedProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatisticsService_GetRelatedProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticsServiceServer).GetRelatedProducts(ctx, req.(*RelatedProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticsService_WatchStatistics_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStatisticsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatisticsServiceServer).WatchStatistics(m, &grpc.GenericServerStream[WatchStatisticsRequest, LiveStatistics]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StatisticsService_WatchStatisticsServer = grpc.ServerStreamingServer[LiveStatistics]
+
+func _StatisticsService_RebuildRollups_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RebuildRollupsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatisticsServiceServer).RebuildRollups(m, &grpc.GenericServerStream[RebuildRollupsRequest, RebuildRollupsProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StatisticsService_RebuildRollupsServer = grpc.ServerStreamingServer[RebuildRollupsProgress]
+
 // StatisticsService_ServiceDesc is the grpc.ServiceDesc for StatisticsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,7 +533,54 @@ var StatisticsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserStatistics",
 			Handler:    _StatisticsService_GetUserStatistics_Handler,
 		},
+		{
+			MethodName: "GetSalesStatistics",
+			Handler:    _StatisticsService_GetSalesStatistics_Handler,
+		},
+		{
+			MethodName: "GetTopProducts",
+			Handler:    _StatisticsService_GetTopProducts_Handler,
+		},
+		{
+			MethodName: "GetOrderHeatmap",
+			Handler:    _StatisticsService_GetOrderHeatmap_Handler,
+		},
+		{
+			MethodName: "GetActiveUsers",
+			Handler:    _StatisticsService_GetActiveUsers_Handler,
+		},
+		{
+			MethodName: "GetRetentionCohorts",
+			Handler:    _StatisticsService_GetRetentionCohorts_Handler,
+		},
+		{
+			MethodName: "GetCustomerProfile",
+			Handler:    _StatisticsService_GetCustomerProfile_Handler,
+		},
+		{
+			MethodName: "ListSegmentMembers",
+			Handler:    _StatisticsService_ListSegmentMembers_Handler,
+		},
+		{
+			MethodName: "GetConversionFunnel",
+			Handler:    _StatisticsService_GetConversionFunnel_Handler,
+		},
+		{
+			MethodName: "GetRelatedProducts",
+			Handler:    _StatisticsService_GetRelatedProducts_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStatistics",
+			Handler:       _StatisticsService_WatchStatistics_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "RebuildRollups",
+			Handler:       _StatisticsService_RebuildRollups_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/statistics.proto",
 }
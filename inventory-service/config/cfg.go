@@ -11,11 +11,19 @@ type (
 	Config struct {
 		Version string `env:"VERSION" envDefault:"1.0.0"`
 
+		// InstanceID identifies this replica in cache invalidation messages.
+		// The hostname is used when it is empty.
+		InstanceID string `env:"INSTANCE_ID"`
+
+		// DefaultCurrency is assigned to prices stored before Money existed.
+		DefaultCurrency string `env:"DEFAULT_CURRENCY" envDefault:"USD"`
+
 		Mongo  mongo.Config
 		Server Server
 		Nats   Nats
 		Redis  Redis
 		Cache  Cache
+		Outbox Outbox
 	}
 
 	Server struct {
@@ -36,12 +44,21 @@ type (
 		NKey         string   `env:"NATS_NKEY" envDefault:"SUACSSL3UAHUDXKFSNVUZRF5UHPMWZ6BFDTJ7M6USDXIEDNPPQYYYCU3VY"`
 		IsTest       bool     `env:"NATS_IS_TEST,notEmpty" envDefault:"true"`
 		NatsSubjects NatsSubjects
+
+		// EventEncoding is "json" or "protobuf", see pkg/events.
+		EventEncoding string `env:"EVENT_ENCODING" envDefault:"json"`
 	}
 
 	NatsSubjects struct {
 		ProductCreated string `env:"NATS_PRODUCT_CREATED_SUBJECT,notEmpty"`
 		ProductUpdated string `env:"NATS_PRODUCT_UPDATED_SUBJECT,notEmpty"`
 		ProductDeleted string `env:"NATS_PRODUCT_DELETED_SUBJECT,notEmpty"`
+
+		CategoryCreated string `env:"NATS_CATEGORY_CREATED_SUBJECT,notEmpty" envDefault:"category.created"`
+		CategoryUpdated string `env:"NATS_CATEGORY_UPDATED_SUBJECT,notEmpty" envDefault:"category.updated"`
+		CategoryDeleted string `env:"NATS_CATEGORY_DELETED_SUBJECT,notEmpty" envDefault:"category.deleted"`
+
+		CacheInvalidation string `env:"NATS_CACHE_INVALIDATION_SUBJECT,notEmpty" envDefault:"inventory.cache.invalidate"`
 	}
 
 	Redis struct {
@@ -53,8 +70,23 @@ type (
 		ReadTimeout  time.Duration `env:"REDIS_READ_TIMEOUT" envDefault:"30s"`
 	}
 
+	// Outbox configures the relay that publishes stored events to NATS.
+	Outbox struct {
+		PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+		BatchSize    int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+		Lease        time.Duration `env:"OUTBOX_LEASE" envDefault:"30s"`
+		MinBackoff   time.Duration `env:"OUTBOX_MIN_BACKOFF" envDefault:"1s"`
+		MaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF" envDefault:"5m"`
+	}
+
 	Cache struct {
 		ProductTTL             time.Duration `env:"REDIS_CACHE_CLIENT_TTL" envDefault:"24h"`
+		ProductTTLJitter       float64       `env:"REDIS_CACHE_TTL_JITTER" envDefault:"0.1"`
+		CategoryTTL            time.Duration `env:"REDIS_CATEGORY_CACHE_TTL" envDefault:"24h"`
+		MemoryMaxEntries       int           `env:"MEMORY_CACHE_MAX_ENTRIES" envDefault:"10000"`
+		MemoryTTL              time.Duration `env:"MEMORY_CACHE_TTL" envDefault:"10m"`
+		MemoryStaleTTL         time.Duration `env:"MEMORY_CACHE_STALE_TTL" envDefault:"1m"`
+		MemoryStatsInterval    time.Duration `env:"MEMORY_CACHE_STATS_INTERVAL" envDefault:"5m"`
 		CMSVariableRefreshTime time.Duration `env:"CLIENT_REFRESH_TIME" envDefault:"1m"`
 	}
 )
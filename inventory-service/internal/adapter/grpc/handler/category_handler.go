@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/utils"
@@ -13,18 +14,16 @@ import (
 
 func (s *InventoryHandler) CreateCategory(ctx context.Context, req *inventorypb.CreateCategoryRequest) (*inventorypb.CategoryResponse, error) {
 	c := &domain.Category{
-		Name: req.Name,
+		Name:     req.Name,
+		ParentID: req.ParentId,
 	}
 
 	if err := s.categoryUsecase.Create(ctx, c); err != nil {
 		utils.Log.Error("CreateCategory failed", "err", err)
-		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		return nil, categoryError(err)
 	}
 
-	return &inventorypb.CategoryResponse{
-		Id:   c.ID,
-		Name: c.Name,
-	}, nil
+	return toCategoryResponse(c), nil
 }
 
 func (s *InventoryHandler) GetCategoryByID(ctx context.Context, req *inventorypb.GetCategoryRequest) (*inventorypb.CategoryResponse, error) {
@@ -37,10 +36,7 @@ func (s *InventoryHandler) GetCategoryByID(ctx context.Context, req *inventorypb
 		return nil, status.Error(codes.NotFound, "category not found")
 	}
 
-	return &inventorypb.CategoryResponse{
-		Id:   cat.ID,
-		Name: cat.Name,
-	}, nil
+	return toCategoryResponse(cat), nil
 }
 
 func (s *InventoryHandler) UpdateCategory(ctx context.Context, req *inventorypb.UpdateCategoryRequest) (*inventorypb.CategoryResponse, error) {
@@ -54,16 +50,13 @@ func (s *InventoryHandler) UpdateCategory(ctx context.Context, req *inventorypb.
 		return nil, status.Errorf(codes.InvalidArgument, err.Error())
 	}
 
-	return &inventorypb.CategoryResponse{
-		Id:   c.ID,
-		Name: c.Name,
-	}, nil
+	return toCategoryResponse(c), nil
 }
 
 func (s *InventoryHandler) DeleteCategory(ctx context.Context, req *inventorypb.DeleteCategoryRequest) (*emptypb.Empty, error) {
 	if err := s.categoryUsecase.Delete(ctx, req.Id); err != nil {
 		utils.Log.Error("DeleteCategory failed", "err", err)
-		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		return nil, categoryError(err)
 	}
 	return &emptypb.Empty{}, nil
 }
@@ -75,13 +68,85 @@ func (s *InventoryHandler) ListCategories(ctx context.Context, _ *inventorypb.Li
 		return nil, status.Errorf(codes.Internal, err.Error())
 	}
 
+	return toListCategoriesResponse(categories), nil
+}
+
+func (s *InventoryHandler) ListChildCategories(ctx context.Context, req *inventorypb.ListChildCategoriesRequest) (*inventorypb.ListCategoriesResponse, error) {
+	categories, err := s.categoryUsecase.ListChildren(ctx, req.ParentId)
+	if err != nil {
+		utils.Log.Error("ListChildCategories failed", "parent_id", req.ParentId, "err", err)
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+
+	return toListCategoriesResponse(categories), nil
+}
+
+func (s *InventoryHandler) GetCategorySubtree(ctx context.Context, req *inventorypb.GetCategorySubtreeRequest) (*inventorypb.CategoryTreeNode, error) {
+	root, err := s.categoryUsecase.GetSubtree(ctx, req.Id)
+	if err != nil {
+		utils.Log.Error("GetCategorySubtree failed", "id", req.Id, "err", err)
+		return nil, categoryError(err)
+	}
+
+	return toCategoryTreeNode(root), nil
+}
+
+func (s *InventoryHandler) GetCategoryBreadcrumbs(ctx context.Context, req *inventorypb.GetCategoryBreadcrumbsRequest) (*inventorypb.ListCategoriesResponse, error) {
+	crumbs, err := s.categoryUsecase.GetBreadcrumbs(ctx, req.Id)
+	if err != nil {
+		utils.Log.Error("GetCategoryBreadcrumbs failed", "id", req.Id, "err", err)
+		return nil, categoryError(err)
+	}
+
+	return toListCategoriesResponse(crumbs), nil
+}
+
+func (s *InventoryHandler) MoveCategory(ctx context.Context, req *inventorypb.MoveCategoryRequest) (*inventorypb.CategoryResponse, error) {
+	c, err := s.categoryUsecase.Move(ctx, req.Id, req.NewParentId)
+	if err != nil {
+		utils.Log.Error("MoveCategory failed", "id", req.Id, "new_parent_id", req.NewParentId, "err", err)
+		return nil, categoryError(err)
+	}
+
+	return toCategoryResponse(c), nil
+}
+
+func categoryError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrCategoryCycle), errors.Is(err, domain.ErrCategoryHasChildren):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrCategoryConflict):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
+
+func toCategoryResponse(c *domain.Category) *inventorypb.CategoryResponse {
+	return &inventorypb.CategoryResponse{
+		Id:        c.ID,
+		Name:      c.Name,
+		ParentId:  c.ParentID,
+		Ancestors: c.Ancestors,
+	}
+}
+
+func toListCategoriesResponse(categories []*domain.Category) *inventorypb.ListCategoriesResponse {
 	var res []*inventorypb.CategoryResponse
 	for _, c := range categories {
-		res = append(res, &inventorypb.CategoryResponse{
-			Id:   c.ID,
-			Name: c.Name,
-		})
+		res = append(res, toCategoryResponse(c))
+	}
+
+	return &inventorypb.ListCategoriesResponse{Categories: res}
+}
+
+func toCategoryTreeNode(n *domain.CategoryNode) *inventorypb.CategoryTreeNode {
+	node := &inventorypb.CategoryTreeNode{Category: toCategoryResponse(n.Category)}
+	for _, child := range n.Children {
+		node.Children = append(node.Children, toCategoryTreeNode(child))
 	}
 
-	return &inventorypb.ListCategoriesResponse{Categories: res}, nil
+	return node
 }
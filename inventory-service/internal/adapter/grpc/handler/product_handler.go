@@ -2,6 +2,8 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/utils"
@@ -26,21 +28,20 @@ func (s *InventoryHandler) GetProductByID(ctx context.Context, req *inventorypb.
 	}
 
 	utils.Log.Info("product found", "id", product.ID)
-	return &inventorypb.ProductResponse{
-		Id:       product.ID,
-		Name:     product.Name,
-		Price:    product.Price,
-		Category: product.Category,
-		Stock:    int32(product.Stock),
-	}, nil
+	return toProductResponse(product), nil
 }
 
 func (s *InventoryHandler) CreateProduct(ctx context.Context, req *inventorypb.CreateProductRequest) (*inventorypb.ProductResponse, error) {
 	// utils.Log.Info("gRPC CreateProduct", "name", req.Name)
 
+	price, err := fromMoneyProto(req.Price)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid price: %v", err)
+	}
+
 	product := &domain.Product{
 		Name:     req.Name,
-		Price:    req.Price,
+		Price:    price,
 		Category: req.Category,
 		Stock:    int(req.Stock),
 	}
@@ -51,50 +52,25 @@ func (s *InventoryHandler) CreateProduct(ctx context.Context, req *inventorypb.C
 	}
 
 	utils.Log.Info("product created", "id", product.ID)
-	return &inventorypb.ProductResponse{
-		Id:       product.ID,
-		Name:     product.Name,
-		Price:    product.Price,
-		Category: product.Category,
-		Stock:    int32(product.Stock),
-	}, nil
+	return toProductResponse(product), nil
 }
 
 func (s *InventoryHandler) UpdateProduct(ctx context.Context, req *inventorypb.UpdateProductRequest) (*inventorypb.ProductResponse, error) {
 	// utils.Log.Info("gRPC UpdateProduct", "id", req.Id)
 
-	current, err := s.productUsecase.GetByID(ctx, req.Id)
+	upd, err := toProductUpdate(req)
 	if err != nil {
-		utils.Log.Error("product not found for update", "id", req.Id, "err", err)
-		return nil, status.Errorf(codes.NotFound, "product not found: %v", err)
-	}
-
-	if req.Name != "" {
-		current.Name = req.Name
-	}
-	if req.Price != 0 {
-		current.Price = req.Price
-	}
-	if req.Category != "" {
-		current.Category = req.Category
-	}
-	if req.Stock != 0 {
-		current.Stock = int(req.Stock)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	if err := s.productUsecase.Update(ctx, current); err != nil {
-		utils.Log.Error("failed to update product", "id", current.ID, "err", err)
-		return nil, status.Errorf(codes.Internal, "failed to update product: %v", err)
+	product, err := s.productUsecase.Update(ctx, upd)
+	if err != nil {
+		utils.Log.Error("failed to update product", "id", req.Id, "err", err)
+		return nil, productError(err)
 	}
 
-	utils.Log.Info("product updated", "id", current.ID)
-	return &inventorypb.ProductResponse{
-		Id:       current.ID,
-		Name:     current.Name,
-		Price:    current.Price,
-		Category: current.Category,
-		Stock:    int32(current.Stock),
-	}, nil
+	utils.Log.Info("product updated", "id", product.ID, "version", product.Version)
+	return toProductResponse(product), nil
 }
 
 func (s *InventoryHandler) DeleteProduct(ctx context.Context, req *inventorypb.DeleteProductRequest) (*emptypb.Empty, error) {
@@ -109,10 +85,25 @@ func (s *InventoryHandler) DeleteProduct(ctx context.Context, req *inventorypb.D
 	return &emptypb.Empty{}, nil
 }
 
-func (s *InventoryHandler) ListProducts(ctx context.Context, _ *inventorypb.ListProductsRequest) (*inventorypb.ListProductsResponse, error) {
+func (s *InventoryHandler) ListProducts(ctx context.Context, req *inventorypb.ListProductsRequest) (*inventorypb.ListProductsResponse, error) {
 	// utils.Log.Info("gRPC ListProducts")
 
-	products, err := s.productUsecase.List(ctx)
+	var (
+		products []*domain.Product
+		err      error
+	)
+	switch {
+	case req.Category == "":
+		products, err = s.productUsecase.List(ctx)
+	case req.IncludeDescendants:
+		var ids []string
+		ids, err = s.categoryUsecase.SubtreeIDs(ctx, req.Category)
+		if err == nil {
+			products, err = s.productUsecase.ListByCategories(ctx, ids)
+		}
+	default:
+		products, err = s.productUsecase.ListByCategories(ctx, []string{req.Category})
+	}
 	if err != nil {
 		utils.Log.Error("failed to list products", "err", err)
 		return nil, status.Errorf(codes.Internal, "failed to list products: %v", err)
@@ -122,14 +113,112 @@ func (s *InventoryHandler) ListProducts(ctx context.Context, _ *inventorypb.List
 
 	var result []*inventorypb.ProductResponse
 	for _, p := range products {
-		result = append(result, &inventorypb.ProductResponse{
-			Id:       p.ID,
-			Name:     p.Name,
-			Price:    p.Price,
-			Category: p.Category,
-			Stock:    int32(p.Stock),
-		})
+		result = append(result, toProductResponse(p))
 	}
 
 	return &inventorypb.ListProductsResponse{Products: result}, nil
 }
+
+func (s *InventoryHandler) BatchGetProducts(ctx context.Context, req *inventorypb.BatchGetProductsRequest) (*inventorypb.BatchGetProductsResponse, error) {
+	products, notFound, err := s.productUsecase.BatchGet(ctx, req.Ids)
+	if err != nil {
+		utils.Log.Error("failed to batch get products", "count", len(req.Ids), "err", err)
+		if errors.Is(err, domain.ErrInvalidBatch) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get products: %v", err)
+	}
+
+	utils.Log.Info("products batch fetched", "found", len(products), "not_found", len(notFound))
+
+	result := make([]*inventorypb.ProductResponse, 0, len(products))
+	for _, p := range products {
+		result = append(result, toProductResponse(p))
+	}
+
+	return &inventorypb.BatchGetProductsResponse{Products: result, NotFoundIds: notFound}, nil
+}
+
+// toProductUpdate builds the partial update described by req. Without an
+// update mask every non-zero field is applied, which is how the RPC behaved
+// before masks were supported.
+func toProductUpdate(req *inventorypb.UpdateProductRequest) (*domain.ProductUpdate, error) {
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		if req.Name != "" {
+			paths = append(paths, "name")
+		}
+		if req.Price != nil {
+			paths = append(paths, "price")
+		}
+		if req.Category != "" {
+			paths = append(paths, "category")
+		}
+		if req.Stock != 0 {
+			paths = append(paths, "stock")
+		}
+	}
+
+	upd := &domain.ProductUpdate{ID: req.Id, Version: req.Version}
+	for _, path := range paths {
+		switch path {
+		case "name":
+			upd.Name = &req.Name
+		case "price":
+			price, err := fromMoneyProto(req.Price)
+			if err != nil {
+				return nil, fmt.Errorf("invalid price: %w", err)
+			}
+			upd.Price = &price
+		case "category":
+			upd.Category = &req.Category
+		case "stock":
+			stock := int(req.Stock)
+			upd.Stock = &stock
+		default:
+			return nil, fmt.Errorf("unknown update_mask path %q", path)
+		}
+	}
+
+	return upd, nil
+}
+
+func productError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, domain.ErrInvalidProduct):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "failed to update product: %v", err)
+	}
+}
+
+func toProductResponse(p *domain.Product) *inventorypb.ProductResponse {
+	return &inventorypb.ProductResponse{
+		Id:       p.ID,
+		Name:     p.Name,
+		Price:    toMoneyProto(p.Price),
+		Category: p.Category,
+		Stock:    int32(p.Stock),
+		Version:  p.Version,
+	}
+}
+
+func toMoneyProto(m domain.Money) *inventorypb.Money {
+	units, nanos := m.Units()
+	return &inventorypb.Money{
+		CurrencyCode: m.Currency,
+		Units:        units,
+		Nanos:        nanos,
+	}
+}
+
+func fromMoneyProto(m *inventorypb.Money) (domain.Money, error) {
+	if m == nil {
+		return domain.Money{}, errors.New("price is required")
+	}
+	return domain.NewMoneyFromUnits(m.Units, m.Nanos, m.CurrencyCode)
+}
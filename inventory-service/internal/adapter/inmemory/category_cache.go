@@ -0,0 +1,107 @@
+package inmemory
+
+import (
+	"log"
+	"sync"
+
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+)
+
+var _ domain.CategoryMemoryCache = (*CategoryCache)(nil)
+
+// CategoryCache keeps categories by ID. The cached list is only served after
+// SetList stored the full set of categories and no write has happened since.
+type CategoryCache struct {
+	categories   map[string]*domain.Category
+	listComplete bool
+	m            sync.RWMutex
+}
+
+func NewCategoryCache() *CategoryCache {
+	return &CategoryCache{
+		categories: make(map[string]*domain.Category),
+		m:          sync.RWMutex{},
+	}
+}
+
+func (c *CategoryCache) Set(category *domain.Category) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.categories[category.ID] = category
+	c.listComplete = false
+	log.Printf("[InMemory] Set category id=%s", category.ID)
+}
+
+// Warm stores a category read from Redis or the database. Unlike Set it
+// keeps the cached list, which a read does not change.
+func (c *CategoryCache) Warm(category *domain.Category) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.categories[category.ID] = category
+	log.Printf("[InMemory] Warm category id=%s", category.ID)
+}
+
+func (c *CategoryCache) SetMany(categories []*domain.Category) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	for _, category := range categories {
+		c.categories[category.ID] = category
+	}
+	c.listComplete = false
+	log.Printf("[InMemory] SetMany categories done: total=%d", len(categories))
+}
+
+func (c *CategoryCache) Get(categoryID string) (*domain.Category, bool) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	category, ok := c.categories[categoryID]
+	if ok {
+		log.Printf("[InMemory] HIT for category id=%s", categoryID)
+	} else {
+		log.Printf("[InMemory] MISS for category id=%s", categoryID)
+	}
+	return category, ok
+}
+
+func (c *CategoryCache) Delete(categoryID string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	delete(c.categories, categoryID)
+	c.listComplete = false
+	log.Printf("[InMemory] Deleted category id=%s", categoryID)
+}
+
+func (c *CategoryCache) SetList(categories []*domain.Category) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.categories = make(map[string]*domain.Category, len(categories))
+	for _, category := range categories {
+		c.categories[category.ID] = category
+	}
+	c.listComplete = true
+	log.Printf("[InMemory] SetList categories: total=%d", len(categories))
+}
+
+func (c *CategoryCache) GetList() ([]*domain.Category, bool) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	if !c.listComplete {
+		log.Printf("[InMemory] GetList categories: not loaded")
+		return nil, false
+	}
+
+	list := make([]*domain.Category, 0, len(c.categories))
+	for _, category := range c.categories {
+		list = append(list, category)
+	}
+
+	log.Printf("[InMemory] GetList categories: returned %d", len(list))
+	return list, true
+}
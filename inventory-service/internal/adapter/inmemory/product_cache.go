@@ -1,23 +1,70 @@
 package inmemory
 
 import (
+	"container/list"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
 )
 
 var _ domain.ProductMemoryCache = (*ProductCache)(nil)
 
+// versionMarkTTL is how long an invalidation is remembered. Messages arriving
+// later than that out of order are not expected.
+const versionMarkTTL = time.Hour
+
+// versionMark is the newest version of a product another replica announced.
+// A deleted mark rejects every version.
+type versionMark struct {
+	version int64
+	deleted bool
+	at      time.Time
+}
+
+type productEntry struct {
+	product   *domain.Product
+	expiresAt time.Time
+}
+
+// ProductCache is an LRU of products by ID holding at most maxEntries
+// products, each fresh for ttl. For staleTTL after that an entry is still
+// returned but flagged as stale, so the caller can serve it while one
+// goroutine refreshes it.
+//
+// The full catalog is kept apart from the LRU as the list entry. It only
+// exists after SetList stored a complete load, so GetList never returns the
+// handful of products that happen to be cached as if it were the catalog.
+// Writes keep the list entry in sync where they can and drop it otherwise.
+// The list entry shares the products of the LRU but is not counted against
+// maxEntries, and is returned in the order it was loaded in.
 type ProductCache struct {
-	products map[string]*domain.Product
-	m        sync.RWMutex
+	maxEntries int
+	ttl        time.Duration
+	staleTTL   time.Duration
+
+	lru     *list.List // front is most recently used
+	entries map[string]*list.Element
+
+	list          map[string]*domain.Product
+	listOrder     []string // IDs of the list in the order it was loaded in
+	listExpiresAt time.Time
+
+	marks map[string]versionMark
+	stats domain.CacheStats
+	m     sync.Mutex
 }
 
-func NewProductCache() *ProductCache {
+func NewProductCache(maxEntries int, ttl, staleTTL time.Duration) *ProductCache {
 	return &ProductCache{
-		products: make(map[string]*domain.Product),
-		m:        sync.RWMutex{},
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		staleTTL:   staleTTL,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+		marks:      make(map[string]versionMark),
+		m:          sync.Mutex{},
 	}
 }
 
@@ -25,7 +72,13 @@ func (c *ProductCache) Set(product *domain.Product) {
 	c.m.Lock()
 	defer c.m.Unlock()
 
-	c.products[product.ID] = product
+	if c.isStale(product) {
+		log.Printf("[InMemory] Skip stale product id=%s version=%d", product.ID, product.Version)
+		return
+	}
+
+	c.add(product)
+	c.syncList(product)
 	log.Printf("[InMemory] Set product id=%s", product.ID)
 }
 
@@ -34,47 +87,246 @@ func (c *ProductCache) SetMany(products []*domain.Product) {
 	defer c.m.Unlock()
 
 	for _, product := range products {
-		c.products[product.ID] = product
-		log.Printf("[InMemory] SetMany product id=%s", product.ID)
+		if c.isStale(product) {
+			continue
+		}
+		c.add(product)
+		c.syncList(product)
 	}
 	log.Printf("[InMemory] SetMany done: total=%d", len(products))
 }
 
-func (c *ProductCache) Get(productID string) (*domain.Product, bool) {
-	c.m.RLock()
-	defer c.m.RUnlock()
+// Get returns the cached product, which may be past its TTL if stale is set.
+func (c *ProductCache) Get(productID string) (product *domain.Product, stale bool, ok bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
 
-	product, ok := c.products[productID]
-	if ok {
+	product, stale, ok = c.get(productID)
+	switch {
+	case stale:
+		log.Printf("[InMemory] STALE HIT for id=%s", productID)
+	case ok:
 		log.Printf("[InMemory] HIT for id=%s", productID)
-	} else {
+	default:
 		log.Printf("[InMemory] MISS for id=%s", productID)
 	}
-	return product, ok
+	return product, stale, ok
+}
+
+// GetMany returns the fresh cached products among productIDs and the IDs
+// that are not cached or stale.
+func (c *ProductCache) GetMany(productIDs []string) ([]*domain.Product, []string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	found := make([]*domain.Product, 0, len(productIDs))
+	var missing []string
+	for _, id := range productIDs {
+		if product, stale, ok := c.get(id); ok && !stale {
+			found = append(found, product)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	log.Printf("[InMemory] GetMany: hits=%d misses=%d", len(found), len(missing))
+	return found, missing
 }
 
 func (c *ProductCache) Delete(productID string) {
 	c.m.Lock()
 	defer c.m.Unlock()
 
-	delete(c.products, productID)
+	c.remove(productID)
+	if c.list != nil {
+		delete(c.list, productID)
+	}
 	log.Printf("[InMemory] Deleted product id=%s", productID)
 }
 
-func (c *ProductCache) GetList() ([]*domain.Product, bool) {
-	c.m.RLock()
-	defer c.m.RUnlock()
+// Invalidate evicts productID unless the cached entry is already at version
+// or newer, and remembers version so that older copies read later from Redis
+// or an in-flight query are not cached again. A deleted product is never
+// cached again while its mark is kept.
+func (c *ProductCache) Invalidate(productID string, version int64, deleted bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.pruneMarks()
 
-	if len(c.products) == 0 {
-		log.Printf("[InMemory] GetList: empty cache")
-		return nil, false
+	if mark, ok := c.marks[productID]; ok && (mark.deleted || (!deleted && mark.version > version)) {
+		log.Printf("[InMemory] Ignore outdated invalidation id=%s version=%d", productID, version)
+		return
 	}
+	c.marks[productID] = versionMark{version: version, deleted: deleted, at: time.Now()}
 
-	list := make([]*domain.Product, 0, len(c.products))
-	for _, product := range c.products {
-		list = append(list, product)
+	// the list no longer matches, a new or updated product is missing from it
+	c.list = nil
+
+	if el, ok := c.entries[productID]; ok && !deleted && el.Value.(*productEntry).product.Version >= version {
+		return
 	}
+	c.remove(productID)
+	log.Printf("[InMemory] Invalidated product id=%s version=%d deleted=%t", productID, version, deleted)
+}
+
+// SetList stores the complete catalog as the list entry. The LRU is warmed
+// with the products as well, as far as it has room.
+func (c *ProductCache) SetList(products []*domain.Product) {
+	c.m.Lock()
+	defer c.m.Unlock()
 
-	log.Printf("[InMemory] GetList: returned %d products", len(list))
-	return list, true
+	complete := make(map[string]*domain.Product, len(products))
+	order := make([]string, 0, len(products))
+	for _, product := range products {
+		if c.isStale(product) {
+			// an invalidation overtook this load, it is not complete
+			complete = nil
+			continue
+		}
+		if complete != nil {
+			complete[product.ID] = product
+			order = append(order, product.ID)
+		}
+		c.add(product)
+	}
+
+	c.list = complete
+	c.listOrder = order
+	c.listExpiresAt = time.Now().Add(c.ttl)
+	log.Printf("[InMemory] SetList products: total=%d complete=%t", len(products), complete != nil)
+}
+
+// GetList returns the complete catalog, which may be past its TTL if stale
+// is set.
+func (c *ProductCache) GetList() (products []*domain.Product, stale bool, ok bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	now := time.Now()
+	if c.list != nil && now.After(c.listExpiresAt.Add(c.staleTTL)) {
+		c.list = nil
+		c.stats.Expirations++
+	}
+	if c.list == nil {
+		c.stats.Misses++
+		log.Printf("[InMemory] GetList: not loaded")
+		return nil, false, false
+	}
+
+	products = make([]*domain.Product, 0, len(c.list))
+	for _, id := range c.listOrder {
+		// deleted products are gone from the list but not from the order
+		if product, ok := c.list[id]; ok {
+			products = append(products, product)
+		}
+	}
+
+	stale = now.After(c.listExpiresAt)
+	if stale {
+		c.stats.StaleHits++
+	} else {
+		c.stats.Hits++
+	}
+	log.Printf("[InMemory] GetList: returned %d products stale=%t", len(products), stale)
+	return products, stale, true
+}
+
+func (c *ProductCache) Stats() domain.CacheStats {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// get returns a live or stale entry and marks it as recently used. The
+// caller must hold the lock.
+func (c *ProductCache) get(productID string) (*domain.Product, bool, bool) {
+	el, ok := c.entries[productID]
+	if !ok {
+		c.stats.Misses++
+		return nil, false, false
+	}
+
+	entry := el.Value.(*productEntry)
+	now := time.Now()
+	if now.After(entry.expiresAt.Add(c.staleTTL)) {
+		c.lru.Remove(el)
+		delete(c.entries, productID)
+		c.stats.Expirations++
+		c.stats.Misses++
+		return nil, false, false
+	}
+
+	c.lru.MoveToFront(el)
+	if now.After(entry.expiresAt) {
+		c.stats.StaleHits++
+		return entry.product, true, true
+	}
+	c.stats.Hits++
+	return entry.product, false, true
+}
+
+// add inserts or refreshes product and evicts the least recently used
+// entries beyond maxEntries. The caller must hold the lock.
+func (c *ProductCache) add(product *domain.Product) {
+	entry := &productEntry{product: product, expiresAt: time.Now().Add(c.ttl)}
+
+	if el, ok := c.entries[product.ID]; ok {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[product.ID] = c.lru.PushFront(entry)
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*productEntry).product.ID)
+		c.stats.Evictions++
+	}
+}
+
+// remove drops productID from the LRU. The caller must hold the lock.
+func (c *ProductCache) remove(productID string) {
+	if el, ok := c.entries[productID]; ok {
+		c.lru.Remove(el)
+		delete(c.entries, productID)
+	}
+}
+
+// syncList replaces product in the list entry. A product the list does not
+// know yet was created after the list was loaded, so the list is dropped.
+// The caller must hold the lock.
+func (c *ProductCache) syncList(product *domain.Product) {
+	if c.list == nil {
+		return
+	}
+	if _, ok := c.list[product.ID]; !ok {
+		c.list = nil
+		return
+	}
+	c.list[product.ID] = product
+}
+
+// isStale reports whether product is older than an invalidation received for
+// it. The caller must hold the lock.
+func (c *ProductCache) isStale(product *domain.Product) bool {
+	mark, ok := c.marks[product.ID]
+	if !ok {
+		return false
+	}
+	return mark.deleted || product.Version < mark.version
+}
+
+// pruneMarks drops marks older than versionMarkTTL. The caller must hold the
+// lock.
+func (c *ProductCache) pruneMarks() {
+	for id, mark := range c.marks {
+		if time.Since(mark.at) > versionMarkTTL {
+			delete(c.marks, id)
+		}
+	}
 }
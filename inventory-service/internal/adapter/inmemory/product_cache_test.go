@@ -0,0 +1,218 @@
+package inmemory
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+)
+
+func product(id string, version int64) *domain.Product {
+	return &domain.Product{ID: id, Version: version}
+}
+
+func ids(products []*domain.Product) []string {
+	out := make([]string, 0, len(products))
+	for _, p := range products {
+		out = append(out, p.ID)
+	}
+	return out
+}
+
+func TestProductCacheEviction(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		ops        func(c *ProductCache)
+		cached     []string
+		evicted    []string
+	}{
+		{
+			name:       "oldest is evicted",
+			maxEntries: 2,
+			ops: func(c *ProductCache) {
+				c.Set(product("a", 1))
+				c.Set(product("b", 1))
+				c.Set(product("c", 1))
+			},
+			cached:  []string{"b", "c"},
+			evicted: []string{"a"},
+		},
+		{
+			name:       "a read keeps an entry",
+			maxEntries: 2,
+			ops: func(c *ProductCache) {
+				c.Set(product("a", 1))
+				c.Set(product("b", 1))
+				c.Get("a")
+				c.Set(product("c", 1))
+			},
+			cached:  []string{"a", "c"},
+			evicted: []string{"b"},
+		},
+		{
+			name:       "a write keeps an entry",
+			maxEntries: 2,
+			ops: func(c *ProductCache) {
+				c.Set(product("a", 1))
+				c.Set(product("b", 1))
+				c.Set(product("a", 2))
+				c.Set(product("c", 1))
+			},
+			cached:  []string{"a", "c"},
+			evicted: []string{"b"},
+		},
+		{
+			name:       "no limit",
+			maxEntries: 0,
+			ops: func(c *ProductCache) {
+				c.Set(product("a", 1))
+				c.Set(product("b", 1))
+				c.Set(product("c", 1))
+			},
+			cached: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewProductCache(tt.maxEntries, time.Hour, 0)
+			tt.ops(c)
+
+			if got := c.Stats().Evictions; got != uint64(len(tt.evicted)) {
+				t.Errorf("Evictions = %d, want %d", got, len(tt.evicted))
+			}
+			for _, id := range tt.cached {
+				if _, _, ok := c.Get(id); !ok {
+					t.Errorf("Get(%q) missed, want a hit", id)
+				}
+			}
+			for _, id := range tt.evicted {
+				if _, _, ok := c.Get(id); ok {
+					t.Errorf("Get(%q) hit, want it evicted", id)
+				}
+			}
+		})
+	}
+}
+
+// TestProductCacheTTL uses TTLs that already ran out when the entry is set,
+// the cache has no clock of its own.
+func TestProductCacheTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		staleTTL  time.Duration
+		wantOK    bool
+		wantStale bool
+	}{
+		{name: "fresh", ttl: time.Hour, staleTTL: time.Hour, wantOK: true},
+		{name: "stale", ttl: -time.Minute, staleTTL: time.Hour, wantOK: true, wantStale: true},
+		{name: "expired", ttl: -time.Hour, staleTTL: time.Minute},
+		{name: "expired without stale TTL", ttl: -time.Minute, staleTTL: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewProductCache(10, tt.ttl, tt.staleTTL)
+			c.Set(product("a", 1))
+			c.SetList([]*domain.Product{product("a", 1)})
+
+			if _, stale, ok := c.Get("a"); ok != tt.wantOK || stale != tt.wantStale {
+				t.Errorf("Get() = stale %t ok %t, want stale %t ok %t", stale, ok, tt.wantStale, tt.wantOK)
+			}
+			if _, stale, ok := c.GetList(); ok != tt.wantOK || stale != tt.wantStale {
+				t.Errorf("GetList() = stale %t ok %t, want stale %t ok %t", stale, ok, tt.wantStale, tt.wantOK)
+			}
+			if _, _, ok := c.Get("a"); !tt.wantOK && ok {
+				t.Error("Get() after expiry hit, want the entry dropped")
+			}
+			if got := c.Stats().Expirations; !tt.wantOK && got != 2 {
+				t.Errorf("Expirations = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestProductCacheList(t *testing.T) {
+	catalog := []*domain.Product{product("c", 1), product("a", 1), product("b", 1)}
+
+	tests := []struct {
+		name   string
+		ops    func(c *ProductCache)
+		want   []string // nil if the list must not be served
+		wantOK bool
+	}{
+		{
+			name:   "not loaded",
+			ops:    func(c *ProductCache) { c.Set(product("a", 1)) },
+			wantOK: false,
+		},
+		{
+			name:   "loaded in order",
+			ops:    func(c *ProductCache) { c.SetList(catalog) },
+			want:   []string{"c", "a", "b"},
+			wantOK: true,
+		},
+		{
+			name: "update of a listed product",
+			ops: func(c *ProductCache) {
+				c.SetList(catalog)
+				c.Set(product("a", 2))
+			},
+			want:   []string{"c", "a", "b"},
+			wantOK: true,
+		},
+		{
+			name: "delete of a listed product",
+			ops: func(c *ProductCache) {
+				c.SetList(catalog)
+				c.Delete("a")
+			},
+			want:   []string{"c", "b"},
+			wantOK: true,
+		},
+		{
+			name: "new product drops the list",
+			ops: func(c *ProductCache) {
+				c.SetList(catalog)
+				c.Set(product("d", 1))
+			},
+			wantOK: false,
+		},
+		{
+			name: "invalidation drops the list",
+			ops: func(c *ProductCache) {
+				c.SetList(catalog)
+				c.Invalidate("a", 2, false)
+			},
+			wantOK: false,
+		},
+		{
+			name: "load overtaken by an invalidation is not complete",
+			ops: func(c *ProductCache) {
+				c.Invalidate("a", 2, false)
+				c.SetList(catalog)
+			},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewProductCache(10, time.Hour, 0)
+			tt.ops(c)
+
+			for i := 0; i < 3; i++ {
+				products, _, ok := c.GetList()
+				if ok != tt.wantOK {
+					t.Fatalf("GetList() ok = %t, want %t", ok, tt.wantOK)
+				}
+				if ok && !reflect.DeepEqual(ids(products), tt.want) {
+					t.Fatalf("GetList() = %v, want %v", ids(products), tt.want)
+				}
+			}
+		})
+	}
+}
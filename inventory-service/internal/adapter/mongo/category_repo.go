@@ -3,6 +3,7 @@ package mongo
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/utils"
@@ -74,11 +75,119 @@ func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
 		return errors.New("invalid category ID format")
 	}
 	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": oid})
-	return err
+	return conflict(err)
 }
 
 func (r *CategoryRepository) List(ctx context.Context) ([]*domain.Category, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	return r.find(ctx, bson.M{})
+}
+
+func (r *CategoryRepository) ListByIDs(ctx context.Context, ids []string) ([]*domain.Category, error) {
+	oids := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, errors.New("invalid category ID format")
+		}
+		oids = append(oids, oid)
+	}
+
+	return r.find(ctx, bson.M{"_id": bson.M{"$in": oids}})
+}
+
+// ListChildren returns the direct children of parentID. An empty parentID
+// lists the root categories, including ones stored before the tree existed.
+func (r *CategoryRepository) ListChildren(ctx context.Context, parentID string) ([]*domain.Category, error) {
+	if parentID == "" {
+		return r.find(ctx, bson.M{"parent_id": bson.M{"$in": bson.A{"", nil}}})
+	}
+	return r.find(ctx, bson.M{"parent_id": parentID})
+}
+
+func (r *CategoryRepository) ListDescendants(ctx context.Context, id string) ([]*domain.Category, error) {
+	return r.find(ctx, bson.M{"ancestors": id})
+}
+
+// Move stores the new parent and ancestors of c and rewrites the ancestors of
+// every descendant so that they keep their position below c. It fails with
+// domain.ErrCategoryConflict unless c is still below oldParentID.
+func (r *CategoryRepository) Move(ctx context.Context, c *domain.Category, oldParentID string) error {
+	oid, err := primitive.ObjectIDFromHex(c.ID)
+	if err != nil {
+		return errors.New("invalid category ID format")
+	}
+
+	filter := bson.M{"_id": oid, "parent_id": oldParentID}
+	if oldParentID == "" {
+		filter["parent_id"] = bson.M{"$in": bson.A{"", nil}}
+	}
+	update := bson.M{"parent_id": c.ParentID, "ancestors": c.Ancestors}
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": update})
+	if err != nil {
+		utils.Log.Error("Move category failed", "id", c.ID, "err", err)
+		return conflict(err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrCategoryConflict
+	}
+
+	// descendants: new ancestors of c + their own path starting at c
+	rebase := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"ancestors": bson.M{"$concatArrays": bson.A{
+				c.Ancestors,
+				bson.M{"$slice": bson.A{
+					"$ancestors",
+					bson.M{"$indexOfArray": bson.A{"$ancestors", c.ID}},
+					bson.M{"$size": "$ancestors"},
+				}},
+			}},
+		}}},
+	}
+	if _, err := r.collection.UpdateMany(ctx, bson.M{"ancestors": c.ID}, rebase); err != nil {
+		utils.Log.Error("Move category descendants failed", "id", c.ID, "err", err)
+		return conflict(err)
+	}
+
+	return nil
+}
+
+// LockParent writes to parentID so that a transaction adding childID below
+// it conflicts with any other one that moves or deletes the parent or adds
+// below it. childID, unless empty, must not be among the parent's ancestors;
+// otherwise, or if the parent is gone, it fails with
+// domain.ErrCategoryConflict.
+func (r *CategoryRepository) LockParent(ctx context.Context, parentID, childID string) error {
+	oid, err := primitive.ObjectIDFromHex(parentID)
+	if err != nil {
+		return errors.New("invalid category ID format")
+	}
+
+	filter := bson.M{"_id": oid}
+	if childID != "" {
+		filter["ancestors"] = bson.M{"$ne": childID}
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"tree_changes": 1}})
+	if err != nil {
+		utils.Log.Error("Lock parent category failed", "id", parentID, "err", err)
+		return conflict(err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrCategoryConflict
+	}
+	return nil
+}
+
+func (r *CategoryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "parent_id", Value: 1}}},
+		{Keys: bson.D{{Key: "ancestors", Value: 1}}},
+	})
+	return err
+}
+
+func (r *CategoryRepository) find(ctx context.Context, filter any) ([]*domain.Category, error) {
+	cursor, err := r.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -93,5 +202,15 @@ func (r *CategoryRepository) List(ctx context.Context) ([]*domain.Category, erro
 		categories = append(categories, &c)
 	}
 
-	return categories, nil
+	return categories, cursor.Err()
+}
+
+// conflict reports a write conflict with a concurrent transaction as
+// domain.ErrCategoryConflict.
+func conflict(err error) error {
+	var se mongo.ServerError
+	if errors.As(err, &se) && se.HasErrorLabel("TransientTransactionError") {
+		return fmt.Errorf("%w: %v", domain.ErrCategoryConflict, err)
+	}
+	return err
 }
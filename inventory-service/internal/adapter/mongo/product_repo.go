@@ -3,12 +3,14 @@ package mongo
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ProductRepository struct {
@@ -62,29 +64,64 @@ func (r *ProductRepository) GetByID(ctx context.Context, id string) (*domain.Pro
 	return &product, nil
 }
 
-func (r *ProductRepository) Update(ctx context.Context, p *domain.Product) error {
-	// utils.Log.Info("Updating product", "id", p.ID)
+// Update applies upd only if the stored product is still at upd.Version and
+// returns the product as it is after the update. Products written before
+// versioning was introduced have no version field and are only matched by
+// version 0.
+func (r *ProductRepository) Update(ctx context.Context, upd *domain.ProductUpdate) (*domain.Product, error) {
+	// utils.Log.Info("Updating product", "id", upd.ID)
 
-	oid, err := primitive.ObjectIDFromHex(p.ID)
+	oid, err := primitive.ObjectIDFromHex(upd.ID)
 	if err != nil {
-		utils.Log.Error("Failed to convert product ID", "id", p.ID, "err", err)
-		return err
+		utils.Log.Error("Failed to convert product ID", "id", upd.ID, "err", err)
+		return nil, fmt.Errorf("%w ID", domain.ErrInvalidProduct)
+	}
+
+	set := bson.M{}
+	if upd.Name != nil {
+		set["name"] = *upd.Name
+	}
+	if upd.Price != nil {
+		set["price"] = *upd.Price
+	}
+	if upd.Category != nil {
+		set["category"] = *upd.Category
 	}
+	if upd.Stock != nil {
+		set["stock"] = *upd.Stock
+	}
+
+	var version any = upd.Version
+	if upd.Version == 0 {
+		version = bson.M{"$exists": false}
+	}
+	filter := bson.M{"_id": oid, "version": version}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
-	update := bson.M{
-		"name":     p.Name,
-		"price":    p.Price,
-		"category": p.Category,
-		"stock":    p.Stock,
+	var product domain.Product
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&product)
+	if err == nil {
+		product.ID = upd.ID
+		return &product, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		utils.Log.Error("FindOneAndUpdate failed", "id", upd.ID, "err", err)
+		return nil, err
 	}
 
-	_, err = r.collection.UpdateByID(ctx, oid, bson.M{"$set": update})
+	// either the product is gone or its version moved on
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": oid})
 	if err != nil {
-		utils.Log.Error("UpdateByID failed", "id", p.ID, "err", err)
-		return err
+		utils.Log.Error("CountDocuments failed", "id", upd.ID, "err", err)
+		return nil, err
+	}
+	if n == 0 {
+		return nil, domain.ErrProductNotFound
 	}
 
-	return nil
+	utils.Log.Warn("Product version conflict", "id", upd.ID, "version", upd.Version)
+	return nil, domain.ErrVersionConflict
 }
 
 func (r *ProductRepository) Delete(ctx context.Context, id string) error {
@@ -103,10 +140,74 @@ func (r *ProductRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
+// List returns every product in ID order, which is the order they were
+// created in.
 func (r *ProductRepository) List(ctx context.Context) ([]*domain.Product, error) {
 	// utils.Log.Info("Listing all products")
 
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	return r.find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+}
+
+// ListByIDs returns the products with the given IDs. IDs that are not valid
+// ObjectIDs cannot match any product and are ignored.
+func (r *ProductRepository) ListByIDs(ctx context.Context, ids []string) ([]*domain.Product, error) {
+	oids := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			utils.Log.Warn("Skipping invalid product ID", "id", id)
+			continue
+		}
+		oids = append(oids, oid)
+	}
+	if len(oids) == 0 {
+		return nil, nil
+	}
+
+	return r.find(ctx, bson.M{"_id": bson.M{"$in": oids}})
+}
+
+func (r *ProductRepository) ListByCategories(ctx context.Context, categoryIDs []string) ([]*domain.Product, error) {
+	return r.find(ctx, bson.M{"category": bson.M{"$in": categoryIDs}})
+}
+
+// MigrateFloatPrices rewrites prices stored as plain numbers by older
+// versions of the service into Money in the given currency. It is safe to run
+// on every start: migrated documents no longer match the filter.
+func (r *ProductRepository) MigrateFloatPrices(ctx context.Context, currency string) (int, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"price": bson.M{"$type": bson.A{"double", "int", "long"}}})
+	if err != nil {
+		return 0, fmt.Errorf("find float prices: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Price float64            `bson:"price"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return migrated, fmt.Errorf("decode product: %w", err)
+		}
+
+		price, err := domain.NewMoneyFromMajor(doc.Price, currency)
+		if err != nil {
+			return migrated, fmt.Errorf("convert price of %s: %w", doc.ID.Hex(), err)
+		}
+
+		filter := bson.M{"_id": doc.ID, "price": doc.Price}
+		if _, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"price": price}}); err != nil {
+			return migrated, fmt.Errorf("update price of %s: %w", doc.ID.Hex(), err)
+		}
+		migrated++
+	}
+
+	return migrated, cursor.Err()
+}
+
+func (r *ProductRepository) find(ctx context.Context, filter any, opts ...*options.FindOptions) ([]*domain.Product, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts...)
 	if err != nil {
 		utils.Log.Error("Find failed", "err", err)
 		return nil, err
@@ -0,0 +1,96 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// TestProductRepositoryUpdate runs Update against a mocked deployment and
+// checks the version it filters on and how a miss is reported.
+func TestProductRepositoryUpdate(t *testing.T) {
+	utils.InitLogger()
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	oid := primitive.NewObjectID()
+	name := "pear"
+	stored := bson.D{{Key: "_id", Value: oid}, {Key: "name", Value: name}, {Key: "version", Value: int64(3)}}
+	count := func(n int64) bson.D {
+		return mtest.CreateCursorResponse(0, "db.products", mtest.FirstBatch, bson.D{{Key: "n", Value: n}})
+	}
+
+	tests := []struct {
+		name        string
+		version     int64
+		responses   []bson.D
+		wantVersion any
+		wantErr     error
+	}{
+		{
+			name:        "current version",
+			version:     2,
+			responses:   []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: stored})},
+			wantVersion: int64(2),
+		},
+		{
+			name:        "modified since",
+			version:     2,
+			responses:   []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}), count(1)},
+			wantVersion: int64(2),
+			wantErr:     domain.ErrVersionConflict,
+		},
+		{
+			name:        "deleted since",
+			version:     2,
+			responses:   []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}), count(0)},
+			wantVersion: int64(2),
+			wantErr:     domain.ErrProductNotFound,
+		},
+		{
+			name:        "no version on a legacy product",
+			responses:   []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: stored})},
+			wantVersion: bson.D{{Key: "$exists", Value: false}},
+		},
+		{
+			name:        "no version on a versioned product",
+			responses:   []bson.D{mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}), count(1)},
+			wantVersion: bson.D{{Key: "$exists", Value: false}},
+			wantErr:     domain.ErrVersionConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.responses...)
+			repo := NewProductRepository(mt.DB)
+
+			p, err := repo.Update(context.Background(), &domain.ProductUpdate{ID: oid.Hex(), Version: tt.version, Name: &name})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (p == nil || p.ID != oid.Hex() || p.Version != 3) {
+				t.Errorf("Update() = %+v, want the stored product at version 3", p)
+			}
+
+			evt := mt.GetStartedEvent()
+			if evt == nil || evt.CommandName != "findAndModify" {
+				t.Fatalf("first command = %v, want findAndModify", evt)
+			}
+			got := evt.Command.Lookup("query", "version")
+			typ, want, err := bson.MarshalValue(tt.wantVersion)
+			if err != nil {
+				t.Fatalf("bson.MarshalValue: %v", err)
+			}
+			if got.Type != typ || !bytes.Equal(got.Value, want) {
+				t.Errorf("filter version = %s, want %v", got, tt.wantVersion)
+			}
+		})
+	}
+}
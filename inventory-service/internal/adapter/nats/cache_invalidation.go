@@ -0,0 +1,104 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+	natscl "github.com/Neroframe/ecommerce-platform/inventory-service/pkg/nats"
+	"github.com/nats-io/nats.go"
+)
+
+var _ domain.CacheInvalidationPublisher = (*CacheInvalidationPublisher)(nil)
+
+// CacheInvalidationPublisher broadcasts product and category cache
+// invalidations to every inventory-service replica, stamped with this
+// replica's ID.
+type CacheInvalidationPublisher struct {
+	client  *natscl.Client
+	subject string
+	origin  string
+}
+
+func NewCacheInvalidationPublisher(client *natscl.Client, subject, origin string) *CacheInvalidationPublisher {
+	return &CacheInvalidationPublisher{client: client, subject: subject, origin: origin}
+}
+
+func (p *CacheInvalidationPublisher) PublishProductInvalidation(ctx context.Context, msg domain.ProductCacheInvalidation) error {
+	msg.Origin = p.origin
+	msg.Timestamp = time.Now().UTC()
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("payload marshal error: %w", err)
+	}
+
+	if err := p.client.Conn.Publish(p.subject, data); err != nil {
+		log.Printf("[NATS] Publish failed on subject '%s': %v", p.subject, err)
+		return fmt.Errorf("nats publish error: %w", err)
+	}
+
+	log.Printf("[NATS] Cache invalidation sent product=%s op=%s version=%d", msg.ProductID, msg.Op, msg.Version)
+	return nil
+}
+
+func (p *CacheInvalidationPublisher) PublishCategoryInvalidation(ctx context.Context, msg domain.CategoryCacheInvalidation) error {
+	msg.Origin = p.origin
+	msg.Timestamp = time.Now().UTC()
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("payload marshal error: %w", err)
+	}
+
+	if err := p.client.Conn.Publish(p.subject, data); err != nil {
+		log.Printf("[NATS] Publish failed on subject '%s': %v", p.subject, err)
+		return fmt.Errorf("nats publish error: %w", err)
+	}
+
+	log.Printf("[NATS] Cache invalidation sent categories=%v", msg.CategoryIDs)
+	return nil
+}
+
+// CacheInvalidationHandler applies invalidations published by other replicas.
+type CacheInvalidationHandler struct {
+	products   domain.ProductUsecase
+	categories domain.CategoryUsecase
+	origin     string
+}
+
+func NewCacheInvalidationHandler(products domain.ProductUsecase, categories domain.CategoryUsecase, origin string) *CacheInvalidationHandler {
+	return &CacheInvalidationHandler{products: products, categories: categories, origin: origin}
+}
+
+// Handle tells product and category invalidations, which share the subject,
+// apart by the category IDs only the latter carry.
+func (h *CacheInvalidationHandler) Handle(ctx context.Context, msg *nats.Msg) error {
+	var inv struct {
+		domain.ProductCacheInvalidation
+		CategoryIDs []string `json:"category_ids"`
+	}
+	if err := json.Unmarshal(msg.Data, &inv); err != nil {
+		return fmt.Errorf("unmarshal cache invalidation: %w", err)
+	}
+
+	// our own writes already updated the local cache
+	if inv.Origin == h.origin {
+		return nil
+	}
+
+	if len(inv.CategoryIDs) > 0 {
+		log.Printf("[NATS] Cache invalidation received categories=%v from=%s", inv.CategoryIDs, inv.Origin)
+		return h.categories.ApplyCacheInvalidation(ctx, domain.CategoryCacheInvalidation{
+			CategoryIDs: inv.CategoryIDs,
+			Origin:      inv.Origin,
+			Timestamp:   inv.Timestamp,
+		})
+	}
+
+	log.Printf("[NATS] Cache invalidation received product=%s op=%s version=%d from=%s", inv.ProductID, inv.Op, inv.Version, inv.Origin)
+	return h.products.ApplyCacheInvalidation(ctx, inv.ProductCacheInvalidation)
+}
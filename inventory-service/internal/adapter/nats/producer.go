@@ -2,58 +2,44 @@ package nats
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
-	"github.com/Neroframe/ecommerce-platform/inventory-service/pkg/nats"
+	natscl "github.com/Neroframe/ecommerce-platform/inventory-service/pkg/nats"
+	"github.com/nats-io/nats.go"
 )
 
-type InventoryEventPublisher struct {
-	client *nats.Client
-}
-
-var _ domain.InventoryEventPublisher = (*InventoryEventPublisher)(nil) 
-
-func NewInventoryEventPublisher(client *nats.Client) *InventoryEventPublisher {
-	return &InventoryEventPublisher{client: client}
-}
-
-func (p *InventoryEventPublisher) PublishProductCreated(ctx context.Context, payload domain.ProductCreatedEvent) error {
-	return p.publish(ctx, "product.created", payload)
-}
+var _ domain.MessagePublisher = (*MessagePublisher)(nil)
 
-func (p *InventoryEventPublisher) PublishProductUpdated(ctx context.Context, payload domain.ProductUpdatedEvent) error {
-	return p.publish(ctx, "product.updated", payload)
+// MessagePublisher sends outbox messages to NATS. The outbox message ID goes
+// in the Nats-Msg-Id header, which JetStream uses to drop duplicates.
+type MessagePublisher struct {
+	client *natscl.Client
 }
 
-func (p *InventoryEventPublisher) PublishProductDeleted(ctx context.Context, payload domain.ProductDeletedEvent) error {
-	return p.publish(ctx, "product.deleted", payload)
+func NewMessagePublisher(client *natscl.Client) *MessagePublisher {
+	return &MessagePublisher{client: client}
 }
 
-func (p *InventoryEventPublisher) PublishCategoryCreated(ctx context.Context, payload domain.CategoryCreatedEvent) error {
-	return p.publish(ctx, "category.created", payload)
-}
-func (p *InventoryEventPublisher) PublishCategoryUpdated(ctx context.Context, payload domain.CategoryUpdatedEvent) error {
-	return p.publish(ctx, "category.updated", payload)
-}
-func (p *InventoryEventPublisher) PublishCategoryDeleted(ctx context.Context, payload domain.CategoryDeletedEvent) error {
-	return p.publish(ctx, "category.deleted", payload)
-}
-
-func (p *InventoryEventPublisher) publish(ctx context.Context, subject string, payload any) error {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("[NATS] Marshal error: %v", err)
-		return fmt.Errorf("payload marshal error: %w", err)
+func (p *MessagePublisher) Publish(ctx context.Context, subject, id string, headers map[string]string, data []byte) error {
+	msg := nats.NewMsg(subject)
+	for k, v := range headers {
+		msg.Header.Set(k, v)
 	}
-	log.Printf("[NATS] Publishing to subject '%s': %s", subject, data)
+	msg.Header.Set(nats.MsgIdHdr, id)
+	msg.Data = data
+
+	log.Printf("[NATS] Publishing %d bytes to subject '%s'", len(data), subject)
 
-	if err := p.client.Conn.Publish(subject, data); err != nil {
+	if err := p.client.Conn.PublishMsg(msg); err != nil {
 		log.Printf("[NATS] Publish failed on subject '%s': %v", subject, err)
 		return fmt.Errorf("nats publish error: %w", err)
 	}
+	// the message only counts as sent once the server has it
+	if err := p.client.Conn.FlushTimeout(nats.DefaultTimeout); err != nil {
+		return fmt.Errorf("nats flush error: %w", err)
+	}
 
 	log.Printf("[NATS] Successfully published to subject '%s'", subject)
 	return nil
@@ -0,0 +1,101 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Neroframe/ecommerce-platform/inventory-service/config"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/pkg/events"
+	eventspb "github.com/Neroframe/ecommerce-platform/inventory-service/proto/events"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ domain.InventoryEventPublisher = (*EventPublisher)(nil)
+
+// EventPublisher records inventory events in the outbox instead of sending
+// them. Called inside a transaction, the event is stored atomically with the
+// change; the Relay publishes it to NATS once committed. Events are encoded
+// as configured by EVENT_ENCODING, see package events.
+type EventPublisher struct {
+	outbox   domain.OutboxRepository
+	subjects config.NatsSubjects
+	encoding events.Encoding
+	producer string
+}
+
+func NewEventPublisher(outbox domain.OutboxRepository, subjects config.NatsSubjects, encoding events.Encoding, producer string) *EventPublisher {
+	return &EventPublisher{outbox: outbox, subjects: subjects, encoding: encoding, producer: producer}
+}
+
+func (p *EventPublisher) PublishProductCreated(ctx context.Context, payload domain.ProductCreatedEvent) error {
+	return p.add(ctx, p.subjects.ProductCreated, events.TypeProductCreated, &eventspb.ProductCreated{
+		Id:         payload.ID,
+		Name:       payload.Name,
+		Price:      toMoneyEvent(payload.Price),
+		CategoryId: payload.CategoryID,
+		Stock:      proto.Int32(int32(payload.Stock)),
+	}, payload)
+}
+
+func (p *EventPublisher) PublishProductUpdated(ctx context.Context, payload domain.ProductUpdatedEvent) error {
+	return p.add(ctx, p.subjects.ProductUpdated, events.TypeProductUpdated, &eventspb.ProductUpdated{
+		Id:         payload.ID,
+		Name:       payload.Name,
+		Price:      toMoneyEvent(payload.Price),
+		CategoryId: payload.CategoryID,
+		Version:    payload.Version,
+		Stock:      proto.Int32(int32(payload.Stock)),
+	}, payload)
+}
+
+func (p *EventPublisher) PublishProductDeleted(ctx context.Context, payload domain.ProductDeletedEvent) error {
+	return p.add(ctx, p.subjects.ProductDeleted, events.TypeProductDeleted, &eventspb.ProductDeleted{
+		Id: payload.ID,
+	}, payload)
+}
+
+func (p *EventPublisher) PublishCategoryCreated(ctx context.Context, payload domain.CategoryCreatedEvent) error {
+	return p.add(ctx, p.subjects.CategoryCreated, events.TypeCategoryCreated, &eventspb.CategoryCreated{
+		Id:       payload.ID,
+		Name:     payload.Name,
+		ParentId: payload.ParentID,
+	}, payload)
+}
+
+func (p *EventPublisher) PublishCategoryUpdated(ctx context.Context, payload domain.CategoryUpdatedEvent) error {
+	return p.add(ctx, p.subjects.CategoryUpdated, events.TypeCategoryUpdated, &eventspb.CategoryUpdated{
+		Id:       payload.ID,
+		Name:     payload.Name,
+		ParentId: payload.ParentID,
+	}, payload)
+}
+
+func (p *EventPublisher) PublishCategoryDeleted(ctx context.Context, payload domain.CategoryDeletedEvent) error {
+	return p.add(ctx, p.subjects.CategoryDeleted, events.TypeCategoryDeleted, &eventspb.CategoryDeleted{
+		Id: payload.ID,
+	}, payload)
+}
+
+// add encodes the event and stores it. The outbox message ID doubles as the
+// event ID.
+func (p *EventPublisher) add(ctx context.Context, subject, eventType string, payload proto.Message, legacy any) error {
+	id := primitive.NewObjectID().Hex()
+	meta := events.NewMetadata(ctx, id, eventType, p.producer)
+
+	data, headers, err := events.Encode(p.encoding, meta, payload, legacy)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", eventType, err)
+	}
+
+	msg := &domain.OutboxMessage{ID: id, Subject: subject, Headers: headers, Payload: data}
+	if err := p.outbox.Add(ctx, msg); err != nil {
+		return fmt.Errorf("outbox.Add: %w", err)
+	}
+	return nil
+}
+
+func toMoneyEvent(m domain.Money) *eventspb.Money {
+	return &eventspb.Money{Amount: m.Amount, Currency: m.Currency}
+}
@@ -0,0 +1,64 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Neroframe/ecommerce-platform/inventory-service/config"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/pkg/events"
+)
+
+func TestEventContracts(t *testing.T) {
+	subjects := config.NatsSubjects{
+		ProductCreated:  "product.created",
+		ProductUpdated:  "product.updated",
+		ProductDeleted:  "product.deleted",
+		CategoryCreated: "category.created",
+		CategoryUpdated: "category.updated",
+		CategoryDeleted: "category.deleted",
+	}
+	price := domain.Money{Amount: 2599, Currency: "USD"}
+
+	for _, enc := range []events.Encoding{events.EncodingJSON, events.EncodingProtobuf} {
+		t.Run(string(enc), func(t *testing.T) {
+			ctx := context.Background()
+			outbox := &recordingOutbox{}
+			p := NewEventPublisher(outbox, subjects, enc, "inventory-service")
+
+			if err := p.PublishProductCreated(ctx, domain.ProductCreatedEvent{
+				ID: "p1", Name: "apple", Price: price, CategoryID: "c1", Stock: 3,
+			}); err != nil {
+				t.Fatalf("PublishProductCreated: %v", err)
+			}
+			if err := p.PublishProductUpdated(ctx, domain.ProductUpdatedEvent{
+				ID: "p1", Name: "apple", Price: price, CategoryID: "c1", Version: 2, Stock: 0,
+			}); err != nil {
+				t.Fatalf("PublishProductUpdated: %v", err)
+			}
+			if err := p.PublishProductDeleted(ctx, domain.ProductDeletedEvent{ID: "p1"}); err != nil {
+				t.Fatalf("PublishProductDeleted: %v", err)
+			}
+			if err := p.PublishCategoryCreated(ctx, domain.CategoryCreatedEvent{ID: "c1", Name: "fruit", ParentID: "c0"}); err != nil {
+				t.Fatalf("PublishCategoryCreated: %v", err)
+			}
+			if err := p.PublishCategoryUpdated(ctx, domain.CategoryUpdatedEvent{ID: "c1", Name: "fresh fruit", ParentID: "c0"}); err != nil {
+				t.Fatalf("PublishCategoryUpdated: %v", err)
+			}
+			if err := p.PublishCategoryDeleted(ctx, domain.CategoryDeletedEvent{ID: "c1"}); err != nil {
+				t.Fatalf("PublishCategoryDeleted: %v", err)
+			}
+
+			names := []string{
+				events.TypeProductCreated, events.TypeProductUpdated, events.TypeProductDeleted,
+				events.TypeCategoryCreated, events.TypeCategoryUpdated, events.TypeCategoryDeleted,
+			}
+			if len(outbox.msgs) != len(names) {
+				t.Fatalf("%d messages added, want %d", len(outbox.msgs), len(names))
+			}
+			for i, msg := range outbox.msgs {
+				checkContract(t, enc, names[i], msg)
+			}
+		})
+	}
+}
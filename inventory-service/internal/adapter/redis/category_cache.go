@@ -0,0 +1,127 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/pkg/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var _ domain.CategoryRedisCache = (*CategoryCache)(nil)
+
+const categoryKeyPrefix = "category:%s"
+const categoryListKey = "category:list"
+
+type CategoryCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewCategoryCache(client *redis.Client, ttl time.Duration) *CategoryCache {
+	return &CategoryCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *CategoryCache) Set(ctx context.Context, category *domain.Category) error {
+	data, err := json.Marshal(category)
+	if err != nil {
+		return fmt.Errorf("failed to marshal category Set: %w", err)
+	}
+
+	key := c.key(category.ID)
+	if err := c.client.Unwrap().Set(ctx, key, data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("redis Set error: %w", err)
+	}
+
+	log.Printf("[Redis] Set category key=%s ttl=%s", key, c.ttl)
+	return nil
+}
+
+func (c *CategoryCache) SetMany(ctx context.Context, categories []*domain.Category) error {
+	pipe := c.client.Unwrap().Pipeline()
+	for _, category := range categories {
+		data, err := json.Marshal(category)
+		if err != nil {
+			return fmt.Errorf("failed to marshal category SetMany: %w", err)
+		}
+		pipe.Set(ctx, c.key(category.ID), data, c.ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set many categories: %w", err)
+	}
+
+	log.Printf("[Redis] SetMany committed %d categories", len(categories))
+	return nil
+}
+
+func (c *CategoryCache) Get(ctx context.Context, categoryID string) (*domain.Category, error) {
+	key := c.key(categoryID)
+	data, err := c.client.Unwrap().Get(ctx, key).Bytes()
+	if err != nil {
+		if err == goredis.Nil {
+			log.Printf("[Redis] MISS for key=%s", key)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	log.Printf("[Redis] HIT for key=%s", key)
+
+	var category domain.Category
+	if err := json.Unmarshal(data, &category); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal category: %w", err)
+	}
+
+	return &category, nil
+}
+
+func (c *CategoryCache) Delete(ctx context.Context, categoryID string) error {
+	return c.client.Unwrap().Del(ctx, c.key(categoryID)).Err()
+}
+
+func (c *CategoryCache) SetList(ctx context.Context, categories []*domain.Category) error {
+	data, err := json.Marshal(categories)
+	if err != nil {
+		return fmt.Errorf("marshal category list: %w", err)
+	}
+
+	if err := c.client.Unwrap().Set(ctx, categoryListKey, data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("redis SetList error: %w", err)
+	}
+
+	log.Printf("[Redis] Set category list key=%s count=%d ttl=%s", categoryListKey, len(categories), c.ttl)
+	return nil
+}
+
+func (c *CategoryCache) GetList(ctx context.Context) ([]*domain.Category, error) {
+	data, err := c.client.Unwrap().Get(ctx, categoryListKey).Bytes()
+	if err != nil {
+		if err == goredis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get category list: %w", err)
+	}
+
+	var categories []*domain.Category
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return nil, fmt.Errorf("unmarshal category list: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (c *CategoryCache) DeleteList(ctx context.Context) error {
+	return c.client.Unwrap().Del(ctx, categoryListKey).Err()
+}
+
+func (c *CategoryCache) key(id string) string {
+	return fmt.Sprintf(categoryKeyPrefix, id)
+}
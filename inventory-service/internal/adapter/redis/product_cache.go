@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"time"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
@@ -15,20 +16,62 @@ import (
 var _ domain.ProductRedisCache = (*ProductCache)(nil)
 
 const keyPrefix = "product:%s"
-const productListKey = "product:list"
 
+// The product list is cached per generation under product:list:<generation>.
+// Every product write increments productListGenKey, which retires the cached
+// list at once without racing readers that are still filling an older one.
+const (
+	productListKeyPrefix = "product:list:%d"
+	productListGenKey    = "product:list:gen"
+)
+
+// setListScript stores ARGV[2] under KEYS[2] for ARGV[3] milliseconds if the
+// generation in KEYS[1] still equals ARGV[1]. It returns 1 if the list was
+// stored.
+var setListScript = goredis.NewScript(`
+local current = redis.call("GET", KEYS[1]) or "0"
+if current ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[2], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// setProductScript stores the product ARGV[1] at version ARGV[2] under
+// KEYS[1] for ARGV[3] milliseconds unless the cached product is newer. It
+// returns 1 if the product was stored.
+var setProductScript = goredis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current then
+	local ok, cached = pcall(cjson.decode, current)
+	if ok and type(cached.Version) == "number" and cached.Version > tonumber(ARGV[2]) then
+		return 0
+	end
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[3])
+return 1
+`)
+
+// ProductCache stores products for ttl plus a random share of up to jitter
+// of it, so keys written together, e.g. by a cache refresh, do not expire
+// together.
 type ProductCache struct {
 	client *redis.Client
 	ttl    time.Duration
+	jitter float64
 }
 
-func NewProductCache(client *redis.Client, ttl time.Duration) *ProductCache {
+func NewProductCache(client *redis.Client, ttl time.Duration, jitter float64) *ProductCache {
 	return &ProductCache{
 		client: client,
 		ttl:    ttl,
+		jitter: jitter,
 	}
 }
 
+// Set caches product unless a newer version of it is cached already, so a
+// reader that loaded the product before an update cannot overwrite the
+// updated product with its copy.
 func (c *ProductCache) Set(ctx context.Context, product *domain.Product) error {
 	data, err := json.Marshal(product)
 	if err != nil {
@@ -36,23 +79,41 @@ func (c *ProductCache) Set(ctx context.Context, product *domain.Product) error {
 	}
 
 	key := c.key(product.ID)
-	err = c.client.Unwrap().Set(ctx, key, data, c.ttl).Err()
+	ttl := c.expiry()
+	stored, err := setProductScript.Run(ctx, c.client.Unwrap(), []string{key}, data, product.Version, ttl.Milliseconds()).Int()
 	if err != nil {
 		return fmt.Errorf("redis Set error: %w", err)
 	}
+	if stored == 0 {
+		log.Printf("[Redis] Skip outdated product key=%s version=%d", key, product.Version)
+		return nil
+	}
 
-	log.Printf("[Redis] Set product key=%s ttl=%s", key, c.ttl)
+	log.Printf("[Redis] Set product key=%s ttl=%s", key, ttl)
 	return nil
 }
 
+// SetMany caches products in one pipeline with the same version check as
+// Set.
 func (c *ProductCache) SetMany(ctx context.Context, products []*domain.Product) error {
-	pipe := c.client.Unwrap().Pipeline()
+	if len(products) == 0 {
+		return nil
+	}
+
+	// Load the script first, EvalSha in a pipeline cannot fall back to Eval.
+	client := c.client.Unwrap()
+	if err := setProductScript.Load(ctx, client).Err(); err != nil {
+		return fmt.Errorf("failed to load set product script: %w", err)
+	}
+
+	pipe := client.Pipeline()
+	cmds := make([]*goredis.Cmd, 0, len(products))
 	for _, product := range products {
 		data, err := json.Marshal(product)
 		if err != nil {
 			return fmt.Errorf("failed to marshal product SetMany: %w", err)
 		}
-		pipe.Set(ctx, c.key(product.ID), data, c.ttl)
+		cmds = append(cmds, setProductScript.EvalSha(ctx, pipe, []string{c.key(product.ID)}, data, product.Version, c.expiry().Milliseconds()))
 	}
 
 	_, err := pipe.Exec(ctx)
@@ -60,7 +121,14 @@ func (c *ProductCache) SetMany(ctx context.Context, products []*domain.Product)
 		return fmt.Errorf("failed to set many products: %w", err)
 	}
 
-	log.Printf("[Redis] SetMany committed %d products", len(products))
+	skipped := 0
+	for _, cmd := range cmds {
+		if stored, _ := cmd.Int(); stored == 0 {
+			skipped++
+		}
+	}
+
+	log.Printf("[Redis] SetMany committed %d products, skipped %d outdated", len(products)-skipped, skipped)
 	return nil
 }
 
@@ -86,42 +154,130 @@ func (c *ProductCache) Get(ctx context.Context, productID string) (*domain.Produ
 	return &product, nil
 }
 
+// GetMany fetches productIDs with a single MGET and returns the products that
+// were cached.
+func (c *ProductCache) GetMany(ctx context.Context, productIDs []string) ([]*domain.Product, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(productIDs))
+	for i, id := range productIDs {
+		keys[i] = c.key(id)
+	}
+
+	values, err := c.client.Unwrap().MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get many products: %w", err)
+	}
+
+	products := make([]*domain.Product, 0, len(values))
+	for i, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue // nil for a missing key
+		}
+
+		var product domain.Product
+		if err := json.Unmarshal([]byte(data), &product); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal product %s: %w", productIDs[i], err)
+		}
+		products = append(products, &product)
+	}
+
+	log.Printf("[Redis] MGET products: hits=%d misses=%d", len(products), len(productIDs)-len(products))
+	return products, nil
+}
+
 func (c *ProductCache) Delete(ctx context.Context, productID string) error {
 	return c.client.Unwrap().Del(ctx, c.key(productID)).Err()
 }
 
-func (c *ProductCache) SetList(ctx context.Context, products []*domain.Product) error {
+// SetList stores the catalog loaded while generation was current. The list
+// is only written if no writer bumped the generation in the meantime, so a
+// slow reader cannot overwrite a newer invalidation with the data it loaded
+// before it.
+func (c *ProductCache) SetList(ctx context.Context, generation int64, products []*domain.Product) error {
 	data, err := json.Marshal(products)
 	if err != nil {
 		return fmt.Errorf("marshal product list: %w", err)
 	}
 
-	err = c.client.Unwrap().Set(ctx, productListKey, data, c.ttl).Err()
+	key := c.listKey(generation)
+	ttl := c.expiry()
+	stored, err := setListScript.Run(ctx, c.client.Unwrap(), []string{productListGenKey, key}, generation, data, ttl.Milliseconds()).Int()
 	if err != nil {
 		return fmt.Errorf("redis SetList error: %w", err)
 	}
+	if stored == 0 {
+		log.Printf("[Redis] Skip product list of outdated generation=%d", generation)
+		return nil
+	}
 
-	log.Printf("[Redis] Set product list key=%s count=%d ttl=%s", productListKey, len(products), c.ttl)
+	log.Printf("[Redis] Set product list key=%s count=%d ttl=%s", key, len(products), ttl)
 	return nil
 }
 
-func (c *ProductCache) GetList(ctx context.Context) ([]*domain.Product, error) {
-	data, err := c.client.Unwrap().Get(ctx, productListKey).Bytes()
+// GetList returns the cached catalog of the current generation, or nil when
+// it is not cached. The generation is returned in both cases and has to be
+// passed to SetList after loading the catalog from the database.
+func (c *ProductCache) GetList(ctx context.Context) ([]*domain.Product, int64, error) {
+	generation, err := c.ListGeneration(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := c.client.Unwrap().Get(ctx, c.listKey(generation)).Bytes()
 	if err != nil {
 		if err == goredis.Nil {
-			return nil, nil
+			return nil, generation, nil
 		}
-		return nil, fmt.Errorf("get product list: %w", err)
+		return nil, generation, fmt.Errorf("get product list: %w", err)
 	}
 
 	var products []*domain.Product
 	if err := json.Unmarshal(data, &products); err != nil {
-		return nil, fmt.Errorf("unmarshal product list: %w", err)
+		return nil, generation, fmt.Errorf("unmarshal product list: %w", err)
 	}
 
-	return products, nil
+	return products, generation, nil
+}
+
+// ListGeneration returns the current list generation, 0 before the first
+// write.
+func (c *ProductCache) ListGeneration(ctx context.Context) (int64, error) {
+	generation, err := c.client.Unwrap().Get(ctx, productListGenKey).Int64()
+	if err != nil && err != goredis.Nil {
+		return 0, fmt.Errorf("get product list generation: %w", err)
+	}
+	return generation, nil
+}
+
+// InvalidateList starts a new list generation. Lists of older generations
+// are never read again and expire with their TTL.
+func (c *ProductCache) InvalidateList(ctx context.Context) error {
+	generation, err := c.client.Unwrap().Incr(ctx, productListGenKey).Result()
+	if err != nil {
+		return fmt.Errorf("incr product list generation: %w", err)
+	}
+
+	log.Printf("[Redis] Product list generation=%d", generation)
+	return nil
 }
 
 func (c *ProductCache) key(id string) string {
 	return fmt.Sprintf(keyPrefix, id)
 }
+
+// expiry returns the TTL for a new key.
+func (c *ProductCache) expiry() time.Duration {
+	spread := int64(float64(c.ttl) * c.jitter)
+	if spread <= 0 {
+		return c.ttl
+	}
+	return c.ttl + time.Duration(rand.Int64N(spread))
+}
+
+func (c *ProductCache) listKey(generation int64) string {
+	return fmt.Sprintf(productListKeyPrefix, generation)
+}
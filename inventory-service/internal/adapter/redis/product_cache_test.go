@@ -0,0 +1,230 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/pkg/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestProductCache(t *testing.T) *ProductCache {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client, err := redis.NewClient(context.Background(), redis.Config{Host: mr.Addr()})
+	if err != nil {
+		t.Fatalf("redis.NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewProductCache(client, time.Minute, 0)
+}
+
+func TestProductCacheSetListSkipsOutdatedGeneration(t *testing.T) {
+	ctx := context.Background()
+	c := newTestProductCache(t)
+
+	list, generation, err := c.GetList(ctx)
+	if err != nil || list != nil || generation != 0 {
+		t.Fatalf("GetList() = %v, %d, %v, want a miss of generation 0", list, generation, err)
+	}
+
+	if err := c.InvalidateList(ctx); err != nil {
+		t.Fatalf("InvalidateList: %v", err)
+	}
+	if err := c.SetList(ctx, generation, []*domain.Product{{ID: "stale"}}); err != nil {
+		t.Fatalf("SetList: %v", err)
+	}
+
+	list, generation, err = c.GetList(ctx)
+	if err != nil || list != nil || generation != 1 {
+		t.Fatalf("GetList() after a stale SetList = %v, %d, %v, want a miss of generation 1", list, generation, err)
+	}
+
+	if err := c.SetList(ctx, generation, []*domain.Product{{ID: "fresh"}}); err != nil {
+		t.Fatalf("SetList: %v", err)
+	}
+	list, _, err = c.GetList(ctx)
+	if err != nil || len(list) != 1 || list[0].ID != "fresh" {
+		t.Fatalf("GetList() = %v, %v, want the fresh list", list, err)
+	}
+}
+
+func TestProductCacheSetSkipsOutdatedVersion(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		cached []*domain.Product
+		set    []*domain.Product
+		many   bool
+		want   int64
+	}{
+		{name: "not cached", set: []*domain.Product{{ID: "a", Version: 1}}, want: 1},
+		{name: "newer version", cached: []*domain.Product{{ID: "a", Version: 1}}, set: []*domain.Product{{ID: "a", Version: 2}}, want: 2},
+		{name: "same version", cached: []*domain.Product{{ID: "a", Version: 2}}, set: []*domain.Product{{ID: "a", Version: 2}}, want: 2},
+		{name: "older version", cached: []*domain.Product{{ID: "a", Version: 3}}, set: []*domain.Product{{ID: "a", Version: 2}}, want: 3},
+		{name: "legacy product", cached: []*domain.Product{{ID: "a"}}, set: []*domain.Product{{ID: "a", Version: 1}}, want: 1},
+		{
+			name:   "many, older version",
+			cached: []*domain.Product{{ID: "a", Version: 3}},
+			set:    []*domain.Product{{ID: "b", Version: 1}, {ID: "a", Version: 2}},
+			many:   true,
+			want:   3,
+		},
+		{
+			name:   "many, newer version",
+			cached: []*domain.Product{{ID: "a", Version: 1}},
+			set:    []*domain.Product{{ID: "a", Version: 2}},
+			many:   true,
+			want:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestProductCache(t)
+			for _, p := range tt.cached {
+				if err := c.Set(ctx, p); err != nil {
+					t.Fatalf("Set: %v", err)
+				}
+			}
+
+			var err error
+			if tt.many {
+				err = c.SetMany(ctx, tt.set)
+			} else {
+				err = c.Set(ctx, tt.set[0])
+			}
+			if err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+
+			got, err := c.Get(ctx, "a")
+			if err != nil || got == nil {
+				t.Fatalf("Get() = %v, %v, want a hit", got, err)
+			}
+			if got.Version != tt.want {
+				t.Errorf("cached version = %d, want %d", got.Version, tt.want)
+			}
+			if len(tt.set) > 1 {
+				if b, err := c.Get(ctx, "b"); err != nil || b == nil {
+					t.Errorf("Get(b) = %v, %v, want the other product cached", b, err)
+				}
+			}
+		})
+	}
+}
+
+// TestProductCacheConcurrentSetListAndInvalidateList runs readers that fill
+// the list on a miss against writers that change the catalog and invalidate
+// the list, the way the product usecase does. The catalog is a counter; a
+// list holds the counter it was loaded at as the stock of its only product.
+// Once a writer's InvalidateList returned, no list loaded before its write
+// may be served.
+func TestProductCacheConcurrentSetListAndInvalidateList(t *testing.T) {
+	const (
+		writers  = 2
+		readers  = 8
+		writes   = 50
+		deadline = 10 * time.Second
+	)
+
+	ctx := context.Background()
+	c := newTestProductCache(t)
+
+	var (
+		catalog     atomic.Int64 // version of the database
+		invalidated atomic.Int64 // highest version whose invalidation returned
+		done        atomic.Bool
+		served      atomic.Int64
+	)
+
+	var writersWG sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		writersWG.Add(1)
+		go func() {
+			defer writersWG.Done()
+			for i := 0; i < writes; i++ {
+				version := catalog.Add(1)
+				if err := c.InvalidateList(ctx); err != nil {
+					t.Errorf("InvalidateList: %v", err)
+					return
+				}
+				for {
+					seen := invalidated.Load()
+					if seen >= version || invalidated.CompareAndSwap(seen, version) {
+						break
+					}
+				}
+				time.Sleep(2 * time.Millisecond) // let readers fill and serve lists in between
+			}
+		}()
+	}
+
+	var readersWG sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		readersWG.Add(1)
+		go func() {
+			defer readersWG.Done()
+			for !done.Load() {
+				floor := invalidated.Load()
+				list, generation, err := c.GetList(ctx)
+				if err != nil {
+					t.Errorf("GetList: %v", err)
+					return
+				}
+				if list != nil {
+					if len(list) != 1 {
+						t.Errorf("GetList() returned %d products, want 1", len(list))
+						return
+					}
+					if loaded := int64(list[0].Stock); loaded < floor {
+						t.Errorf("GetList() served a list loaded at version %d after version %d was invalidated", loaded, floor)
+						return
+					}
+					served.Add(1)
+					continue
+				}
+
+				loaded := catalog.Load()
+				if err := c.SetList(ctx, generation, []*domain.Product{{ID: "p", Stock: int(loaded)}}); err != nil {
+					t.Errorf("SetList: %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	writersDone := make(chan struct{})
+	go func() {
+		writersWG.Wait()
+		close(writersDone)
+	}()
+	select {
+	case <-writersDone:
+	case <-time.After(deadline):
+		t.Error("writers did not finish in time")
+	}
+	done.Store(true)
+	readersWG.Wait()
+
+	list, generation, err := c.GetList(ctx)
+	if err != nil {
+		t.Fatalf("GetList: %v", err)
+	}
+	if generation != writers*writes {
+		t.Errorf("generation = %d, want %d", generation, writers*writes)
+	}
+	if list != nil && int64(list[0].Stock) != catalog.Load() {
+		t.Errorf("final list loaded at version %d, want %d", list[0].Stock, catalog.Load())
+	}
+	if served.Load() == 0 {
+		t.Error("no cached list was served, the race was not exercised")
+	}
+}
@@ -13,12 +13,16 @@ import (
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/adapter/inmemory"
 	mongoadapter "github.com/Neroframe/ecommerce-platform/inventory-service/internal/adapter/mongo"
 	natsadapter "github.com/Neroframe/ecommerce-platform/inventory-service/internal/adapter/nats"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/adapter/outbox"
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/adapter/redis"
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/usecase"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/pkg/events"
 	mongoconn "github.com/Neroframe/ecommerce-platform/inventory-service/pkg/mongo"
 	natsconn "github.com/Neroframe/ecommerce-platform/inventory-service/pkg/nats"
+	natsconsumer "github.com/Neroframe/ecommerce-platform/inventory-service/pkg/nats/consumer"
 	redisconn "github.com/Neroframe/ecommerce-platform/inventory-service/pkg/redis"
+	"github.com/Neroframe/ecommerce-platform/inventory-service/pkg/safe"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/config"
 )
@@ -28,7 +32,13 @@ const serviceName = "inventory-service"
 type App struct {
 	grpcServer *grpcadapter.API
 	productUC  domain.ProductUsecase
-	// natsConsumer *natsconsumer.PubSub
+	categoryUC domain.CategoryUsecase
+
+	productCache  domain.ProductMemoryCache
+	statsInterval time.Duration
+
+	natsConsumer *natsconsumer.PubSub
+	outboxRelay  *outbox.Relay
 }
 
 func New(ctx context.Context, cfg *config.Config) (*App, error) {
@@ -40,7 +50,22 @@ func New(ctx context.Context, cfg *config.Config) (*App, error) {
 	}
 
 	productRepo := mongoadapter.NewProductRepository(mongoDB.Conn)
+	migrated, err := productRepo.MigrateFloatPrices(ctx, cfg.DefaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("productRepo.MigrateFloatPrices: %w", err)
+	}
+	if migrated > 0 {
+		log.Printf("migrated %d product prices to %s minor units", migrated, cfg.DefaultCurrency)
+	}
 	categoryRepo := mongoadapter.NewCategoryRepository(mongoDB.Conn)
+	if err := categoryRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("categoryRepo.EnsureIndexes: %w", err)
+	}
+	outboxRepo := mongoadapter.NewOutboxRepository(mongoDB.Conn)
+	if err := outboxRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("outboxRepo.EnsureIndexes: %w", err)
+	}
+	transactor := mongoadapter.NewTransactor(mongoDB.Client)
 
 	// NATS client
 	natsClient, err := natsconn.NewClient(ctx, cfg.Nats.Hosts, cfg.Nats.NKey, cfg.Nats.IsTest)
@@ -57,19 +82,53 @@ func New(ctx context.Context, cfg *config.Config) (*App, error) {
 	log.Println("Redis is connected:", redisClient.Ping(ctx) == nil)
 
 	// Cache inmemory & redis
-	productInmemoryCache := inmemory.NewProductCache()
-	productRedisCache := redis.NewProductCache(redisClient, cfg.Cache.ProductTTL)
+	productInmemoryCache := inmemory.NewProductCache(cfg.Cache.MemoryMaxEntries, cfg.Cache.MemoryTTL, cfg.Cache.MemoryStaleTTL)
+	productRedisCache := redis.NewProductCache(redisClient, cfg.Cache.ProductTTL, cfg.Cache.ProductTTLJitter)
+	categoryInmemoryCache := inmemory.NewCategoryCache()
+	categoryRedisCache := redis.NewCategoryCache(redisClient, cfg.Cache.CategoryTTL)
+
+	instanceID := cfg.InstanceID
+	if instanceID == "" {
+		if instanceID, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("os.Hostname: %w", err)
+		}
+	}
+
+	// Events go to the outbox and are relayed to NATS after commit
+	eventEncoding, err := events.ParseEncoding(cfg.Nats.EventEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("events.ParseEncoding: %w", err)
+	}
+	eventPublisher := outbox.NewEventPublisher(outboxRepo, cfg.Nats.NatsSubjects, eventEncoding, serviceName)
+	outboxRelay := outbox.NewRelay(outboxRepo, natsadapter.NewMessagePublisher(natsClient), outbox.RelayConfig(cfg.Outbox))
 
 	// NATS publisher
-	eventPublisher := natsadapter.NewInventoryEventPublisher(natsClient)
+	invalidationPublisher := natsadapter.NewCacheInvalidationPublisher(natsClient, cfg.Nats.NatsSubjects.CacheInvalidation, instanceID)
 
 	// UC
-	productUC := usecase.NewProductUsecase(productRepo, eventPublisher, productInmemoryCache, productRedisCache)
-	categoryUC := usecase.NewCategoryUsecase(categoryRepo, eventPublisher)
+	productUC := usecase.NewProductUsecase(productRepo, eventPublisher, transactor, productInmemoryCache, productRedisCache, invalidationPublisher)
+	categoryUC := usecase.NewCategoryUsecase(categoryRepo, eventPublisher, transactor, categoryInmemoryCache, categoryRedisCache, invalidationPublisher)
+
+	// NATS consumer for cache invalidations of the other replicas
+	invalidationHandler := natsadapter.NewCacheInvalidationHandler(productUC, categoryUC, instanceID)
+	natsConsumer := natsconsumer.NewPubSub(natsClient)
+	natsConsumer.Subscribe(natsconsumer.PubSubSubscriptionConfig{
+		Subject: cfg.Nats.NatsSubjects.CacheInvalidation,
+		Handler: invalidationHandler.Handle,
+	})
 
 	grpcAPI := grpcadapter.New(cfg.Server.GRPCServer, productUC, categoryUC)
 
-	return &App{grpcServer: grpcAPI, productUC: productUC}, nil
+	return &App{
+		grpcServer:   grpcAPI,
+		productUC:    productUC,
+		categoryUC:   categoryUC,
+		natsConsumer: natsConsumer,
+		outboxRelay:  outboxRelay,
+
+		productCache:  productInmemoryCache,
+		statsInterval: cfg.Cache.MemoryStatsInterval,
+	}, nil
 }
 
 func (a *App) Run() error {
@@ -81,6 +140,10 @@ func (a *App) Run() error {
 		log.Printf("Error warming up product cache: %v", err)
 		return fmt.Errorf("failed to warm up cache: %w", err)
 	}
+	if err := a.categoryUC.RefreshCategoriesCache(ctx); err != nil {
+		log.Printf("Error warming up category cache: %v", err)
+		return fmt.Errorf("failed to warm up category cache: %w", err)
+	}
 
 	// Refresh every 12 hour
 	go func() {
@@ -93,14 +156,41 @@ func (a *App) Run() error {
 				if err := a.productUC.RefreshProductsCache(context.Background()); err != nil {
 					log.Printf("Periodic cache refresh failed: %v", err)
 				}
+				if err := a.categoryUC.RefreshCategoriesCache(context.Background()); err != nil {
+					log.Printf("Periodic category cache refresh failed: %v", err)
+				}
 			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
-	// Start grpc server
+	// Report in-memory cache effectiveness
+	go func() {
+		ticker := time.NewTicker(a.statsInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s := a.productCache.Stats()
+				log.Printf("[InMemory] product cache stats: entries=%d hits=%d stale_hits=%d misses=%d evictions=%d expirations=%d",
+					s.Entries, s.Hits, s.StaleHits, s.Misses, s.Evictions, s.Expirations)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Publish committed events
+	go safe.Do(ctx, func() { a.outboxRelay.Run(ctx) })
+
 	errCh := make(chan error, 1)
+
+	// Start cache invalidation consumer
+	a.natsConsumer.Start(ctx, errCh)
+
+	// Start grpc server
 	a.grpcServer.Run(ctx, errCh)
 	log.Println("Inventory service is running")
 
@@ -116,6 +206,7 @@ func (a *App) Run() error {
 		if cerr := a.grpcServer.Stop(ctx); cerr != nil {
 			log.Printf("gRPC stop error: %v", cerr)
 		}
+		a.natsConsumer.Stop()
 		return nil
 	}
 }
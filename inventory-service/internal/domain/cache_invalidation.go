@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	CacheOpUpsert = "upsert"
+	CacheOpDelete = "delete"
+)
+
+// ProductCacheInvalidation tells the other replicas that a product changed so
+// they can drop their in-memory copy. Version is the product version after
+// the write; replicas ignore messages older than what they already hold.
+type ProductCacheInvalidation struct {
+	ProductID string    `json:"product_id"`
+	Version   int64     `json:"version"`
+	Op        string    `json:"op"`
+	Origin    string    `json:"origin"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CategoryCacheInvalidation tells the other replicas that categories changed
+// so they can drop their in-memory copies and list. A move lists the whole
+// subtree, whose ancestors all changed.
+type CategoryCacheInvalidation struct {
+	CategoryIDs []string  `json:"category_ids"`
+	Origin      string    `json:"origin"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type CacheInvalidationPublisher interface {
+	PublishProductInvalidation(ctx context.Context, msg ProductCacheInvalidation) error
+	PublishCategoryInvalidation(ctx context.Context, msg CategoryCacheInvalidation) error
+}
@@ -2,21 +2,69 @@ package domain
 
 import "context"
 
+// CacheStats counts in-memory cache lookups since start. StaleHits are
+// lookups served from an entry past its TTL, Evictions entries pushed out by
+// the size limit and Expirations entries dropped once they were too old to
+// be served at all.
+type CacheStats struct {
+	Hits        uint64
+	StaleHits   uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+	Entries     int
+}
+
+// ProductMemoryCache serves entries past their TTL for a grace period with
+// stale set, so callers can answer from them while refreshing in the
+// background.
 type ProductMemoryCache interface {
-	Get(productID string) (*Product, bool)
+	Get(productID string) (product *Product, stale bool, ok bool)
+	GetMany(productIDs []string) (found []*Product, missing []string)
 	Set(product *Product)
 	SetMany(products []*Product)
 	Delete(productID string)
+	Invalidate(productID string, version int64, deleted bool)
+
+	GetList() (products []*Product, stale bool, ok bool)
+	SetList(products []*Product)
 
-	GetList() ([]*Product, bool)
+	Stats() CacheStats
 }
 
 type ProductRedisCache interface {
 	Get(ctx context.Context, productID string) (*Product, error)
+	GetMany(ctx context.Context, productIDs []string) ([]*Product, error)
 	Set(ctx context.Context, product *Product) error
 	SetMany(ctx context.Context, products []*Product) error
 	Delete(ctx context.Context, productID string) error
 
-	GetList(ctx context.Context) ([]*Product, error)
-	SetList(ctx context.Context, products []*Product) error
+	// The cached list belongs to a generation that every write moves on with
+	// InvalidateList. SetList only stores a list for the current generation.
+	GetList(ctx context.Context) ([]*Product, int64, error)
+	SetList(ctx context.Context, generation int64, products []*Product) error
+	ListGeneration(ctx context.Context) (int64, error)
+	InvalidateList(ctx context.Context) error
+}
+
+type CategoryMemoryCache interface {
+	Get(categoryID string) (*Category, bool)
+	Set(category *Category)
+	Warm(category *Category)
+	SetMany(categories []*Category)
+	Delete(categoryID string)
+
+	GetList() ([]*Category, bool)
+	SetList(categories []*Category)
+}
+
+type CategoryRedisCache interface {
+	Get(ctx context.Context, categoryID string) (*Category, error)
+	Set(ctx context.Context, category *Category) error
+	SetMany(ctx context.Context, categories []*Category) error
+	Delete(ctx context.Context, categoryID string) error
+
+	GetList(ctx context.Context) ([]*Category, error)
+	SetList(ctx context.Context, categories []*Category) error
+	DeleteList(ctx context.Context) error
 }
@@ -2,12 +2,31 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"strings"
 )
 
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryCycle       = errors.New("category cannot be moved under itself or its descendants")
+	ErrCategoryHasChildren = errors.New("category has child categories")
+	ErrCategoryConflict    = errors.New("category tree was modified concurrently")
+)
+
+// Category is a node of the category tree. Ancestors holds the IDs of every
+// parent category ordered from the root down to the direct parent, so the
+// whole subtree of a category can be fetched with a single query.
 type Category struct {
-	ID   string `bson:"_id,omitempty"`
-	Name string `bson:"name"`
+	ID        string   `bson:"_id,omitempty"`
+	Name      string   `bson:"name"`
+	ParentID  string   `bson:"parent_id"`
+	Ancestors []string `bson:"ancestors"`
+}
+
+// CategoryNode is a category together with its nested children.
+type CategoryNode struct {
+	Category *Category
+	Children []*CategoryNode
 }
 
 type CategoryRepository interface {
@@ -16,6 +35,12 @@ type CategoryRepository interface {
 	Update(ctx context.Context, c *Category) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]*Category, error)
+
+	ListByIDs(ctx context.Context, ids []string) ([]*Category, error)
+	ListChildren(ctx context.Context, parentID string) ([]*Category, error)
+	ListDescendants(ctx context.Context, id string) ([]*Category, error)
+	Move(ctx context.Context, c *Category, oldParentID string) error
+	LockParent(ctx context.Context, parentID, childID string) error
 }
 
 type CategoryUsecase interface {
@@ -24,8 +49,36 @@ type CategoryUsecase interface {
 	Update(ctx context.Context, c *Category) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]*Category, error)
+
+	ListChildren(ctx context.Context, parentID string) ([]*Category, error)
+	GetSubtree(ctx context.Context, id string) (*CategoryNode, error)
+	GetBreadcrumbs(ctx context.Context, id string) ([]*Category, error)
+	Move(ctx context.Context, id, newParentID string) (*Category, error)
+	SubtreeIDs(ctx context.Context, id string) ([]string, error)
+	RefreshCategoriesCache(ctx context.Context) error
+	ApplyCacheInvalidation(ctx context.Context, msg CategoryCacheInvalidation) error
 }
 
 func (c *Category) NormalizeName() {
 	c.Name = strings.ToLower(strings.TrimSpace(c.Name))
 }
+
+// Path returns the IDs of the category's ancestors followed by its own ID.
+func (c *Category) Path() []string {
+	path := make([]string, 0, len(c.Ancestors)+1)
+	path = append(path, c.Ancestors...)
+	return append(path, c.ID)
+}
+
+// IsAncestorOf reports whether c is other itself or one of its ancestors.
+func (c *Category) IsAncestorOf(other *Category) bool {
+	if c.ID == other.ID {
+		return true
+	}
+	for _, id := range other.Ancestors {
+		if id == c.ID {
+			return true
+		}
+	}
+	return false
+}
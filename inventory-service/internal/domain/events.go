@@ -5,15 +5,18 @@ import "context"
 type ProductCreatedEvent struct {
 	ID         string `json:"id"`
 	Name       string `json:"name"`
-	Price      int    `json:"price"`
+	Price      Money  `json:"price"`
 	CategoryID string `json:"category_id"`
+	Stock      int    `json:"stock"`
 }
 
 type ProductUpdatedEvent struct {
 	ID         string `json:"id"`
 	Name       string `json:"name"`
-	Price      int    `json:"price"`
+	Price      Money  `json:"price"`
 	CategoryID string `json:"category_id"`
+	Version    int64  `json:"version"`
+	Stock      int    `json:"stock"`
 }
 
 type ProductDeletedEvent struct {
@@ -21,13 +24,15 @@ type ProductDeletedEvent struct {
 }
 
 type CategoryCreatedEvent struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
 }
 
 type CategoryUpdatedEvent struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
 }
 
 type CategoryDeletedEvent struct {
@@ -2,34 +2,79 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"strings"
 )
 
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrVersionConflict = errors.New("product was modified concurrently")
+	ErrInvalidBatch    = errors.New("invalid product batch")
+	ErrInvalidProduct  = errors.New("invalid product")
+)
+
+// Product is a catalog item. Version starts at 1 and is incremented by every
+// update, so writers can detect that somebody else changed the product since
+// they read it.
 type Product struct {
-	ID       string  `bson:"_id,omitempty"`
-	Name     string  `bson:"name"`
-	Price    float64 `bson:"price"`
-	Category string  `bson:"category"`
-	Stock    int     `bson:"stock"`
+	ID       string `bson:"_id,omitempty"`
+	Name     string `bson:"name"`
+	Price    Money  `bson:"price"`
+	Category string `bson:"category"`
+	Stock    int    `bson:"stock"`
+	Version  int64  `bson:"version"`
+}
+
+// ProductUpdate is a partial update of a product. Only non-nil fields are
+// changed, and it is only applied while the stored product is still at
+// Version. A zero Version only matches products stored before versioning.
+type ProductUpdate struct {
+	ID       string
+	Version  int64
+	Name     *string
+	Price    *Money
+	Category *string
+	Stock    *int
 }
 
 type ProductRepository interface {
 	Create(ctx context.Context, p *Product) error
 	GetByID(ctx context.Context, id string) (*Product, error)
-	Update(ctx context.Context, p *Product) error
+	Update(ctx context.Context, upd *ProductUpdate) (*Product, error)
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]*Product, error)
+	ListByIDs(ctx context.Context, ids []string) ([]*Product, error)
+	ListByCategories(ctx context.Context, categoryIDs []string) ([]*Product, error)
 }
 
 type ProductUsecase interface {
 	Create(ctx context.Context, p *Product) error
 	GetByID(ctx context.Context, id string) (*Product, error)
-	Update(ctx context.Context, p *Product) error
+	BatchGet(ctx context.Context, ids []string) (found []*Product, notFound []string, err error)
+	Update(ctx context.Context, upd *ProductUpdate) (*Product, error)
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]*Product, error)
+	ListByCategories(ctx context.Context, categoryIDs []string) ([]*Product, error)
 	RefreshProductsCache(ctx context.Context) error
+	ApplyCacheInvalidation(ctx context.Context, msg ProductCacheInvalidation) error
 }
 
 func (p *Product) NormalizeName() {
-	p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+	p.Name = normalizeProductName(p.Name)
+}
+
+// IsEmpty reports whether the update does not change any field.
+func (u *ProductUpdate) IsEmpty() bool {
+	return u.Name == nil && u.Price == nil && u.Category == nil && u.Stock == nil
+}
+
+func (u *ProductUpdate) NormalizeName() {
+	if u.Name != nil {
+		name := normalizeProductName(*u.Name)
+		u.Name = &name
+	}
+}
+
+func normalizeProductName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
 }
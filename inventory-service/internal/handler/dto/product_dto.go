@@ -1,16 +1,20 @@
 package dto
 
+import "github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+
 // go-playground/validator
 type CreateProductRequest struct {
-	Name     string  `json:"name" binding:"required"`
-	Price    float64 `json:"price" binding:"gt=0"`
-	Category string  `json:"category" binding:"required"`
-	Stock    int     `json:"stock" binding:"min=0"`
+	Name     string       `json:"name" binding:"required"`
+	Price    domain.Money `json:"price" binding:"required"`
+	Category string       `json:"category" binding:"required"`
+	Stock    int          `json:"stock" binding:"min=0"`
 }
 
+// UpdateProductRequest only changes the fields that are present.
 type UpdateProductRequest struct {
-	Name     *string  `json:"name,omitempty"`
-	Price    *float64 `json:"price,omitempty"`
-	Category *string  `json:"category,omitempty"`
-	Stock    *int     `json:"stock,omitempty"`
+	Version  int64         `json:"version"`
+	Name     *string       `json:"name,omitempty"`
+	Price    *domain.Money `json:"price,omitempty"`
+	Category *string       `json:"category,omitempty"`
+	Stock    *int          `json:"stock,omitempty"`
 }
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"log"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
@@ -68,22 +69,25 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	current, err := h.usecase.GetByID(c.Request.Context(), id)
-	if err != nil {
-		utils.Log.Error("product not found", "id", id, "err", err)
-		c.JSON(404, gin.H{"error": "product not found"})
-		return
-	}
-
-	product := &domain.Product{
+	upd := &domain.ProductUpdate{
 		ID:       id,
-		Name:     utils.DefaultIfNilString(req.Name, current.Name),
-		Price:    utils.DefaultIfNilFloat(req.Price, current.Price),
-		Category: utils.DefaultIfNilString(req.Category, current.Category),
-		Stock:    utils.DefaultIfNilInt(req.Stock, current.Stock),
+		Version:  req.Version,
+		Name:     req.Name,
+		Price:    req.Price,
+		Category: req.Category,
+		Stock:    req.Stock,
 	}
 
-	if err := h.usecase.Update(c.Request.Context(), product); err != nil {
+	product, err := h.usecase.Update(c.Request.Context(), upd)
+	if errors.Is(err, domain.ErrVersionConflict) {
+		c.JSON(409, gin.H{"error": "product was modified, reload and retry"})
+		return
+	}
+	if errors.Is(err, domain.ErrProductNotFound) {
+		c.JSON(404, gin.H{"error": "product not found"})
+		return
+	}
+	if err != nil {
 		utils.Log.Error("Failed to update product", "id", id, "err", err)
 		c.JSON(500, gin.H{"error": "failed to update product"})
 		return
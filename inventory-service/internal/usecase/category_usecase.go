@@ -3,19 +3,30 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
 )
 
 type categoryUsecase struct {
-	categoryRepo domain.CategoryRepository
-	publisher    domain.InventoryEventPublisher
+	categoryRepo  domain.CategoryRepository
+	publisher     domain.InventoryEventPublisher
+	tx            domain.Transactor
+	inMemoryCache domain.CategoryMemoryCache
+	redisCache    domain.CategoryRedisCache
+	invalidator   domain.CacheInvalidationPublisher
 }
 
-func NewCategoryUsecase(repo domain.CategoryRepository, p domain.InventoryEventPublisher) domain.CategoryUsecase {
+// NewCategoryUsecase wires the category usecase. p must write to the outbox:
+// events are published in the same transaction as the change.
+func NewCategoryUsecase(repo domain.CategoryRepository, p domain.InventoryEventPublisher, tx domain.Transactor, inmemory domain.CategoryMemoryCache, redis domain.CategoryRedisCache, invalidator domain.CacheInvalidationPublisher) domain.CategoryUsecase {
 	return &categoryUsecase{
-		categoryRepo: repo,
-		publisher:    p,
+		categoryRepo:  repo,
+		publisher:     p,
+		tx:            tx,
+		inMemoryCache: inmemory,
+		redisCache:    redis,
+		invalidator:   invalidator,
 	}
 }
 
@@ -29,14 +40,78 @@ func (u *categoryUsecase) Create(ctx context.Context, c *domain.Category) error
 
 	c.NormalizeName()
 
-	return u.categoryRepo.Create(ctx, c)
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		c.Ancestors = []string{}
+		if c.ParentID != "" {
+			parent, err := u.categoryRepo.GetByID(ctx, c.ParentID)
+			if err != nil {
+				return fmt.Errorf("categoryRepo.GetByID: %w", err)
+			}
+			if parent == nil {
+				return fmt.Errorf("parent %w", domain.ErrCategoryNotFound)
+			}
+			// the parent cannot be moved or deleted until this commits
+			if err := u.categoryRepo.LockParent(ctx, parent.ID, ""); err != nil {
+				return fmt.Errorf("categoryRepo.LockParent: %w", err)
+			}
+			c.Ancestors = parent.Path()
+		}
+
+		if err := u.categoryRepo.Create(ctx, c); err != nil {
+			return err
+		}
+
+		event := domain.CategoryCreatedEvent{
+			ID:       c.ID,
+			Name:     c.Name,
+			ParentID: c.ParentID,
+		}
+		if err := u.publisher.PublishCategoryCreated(ctx, event); err != nil {
+			return fmt.Errorf("publisher.PublishCategoryCreated: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		c.ID = ""
+		return err
+	}
+
+	u.cacheCategory(ctx, c)
+	u.broadcastInvalidation(ctx, c.ID)
+
+	return nil
 }
 
 func (u *categoryUsecase) GetByID(ctx context.Context, id string) (*domain.Category, error) {
 	if id == "" {
 		return nil, errors.New("category ID cannot be empty")
 	}
-	return u.categoryRepo.GetByID(ctx, id)
+
+	// try inmemory
+	if category, ok := u.inMemoryCache.Get(id); ok {
+		return category, nil
+	}
+
+	// try Redis
+	category, err := u.redisCache.Get(ctx, id)
+	if err == nil && category != nil {
+		u.inMemoryCache.Warm(category) // warm inmemory
+		return category, nil
+	}
+
+	// DB
+	category, err = u.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("categoryRepo.GetByID: %w", err)
+	}
+	if category == nil {
+		return nil, nil // not found
+	}
+
+	u.inMemoryCache.Warm(category)
+	_ = u.redisCache.Set(ctx, category)
+
+	return category, nil
 }
 
 func (u *categoryUsecase) Update(ctx context.Context, c *domain.Category) error {
@@ -52,16 +127,311 @@ func (u *categoryUsecase) Update(ctx context.Context, c *domain.Category) error
 
 	c.NormalizeName()
 
-	return u.categoryRepo.Update(ctx, c)
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.categoryRepo.Update(ctx, c); err != nil {
+			return err
+		}
+
+		// reload to keep the tree fields of the cached entry
+		updated, err := u.categoryRepo.GetByID(ctx, c.ID)
+		if err != nil {
+			return fmt.Errorf("categoryRepo.GetByID: %w", err)
+		}
+		if updated == nil {
+			return domain.ErrCategoryNotFound
+		}
+		*c = *updated
+
+		event := domain.CategoryUpdatedEvent{
+			ID:       c.ID,
+			Name:     c.Name,
+			ParentID: c.ParentID,
+		}
+		if err := u.publisher.PublishCategoryUpdated(ctx, event); err != nil {
+			return fmt.Errorf("publisher.PublishCategoryUpdated: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	u.cacheCategory(ctx, c)
+	u.broadcastInvalidation(ctx, c.ID)
+
+	return nil
 }
 
 func (u *categoryUsecase) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return errors.New("category ID cannot be empty")
 	}
-	return u.categoryRepo.Delete(ctx, id)
+
+	// children added meanwhile lock the category, which makes the delete
+	// conflict with them
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		children, err := u.categoryRepo.ListChildren(ctx, id)
+		if err != nil {
+			return fmt.Errorf("categoryRepo.ListChildren: %w", err)
+		}
+		if len(children) > 0 {
+			return domain.ErrCategoryHasChildren
+		}
+
+		if err := u.categoryRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		if err := u.publisher.PublishCategoryDeleted(ctx, domain.CategoryDeletedEvent{ID: id}); err != nil {
+			return fmt.Errorf("publisher.PublishCategoryDeleted: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// invalidate caches
+	u.inMemoryCache.Delete(id)
+	_ = u.redisCache.Delete(ctx, id)
+	_ = u.redisCache.DeleteList(ctx)
+	u.broadcastInvalidation(ctx, id)
+
+	return nil
 }
 
 func (u *categoryUsecase) List(ctx context.Context) ([]*domain.Category, error) {
-	return u.categoryRepo.List(ctx)
+	// inmemory cache
+	if categories, ok := u.inMemoryCache.GetList(); ok {
+		return categories, nil
+	}
+
+	// redis cache
+	categories, err := u.redisCache.GetList(ctx)
+	if err == nil && categories != nil {
+		u.inMemoryCache.SetList(categories) // warm memory
+		return categories, nil
+	}
+
+	// mongoDB
+	categories, err = u.categoryRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("categoryRepo.List: %w", err)
+	}
+
+	// update cache
+	u.inMemoryCache.SetList(categories)
+	_ = u.redisCache.SetList(ctx, categories)
+
+	return categories, nil
+}
+
+func (u *categoryUsecase) RefreshCategoriesCache(ctx context.Context) error {
+	categories, err := u.categoryRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("categoryRepo.List: %w", err)
+	}
+
+	u.inMemoryCache.SetList(categories)
+
+	if err := u.redisCache.SetList(ctx, categories); err != nil {
+		return fmt.Errorf("redisCache.SetList: %w", err)
+	}
+	if err := u.redisCache.SetMany(ctx, categories); err != nil {
+		return fmt.Errorf("redisCache.SetMany: %w", err)
+	}
+
+	return nil
+}
+
+// ListChildren returns the direct children of parentID, or the root
+// categories when parentID is empty.
+func (u *categoryUsecase) ListChildren(ctx context.Context, parentID string) ([]*domain.Category, error) {
+	return u.categoryRepo.ListChildren(ctx, parentID)
+}
+
+func (u *categoryUsecase) GetSubtree(ctx context.Context, id string) (*domain.CategoryNode, error) {
+	root, err := u.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := u.categoryRepo.ListDescendants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("categoryRepo.ListDescendants: %w", err)
+	}
+
+	nodes := map[string]*domain.CategoryNode{root.ID: {Category: root}}
+	for _, c := range descendants {
+		nodes[c.ID] = &domain.CategoryNode{Category: c}
+	}
+	for _, c := range descendants {
+		if parent, ok := nodes[c.ParentID]; ok {
+			parent.Children = append(parent.Children, nodes[c.ID])
+		}
+	}
+
+	return nodes[root.ID], nil
+}
+
+// GetBreadcrumbs returns the path from the root category down to id.
+func (u *categoryUsecase) GetBreadcrumbs(ctx context.Context, id string) ([]*domain.Category, error) {
+	c, err := u.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Ancestors) == 0 {
+		return []*domain.Category{c}, nil
+	}
+
+	ancestors, err := u.categoryRepo.ListByIDs(ctx, c.Ancestors)
+	if err != nil {
+		return nil, fmt.Errorf("categoryRepo.ListByIDs: %w", err)
+	}
+
+	byID := make(map[string]*domain.Category, len(ancestors))
+	for _, a := range ancestors {
+		byID[a.ID] = a
+	}
+
+	crumbs := make([]*domain.Category, 0, len(c.Ancestors)+1)
+	for _, aid := range c.Ancestors {
+		if a, ok := byID[aid]; ok {
+			crumbs = append(crumbs, a)
+		}
+	}
+
+	return append(crumbs, c), nil
+}
+
+// Move re-parents category id under newParentID, or makes it a root category
+// when newParentID is empty. Moving a category below itself or one of its
+// descendants is rejected with domain.ErrCategoryCycle.
+func (u *categoryUsecase) Move(ctx context.Context, id, newParentID string) (*domain.Category, error) {
+	if id == "" {
+		return nil, errors.New("category ID cannot be empty")
+	}
+
+	// The tree is read in the transaction and the writes are conditional on
+	// it, so concurrent moves cannot make a cycle together.
+	var c *domain.Category
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		c, err = u.categoryRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("categoryRepo.GetByID: %w", err)
+		}
+		if c == nil {
+			return domain.ErrCategoryNotFound
+		}
+		oldParentID := c.ParentID
+
+		c.Ancestors = []string{}
+		if newParentID != "" {
+			parent, err := u.categoryRepo.GetByID(ctx, newParentID)
+			if err != nil {
+				return fmt.Errorf("categoryRepo.GetByID: %w", err)
+			}
+			if parent == nil {
+				return fmt.Errorf("parent %w", domain.ErrCategoryNotFound)
+			}
+			if c.IsAncestorOf(parent) {
+				return domain.ErrCategoryCycle
+			}
+			if err := u.categoryRepo.LockParent(ctx, parent.ID, c.ID); err != nil {
+				return fmt.Errorf("categoryRepo.LockParent: %w", err)
+			}
+			c.Ancestors = parent.Path()
+		}
+		c.ParentID = newParentID
+
+		if err := u.categoryRepo.Move(ctx, c, oldParentID); err != nil {
+			return fmt.Errorf("categoryRepo.Move: %w", err)
+		}
+
+		event := domain.CategoryUpdatedEvent{
+			ID:       c.ID,
+			Name:     c.Name,
+			ParentID: c.ParentID,
+		}
+		if err := u.publisher.PublishCategoryUpdated(ctx, event); err != nil {
+			return fmt.Errorf("publisher.PublishCategoryUpdated: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// every descendant got new ancestors as well
+	descendants, err := u.categoryRepo.ListDescendants(ctx, c.ID)
+	if err != nil {
+		return nil, fmt.Errorf("categoryRepo.ListDescendants: %w", err)
+	}
+	u.cacheCategory(ctx, c)
+	u.inMemoryCache.SetMany(descendants)
+	_ = u.redisCache.SetMany(ctx, descendants)
+
+	ids := []string{c.ID}
+	for _, d := range descendants {
+		ids = append(ids, d.ID)
+	}
+	u.broadcastInvalidation(ctx, ids...)
+
+	return c, nil
+}
+
+// SubtreeIDs returns id followed by the IDs of all of its descendants.
+func (u *categoryUsecase) SubtreeIDs(ctx context.Context, id string) ([]string, error) {
+	descendants, err := u.categoryRepo.ListDescendants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("categoryRepo.ListDescendants: %w", err)
+	}
+
+	ids := make([]string, 0, len(descendants)+1)
+	ids = append(ids, id)
+	for _, c := range descendants {
+		ids = append(ids, c.ID)
+	}
+
+	return ids, nil
+}
+
+func (u *categoryUsecase) get(ctx context.Context, id string) (*domain.Category, error) {
+	c, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+
+	return c, nil
+}
+
+// cacheCategory stores c in both caches and drops the cached lists, which no
+// longer match the database.
+func (u *categoryUsecase) cacheCategory(ctx context.Context, c *domain.Category) {
+	u.inMemoryCache.Set(c)
+	_ = u.redisCache.Set(ctx, c)
+	_ = u.redisCache.DeleteList(ctx)
+}
+
+// ApplyCacheInvalidation evicts categories another replica wrote from the
+// in-memory cache, together with the cached list.
+func (u *categoryUsecase) ApplyCacheInvalidation(ctx context.Context, msg domain.CategoryCacheInvalidation) error {
+	if len(msg.CategoryIDs) == 0 {
+		return errors.New("cache invalidation without category IDs")
+	}
+
+	for _, id := range msg.CategoryIDs {
+		u.inMemoryCache.Delete(id)
+	}
+	return nil
+}
+
+// broadcastInvalidation tells the other replicas to drop their in-memory
+// copies of categories. Best effort like the product ones.
+func (u *categoryUsecase) broadcastInvalidation(ctx context.Context, ids ...string) {
+	_ = u.invalidator.PublishCategoryInvalidation(ctx, domain.CategoryCacheInvalidation{CategoryIDs: ids})
 }
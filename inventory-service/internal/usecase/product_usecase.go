@@ -4,23 +4,48 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	"github.com/Neroframe/ecommerce-platform/inventory-service/internal/domain"
+	"golang.org/x/sync/singleflight"
 )
 
+// maxBatchGetProducts bounds BatchGet so a single call cannot pull the whole
+// catalog through the caches.
+const maxBatchGetProducts = 100
+
+// loadTimeout bounds a coalesced cache fill. Loads are detached from the
+// request that started them because other requests wait for the same result.
+const loadTimeout = 10 * time.Second
+
+const productListLoadKey = "product:list"
+
 type productUsecase struct {
 	productRepo   domain.ProductRepository
 	publisher     domain.InventoryEventPublisher
+	tx            domain.Transactor
 	inMemoryCache domain.ProductMemoryCache
 	redisCache    domain.ProductRedisCache
+	invalidator   domain.CacheInvalidationPublisher
+
+	// loads coalesces concurrent cache fills from Redis and MongoDB
+	loads singleflight.Group
+	// refreshing holds the keys of stale entries being revalidated
+	refreshing sync.Map
 }
 
-func NewProductUsecase(repo domain.ProductRepository, pub domain.InventoryEventPublisher, inmemory domain.ProductMemoryCache, redis domain.ProductRedisCache) domain.ProductUsecase {
+// NewProductUsecase wires the product usecase. pub must write to the outbox:
+// events are published in the same transaction as the change.
+func NewProductUsecase(repo domain.ProductRepository, pub domain.InventoryEventPublisher, tx domain.Transactor, inmemory domain.ProductMemoryCache, redis domain.ProductRedisCache, invalidator domain.CacheInvalidationPublisher) domain.ProductUsecase {
 	return &productUsecase{
 		productRepo:   repo,
 		publisher:     pub,
+		tx:            tx,
 		inMemoryCache: inmemory,
 		redisCache:    redis,
+		invalidator:   invalidator,
 	}
 }
 
@@ -31,98 +56,259 @@ func (u *productUsecase) Create(ctx context.Context, p *domain.Product) error {
 	if len(p.Name) > 36 {
 		return errors.New("product name cannot exceed 36 characters")
 	}
-	if p.Price <= 0 {
+	if p.Price.Amount <= 0 {
 		return errors.New("price must be greater than zero")
 	}
+	if err := p.Price.Validate(); err != nil {
+		return err
+	}
 	if p.Stock < 0 {
 		return errors.New("stock value cannot be negative")
 	}
 
 	p.NormalizeName()
-
-	// save to DB
-	err := u.productRepo.Create(ctx, p)
+	p.Version = 1
+
+	// save to DB together with the event
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.productRepo.Create(ctx, p); err != nil {
+			return err
+		}
+
+		event := domain.ProductCreatedEvent{
+			ID:         p.ID,
+			Name:       p.Name,
+			Price:      p.Price,
+			CategoryID: p.Category,
+			Stock:      p.Stock,
+		}
+		if err := u.publisher.PublishProductCreated(ctx, event); err != nil {
+			return fmt.Errorf("publisher.PublishProductCreated: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
+		p.ID = ""
 		return err
 	}
 
-	// set inmemory cache
+	// caches
 	u.inMemoryCache.Set(p)
-
-	// set redis cache
-	if err := u.redisCache.Set(ctx, p); err != nil {
-		return fmt.Errorf("redisCache.Set: %w", err)
-	}
-
-	// NATS publish
-	event := domain.ProductCreatedEvent{
-		ID:         p.ID,
-		Name:       p.Name,
-		Price:      int(p.Price),
-		CategoryID: p.Category,
-	}
-	if err := u.publisher.PublishProductCreated(ctx, event); err != nil {
-		return fmt.Errorf("publisher.PublishProductCreated: %w", err)
-	}
+	_ = u.redisCache.Set(ctx, p)
+	_ = u.redisCache.InvalidateList(ctx)
+	u.broadcastInvalidation(ctx, p.ID, p.Version, domain.CacheOpUpsert)
 
 	return nil
 }
 
 func (u *productUsecase) List(ctx context.Context) ([]*domain.Product, error) {
 	// inmemory cache
-	if products, ok := u.inMemoryCache.GetList(); ok {
+	if products, stale, ok := u.inMemoryCache.GetList(); ok {
+		if stale {
+			u.revalidate(ctx, productListLoadKey, func(ctx context.Context) error {
+				_, err := u.loadList(ctx)
+				return err
+			})
+		}
 		return products, nil
 	}
 
-	// redis cache
-	products, err := u.redisCache.GetList(ctx)
-	if err == nil && products != nil {
-		u.inMemoryCache.SetMany(products) // warm memory
+	return u.loadList(ctx)
+}
+
+// loadList reads the catalog from Redis, or from MongoDB when Redis misses,
+// and caches it. Concurrent calls share a single load.
+func (u *productUsecase) loadList(ctx context.Context) ([]*domain.Product, error) {
+	v, err, _ := u.loads.Do(productListLoadKey, func() (any, error) {
+		ctx, cancel := loadContext(ctx)
+		defer cancel()
+
+		// redis cache
+		products, generation, err := u.redisCache.GetList(ctx)
+		if err == nil && products != nil {
+			u.inMemoryCache.SetList(products) // warm memory
+			return products, nil
+		}
+
+		// mongoDB
+		products, err = u.productRepo.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("productRepo.GetAll: %w", err)
+		}
+
+		// update cache
+		u.inMemoryCache.SetList(products)
+		_ = u.redisCache.SetList(ctx, generation, products)
+
 		return products, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// mongoDB
-	products, err = u.productRepo.List(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("productRepo.GetAll: %w", err)
+	return v.([]*domain.Product), nil
+}
+
+func (u *productUsecase) ListByCategories(ctx context.Context, categoryIDs []string) ([]*domain.Product, error) {
+	if len(categoryIDs) == 0 {
+		return nil, errors.New("at least one category ID is required")
 	}
 
-	// update cache
-	u.inMemoryCache.SetMany(products)
-	_ = u.redisCache.SetList(ctx, products)
+	products, err := u.productRepo.ListByCategories(ctx, categoryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("productRepo.ListByCategories: %w", err)
+	}
 
 	return products, nil
 }
 
 func (u *productUsecase) GetByID(ctx context.Context, id string) (*domain.Product, error) {
 	// try inmemory
-	if product, ok := u.inMemoryCache.Get(id); ok {
+	if product, stale, ok := u.inMemoryCache.Get(id); ok {
+		if stale {
+			u.revalidate(ctx, productLoadKey(id), func(ctx context.Context) error {
+				_, err := u.loadProduct(ctx, id)
+				return err
+			})
+		}
 		return product, nil
 	}
 
-	// try Redis
-	product, err := u.redisCache.Get(ctx, id)
-	if err == nil && product != nil {
-		u.inMemoryCache.Set(product) // warm inmemory
+	return u.loadProduct(ctx, id)
+}
+
+// loadProduct reads a product from Redis, or from MongoDB when Redis misses,
+// and caches it. Concurrent calls for the same product share a single load.
+// It returns nil if the product does not exist.
+func (u *productUsecase) loadProduct(ctx context.Context, id string) (*domain.Product, error) {
+	v, err, _ := u.loads.Do(productLoadKey(id), func() (any, error) {
+		ctx, cancel := loadContext(ctx)
+		defer cancel()
+
+		// try Redis
+		product, err := u.redisCache.Get(ctx, id)
+		if err == nil && product != nil {
+			u.inMemoryCache.Set(product) // warm inmemory
+			return product, nil
+		}
+
+		//  DB
+		product, err = u.productRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("productRepo.GetByID: %w", err)
+		}
+		if product == nil {
+			return nil, nil // not found
+		}
+
+		u.inMemoryCache.Set(product)
+		_ = u.redisCache.Set(ctx, product)
+
 		return product, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	//  DB
-	product, err = u.productRepo.GetByID(ctx, id)
-	if err != nil {
-		return nil, fmt.Errorf("productRepo.GetByID: %w", err)
+	product, _ := v.(*domain.Product)
+	return product, nil
+}
+
+// revalidate refreshes a stale cache entry in the background. Only one
+// refresh per key runs at a time, so a hot key served stale by many requests
+// starts a single goroutine; its load is still shared with any request
+// missing the same entry.
+func (u *productUsecase) revalidate(ctx context.Context, key string, load func(ctx context.Context) error) {
+	if _, running := u.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
 	}
-	if product == nil {
-		return nil, nil // not found
+
+	go func() {
+		defer u.refreshing.Delete(key)
+
+		if err := load(ctx); err != nil {
+			log.Printf("[Cache] revalidate %s failed: %v", key, err)
+		}
+	}()
+}
+
+// BatchGet looks up several products at once. Each tier is asked only for
+// the IDs the previous one missed: the in-memory cache, Redis with a single
+// MGET and finally MongoDB with a single $in query. Products are returned in
+// the order of ids; IDs that do not exist are returned in notFound.
+func (u *productUsecase) BatchGet(ctx context.Context, ids []string) ([]*domain.Product, []string, error) {
+	if len(ids) == 0 {
+		return nil, nil, fmt.Errorf("%w: at least one product ID is required", domain.ErrInvalidBatch)
+	}
+	if len(ids) > maxBatchGetProducts {
+		return nil, nil, fmt.Errorf("%w: cannot get more than %d products at once", domain.ErrInvalidBatch, maxBatchGetProducts)
+	}
+
+	// drop duplicates, keep order
+	seen := make(map[string]struct{}, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			unique = append(unique, id)
+		}
 	}
 
-	u.inMemoryCache.Set(product)
-	_ = u.redisCache.Set(ctx, product)
+	byID := make(map[string]*domain.Product, len(unique))
 
-	return product, nil
+	// inmemory
+	found, missing := u.inMemoryCache.GetMany(unique)
+	for _, p := range found {
+		byID[p.ID] = p
+	}
+
+	// redis
+	if len(missing) > 0 {
+		cached, err := u.redisCache.GetMany(ctx, missing)
+		if err == nil && len(cached) > 0 {
+			u.inMemoryCache.SetMany(cached) // warm inmemory
+			for _, p := range cached {
+				byID[p.ID] = p
+			}
+			missing = missingIDs(missing, byID)
+		}
+	}
+
+	// DB
+	if len(missing) > 0 {
+		products, err := u.productRepo.ListByIDs(ctx, missing)
+		if err != nil {
+			return nil, nil, fmt.Errorf("productRepo.ListByIDs: %w", err)
+		}
+		if len(products) > 0 {
+			u.inMemoryCache.SetMany(products)
+			_ = u.redisCache.SetMany(ctx, products)
+		}
+		for _, p := range products {
+			byID[p.ID] = p
+		}
+	}
+
+	products := make([]*domain.Product, 0, len(byID))
+	var notFound []string
+	for _, id := range unique {
+		if p, ok := byID[id]; ok {
+			products = append(products, p)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+
+	return products, notFound, nil
 }
 
 func (u *productUsecase) RefreshProductsCache(ctx context.Context) error {
+	// read the generation first, a write during the load retires this list
+	generation, err := u.redisCache.ListGeneration(ctx)
+	if err != nil {
+		return fmt.Errorf("redisCache.ListGeneration: %w", err)
+	}
+
 	// load all products from DB
 	products, err := u.productRepo.List(ctx)
 	if err != nil {
@@ -130,56 +316,80 @@ func (u *productUsecase) RefreshProductsCache(ctx context.Context) error {
 	}
 
 	// refresh inmemory cache
-	u.inMemoryCache.SetMany(products)
+	u.inMemoryCache.SetList(products)
 
 	// refresh Redis cache
-	if err := u.redisCache.SetList(ctx, products); err != nil {
+	if err := u.redisCache.SetList(ctx, generation, products); err != nil {
 		return fmt.Errorf("redisCache.SetList: %w", err)
 	}
 
 	return nil
 }
 
-func (u *productUsecase) Update(ctx context.Context, p *domain.Product) error {
-	if p.ID == "" {
-		return errors.New("product ID cannot be empty")
+// Update applies upd to the product and returns the updated product. It fails
+// with domain.ErrVersionConflict when the product changed since upd.Version
+// was read and with domain.ErrInvalidProduct when upd is not valid.
+func (u *productUsecase) Update(ctx context.Context, upd *domain.ProductUpdate) (*domain.Product, error) {
+	if upd.ID == "" {
+		return nil, fmt.Errorf("%w: product ID cannot be empty", domain.ErrInvalidProduct)
 	}
-
-	if p.Name == "" {
-		return errors.New("product name cannot be empty")
+	if upd.IsEmpty() {
+		return nil, fmt.Errorf("%w: no product fields to update", domain.ErrInvalidProduct)
 	}
-	if len(p.Name) > 36 {
-		return errors.New("product name cannot exceed 36 characters")
+
+	if upd.Name != nil {
+		if *upd.Name == "" {
+			return nil, fmt.Errorf("%w: product name cannot be empty", domain.ErrInvalidProduct)
+		}
+		if len(*upd.Name) > 36 {
+			return nil, fmt.Errorf("%w: product name cannot exceed 36 characters", domain.ErrInvalidProduct)
+		}
 	}
-	if p.Price <= 0 {
-		return errors.New("price must be greater than zero")
+	if upd.Price != nil {
+		if upd.Price.Amount <= 0 {
+			return nil, fmt.Errorf("%w: price must be greater than zero", domain.ErrInvalidProduct)
+		}
+		if err := upd.Price.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInvalidProduct, err)
+		}
 	}
-	if p.Stock < 0 {
-		return errors.New("stock value cannot be negative")
+	if upd.Stock != nil && *upd.Stock < 0 {
+		return nil, fmt.Errorf("%w: stock value cannot be negative", domain.ErrInvalidProduct)
 	}
 
-	p.NormalizeName()
-
-	if err := u.productRepo.Update(ctx, p); err != nil {
-		return err
+	upd.NormalizeName()
+
+	var p *domain.Product
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if p, err = u.productRepo.Update(ctx, upd); err != nil {
+			return err
+		}
+
+		evt := domain.ProductUpdatedEvent{
+			ID:         p.ID,
+			Name:       p.Name,
+			Price:      p.Price,
+			CategoryID: p.Category,
+			Version:    p.Version,
+			Stock:      p.Stock,
+		}
+		if err := u.publisher.PublishProductUpdated(ctx, evt); err != nil {
+			return fmt.Errorf("publisher.PublishProductUpdated: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// caches
 	u.inMemoryCache.Set(p)
 	_ = u.redisCache.Set(ctx, p)
+	_ = u.redisCache.InvalidateList(ctx)
+	u.broadcastInvalidation(ctx, p.ID, p.Version, domain.CacheOpUpsert)
 
-	// publish
-	evt := domain.ProductUpdatedEvent{
-		ID:         p.ID,
-		Name:       p.Name,
-		Price:      int(p.Price),
-		CategoryID: p.Category,
-	}
-	if err := u.publisher.PublishProductUpdated(ctx, evt); err != nil {
-		return fmt.Errorf("publisher.PublishProductUpdated: %w", err)
-	}
-
-	return nil
+	return p, nil
 }
 
 func (u *productUsecase) Delete(ctx context.Context, id string) error {
@@ -187,21 +397,65 @@ func (u *productUsecase) Delete(ctx context.Context, id string) error {
 		return errors.New("product ID cannot be empty")
 	}
 
-	if err := u.productRepo.Delete(ctx, id); err != nil {
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.productRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		if err := u.publisher.PublishProductDeleted(ctx, domain.ProductDeletedEvent{ID: id}); err != nil {
+			return fmt.Errorf("publisher.PublishProductDeleted: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
 	// invalidate caches
 	u.inMemoryCache.Delete(id)
-	err := u.redisCache.Delete(ctx, id)
-	if err != nil {
-		return fmt.Errorf("redisCache.Delete error: %w", err)
-	}
+	_ = u.redisCache.Delete(ctx, id)
+	_ = u.redisCache.InvalidateList(ctx)
+	u.broadcastInvalidation(ctx, id, 0, domain.CacheOpDelete)
 
-	// publish
-	if err := u.publisher.PublishProductDeleted(ctx, domain.ProductDeletedEvent{ID: id}); err != nil {
-		return fmt.Errorf("publisher.PublishProductDeleted: %w", err)
+	return nil
+}
+
+// ApplyCacheInvalidation evicts a product another replica wrote from the
+// in-memory cache.
+func (u *productUsecase) ApplyCacheInvalidation(ctx context.Context, msg domain.ProductCacheInvalidation) error {
+	if msg.ProductID == "" {
+		return errors.New("cache invalidation without product ID")
 	}
 
+	u.inMemoryCache.Invalidate(msg.ProductID, msg.Version, msg.Op == domain.CacheOpDelete)
 	return nil
 }
+
+// broadcastInvalidation tells the other replicas to drop their in-memory copy
+// of a product. Like the cache writes it is best effort: a replica that misses
+// the message catches up on the next periodic refresh.
+func (u *productUsecase) broadcastInvalidation(ctx context.Context, id string, version int64, op string) {
+	_ = u.invalidator.PublishProductInvalidation(ctx, domain.ProductCacheInvalidation{
+		ProductID: id,
+		Version:   version,
+		Op:        op,
+	})
+}
+
+func missingIDs(ids []string, found map[string]*domain.Product) []string {
+	var missing []string
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+func productLoadKey(id string) string {
+	return "product:" + id
+}
+
+func loadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+}
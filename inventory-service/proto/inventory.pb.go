@@ -10,6 +10,7 @@ import (
 	empty "github.com/golang/protobuf/ptypes/empty"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,19 +23,81 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Money mirrors google.type.Money: units is the whole part of the amount and
+// nanos the fractional part in billionths, both with the same sign.
+type Money struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CurrencyCode  string                 `protobuf:"bytes,1,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"` // ISO 4217, e.g. "USD"
+	Units         int64                  `protobuf:"varint,2,opt,name=units,proto3" json:"units,omitempty"`
+	Nanos         int32                  `protobuf:"varint,3,opt,name=nanos,proto3" json:"nanos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_proto_inventory_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Money) GetCurrencyCode() string {
+	if x != nil {
+		return x.CurrencyCode
+	}
+	return ""
+}
+
+func (x *Money) GetUnits() int64 {
+	if x != nil {
+		return x.Units
+	}
+	return 0
+}
+
+func (x *Money) GetNanos() int32 {
+	if x != nil {
+		return x.Nanos
+	}
+	return 0
+}
+
 type CreateProductRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Price         float64                `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
 	Category      string                 `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
 	Stock         int32                  `protobuf:"varint,4,opt,name=stock,proto3" json:"stock,omitempty"`
+	Price         *Money                 `protobuf:"bytes,5,opt,name=price,proto3" json:"price,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateProductRequest) Reset() {
 	*x = CreateProductRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[0]
+	mi := &file_proto_inventory_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -46,7 +109,7 @@ func (x *CreateProductRequest) String() string {
 func (*CreateProductRequest) ProtoMessage() {}
 
 func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[0]
+	mi := &file_proto_inventory_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -59,7 +122,7 @@ func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateProductRequest.ProtoReflect.Descriptor instead.
 func (*CreateProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{0}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *CreateProductRequest) GetName() string {
@@ -69,13 +132,6 @@ func (x *CreateProductRequest) GetName() string {
 	return ""
 }
 
-func (x *CreateProductRequest) GetPrice() float64 {
-	if x != nil {
-		return x.Price
-	}
-	return 0
-}
-
 func (x *CreateProductRequest) GetCategory() string {
 	if x != nil {
 		return x.Category
@@ -90,20 +146,34 @@ func (x *CreateProductRequest) GetStock() int32 {
 	return 0
 }
 
+func (x *CreateProductRequest) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
 type UpdateProductRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
-	Category      string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
-	Stock         int32                  `protobuf:"varint,5,opt,name=stock,proto3" json:"stock,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name     string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Category string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
+	Stock    int32                  `protobuf:"varint,5,opt,name=stock,proto3" json:"stock,omitempty"`
+	Price    *Money                 `protobuf:"bytes,6,opt,name=price,proto3" json:"price,omitempty"`
+	// Fields to change, e.g. ["stock"]. When empty every non-zero field is
+	// applied.
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,7,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	// Version of the product the change is based on. The update is rejected
+	// with ABORTED when the product has been modified since. When 0 or unset
+	// only a product stored before versioning, which has no version, matches.
+	Version       int64 `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateProductRequest) Reset() {
 	*x = UpdateProductRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[1]
+	mi := &file_proto_inventory_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -115,7 +185,7 @@ func (x *UpdateProductRequest) String() string {
 func (*UpdateProductRequest) ProtoMessage() {}
 
 func (x *UpdateProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[1]
+	mi := &file_proto_inventory_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -128,7 +198,7 @@ func (x *UpdateProductRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateProductRequest.ProtoReflect.Descriptor instead.
 func (*UpdateProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{1}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *UpdateProductRequest) GetId() string {
@@ -145,13 +215,6 @@ func (x *UpdateProductRequest) GetName() string {
 	return ""
 }
 
-func (x *UpdateProductRequest) GetPrice() float64 {
-	if x != nil {
-		return x.Price
-	}
-	return 0
-}
-
 func (x *UpdateProductRequest) GetCategory() string {
 	if x != nil {
 		return x.Category
@@ -166,6 +229,27 @@ func (x *UpdateProductRequest) GetStock() int32 {
 	return 0
 }
 
+func (x *UpdateProductRequest) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *UpdateProductRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateProductRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type GetProductRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -175,7 +259,7 @@ type GetProductRequest struct {
 
 func (x *GetProductRequest) Reset() {
 	*x = GetProductRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[2]
+	mi := &file_proto_inventory_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -187,7 +271,7 @@ func (x *GetProductRequest) String() string {
 func (*GetProductRequest) ProtoMessage() {}
 
 func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[2]
+	mi := &file_proto_inventory_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -200,7 +284,7 @@ func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
 func (*GetProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{2}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *GetProductRequest) GetId() string {
@@ -219,7 +303,7 @@ type DeleteProductRequest struct {
 
 func (x *DeleteProductRequest) Reset() {
 	*x = DeleteProductRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[3]
+	mi := &file_proto_inventory_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -231,7 +315,7 @@ func (x *DeleteProductRequest) String() string {
 func (*DeleteProductRequest) ProtoMessage() {}
 
 func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[3]
+	mi := &file_proto_inventory_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -244,7 +328,7 @@ func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
 func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{3}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *DeleteProductRequest) GetId() string {
@@ -258,16 +342,17 @@ type ProductResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
 	Category      string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
 	Stock         int32                  `protobuf:"varint,5,opt,name=stock,proto3" json:"stock,omitempty"`
+	Price         *Money                 `protobuf:"bytes,6,opt,name=price,proto3" json:"price,omitempty"`
+	Version       int64                  `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ProductResponse) Reset() {
 	*x = ProductResponse{}
-	mi := &file_proto_inventory_proto_msgTypes[4]
+	mi := &file_proto_inventory_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -279,7 +364,7 @@ func (x *ProductResponse) String() string {
 func (*ProductResponse) ProtoMessage() {}
 
 func (x *ProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[4]
+	mi := &file_proto_inventory_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -292,7 +377,7 @@ func (x *ProductResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProductResponse.ProtoReflect.Descriptor instead.
 func (*ProductResponse) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{4}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ProductResponse) GetId() string {
@@ -309,13 +394,6 @@ func (x *ProductResponse) GetName() string {
 	return ""
 }
 
-func (x *ProductResponse) GetPrice() float64 {
-	if x != nil {
-		return x.Price
-	}
-	return 0
-}
-
 func (x *ProductResponse) GetCategory() string {
 	if x != nil {
 		return x.Category
@@ -330,15 +408,31 @@ func (x *ProductResponse) GetStock() int32 {
 	return 0
 }
 
+func (x *ProductResponse) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *ProductResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type ListProductsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Category           string                 `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`                                                // optional category ID filter
+	IncludeDescendants bool                   `protobuf:"varint,2,opt,name=include_descendants,json=includeDescendants,proto3" json:"include_descendants,omitempty"` // also match products of nested categories
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *ListProductsRequest) Reset() {
 	*x = ListProductsRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[5]
+	mi := &file_proto_inventory_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -350,7 +444,7 @@ func (x *ListProductsRequest) String() string {
 func (*ListProductsRequest) ProtoMessage() {}
 
 func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[5]
+	mi := &file_proto_inventory_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -363,7 +457,21 @@ func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
 func (*ListProductsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{5}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListProductsRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ListProductsRequest) GetIncludeDescendants() bool {
+	if x != nil {
+		return x.IncludeDescendants
+	}
+	return false
 }
 
 type ListProductsResponse struct {
@@ -375,7 +483,7 @@ type ListProductsResponse struct {
 
 func (x *ListProductsResponse) Reset() {
 	*x = ListProductsResponse{}
-	mi := &file_proto_inventory_proto_msgTypes[6]
+	mi := &file_proto_inventory_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -387,7 +495,7 @@ func (x *ListProductsResponse) String() string {
 func (*ListProductsResponse) ProtoMessage() {}
 
 func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[6]
+	mi := &file_proto_inventory_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -400,7 +508,7 @@ func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
 func (*ListProductsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{6}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ListProductsResponse) GetProducts() []*ProductResponse {
@@ -410,16 +518,113 @@ func (x *ListProductsResponse) GetProducts() []*ProductResponse {
 	return nil
 }
 
+type BatchGetProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetProductsRequest) Reset() {
+	*x = BatchGetProductsRequest{}
+	mi := &file_proto_inventory_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetProductsRequest) ProtoMessage() {}
+
+func (x *BatchGetProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetProductsRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BatchGetProductsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type BatchGetProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*ProductResponse     `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"` // in the order of the requested ids
+	NotFoundIds   []string               `protobuf:"bytes,2,rep,name=not_found_ids,json=notFoundIds,proto3" json:"not_found_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetProductsResponse) Reset() {
+	*x = BatchGetProductsResponse{}
+	mi := &file_proto_inventory_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetProductsResponse) ProtoMessage() {}
+
+func (x *BatchGetProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetProductsResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BatchGetProductsResponse) GetProducts() []*ProductResponse {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+func (x *BatchGetProductsResponse) GetNotFoundIds() []string {
+	if x != nil {
+		return x.NotFoundIds
+	}
+	return nil
+}
+
 type CreateCategoryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	ParentId      string                 `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // empty for a root category
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateCategoryRequest) Reset() {
 	*x = CreateCategoryRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[7]
+	mi := &file_proto_inventory_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -431,7 +636,7 @@ func (x *CreateCategoryRequest) String() string {
 func (*CreateCategoryRequest) ProtoMessage() {}
 
 func (x *CreateCategoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[7]
+	mi := &file_proto_inventory_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -444,7 +649,7 @@ func (x *CreateCategoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateCategoryRequest.ProtoReflect.Descriptor instead.
 func (*CreateCategoryRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{7}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *CreateCategoryRequest) GetName() string {
@@ -454,6 +659,13 @@ func (x *CreateCategoryRequest) GetName() string {
 	return ""
 }
 
+func (x *CreateCategoryRequest) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
 type UpdateCategoryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -464,7 +676,7 @@ type UpdateCategoryRequest struct {
 
 func (x *UpdateCategoryRequest) Reset() {
 	*x = UpdateCategoryRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[8]
+	mi := &file_proto_inventory_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -476,7 +688,7 @@ func (x *UpdateCategoryRequest) String() string {
 func (*UpdateCategoryRequest) ProtoMessage() {}
 
 func (x *UpdateCategoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[8]
+	mi := &file_proto_inventory_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -489,7 +701,7 @@ func (x *UpdateCategoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateCategoryRequest.ProtoReflect.Descriptor instead.
 func (*UpdateCategoryRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{8}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *UpdateCategoryRequest) GetId() string {
@@ -515,7 +727,7 @@ type GetCategoryRequest struct {
 
 func (x *GetCategoryRequest) Reset() {
 	*x = GetCategoryRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[9]
+	mi := &file_proto_inventory_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -527,7 +739,7 @@ func (x *GetCategoryRequest) String() string {
 func (*GetCategoryRequest) ProtoMessage() {}
 
 func (x *GetCategoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[9]
+	mi := &file_proto_inventory_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -540,7 +752,7 @@ func (x *GetCategoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetCategoryRequest.ProtoReflect.Descriptor instead.
 func (*GetCategoryRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{9}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *GetCategoryRequest) GetId() string {
@@ -559,7 +771,7 @@ type DeleteCategoryRequest struct {
 
 func (x *DeleteCategoryRequest) Reset() {
 	*x = DeleteCategoryRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[10]
+	mi := &file_proto_inventory_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -571,7 +783,7 @@ func (x *DeleteCategoryRequest) String() string {
 func (*DeleteCategoryRequest) ProtoMessage() {}
 
 func (x *DeleteCategoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[10]
+	mi := &file_proto_inventory_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -584,7 +796,7 @@ func (x *DeleteCategoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteCategoryRequest.ProtoReflect.Descriptor instead.
 func (*DeleteCategoryRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{10}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *DeleteCategoryRequest) GetId() string {
@@ -598,13 +810,15 @@ type CategoryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ParentId      string                 `protobuf:"bytes,3,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Ancestors     []string               `protobuf:"bytes,4,rep,name=ancestors,proto3" json:"ancestors,omitempty"` // root first, direct parent last
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CategoryResponse) Reset() {
 	*x = CategoryResponse{}
-	mi := &file_proto_inventory_proto_msgTypes[11]
+	mi := &file_proto_inventory_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -616,7 +830,7 @@ func (x *CategoryResponse) String() string {
 func (*CategoryResponse) ProtoMessage() {}
 
 func (x *CategoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[11]
+	mi := &file_proto_inventory_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -629,7 +843,7 @@ func (x *CategoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CategoryResponse.ProtoReflect.Descriptor instead.
 func (*CategoryResponse) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{11}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *CategoryResponse) GetId() string {
@@ -646,6 +860,20 @@ func (x *CategoryResponse) GetName() string {
 	return ""
 }
 
+func (x *CategoryResponse) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *CategoryResponse) GetAncestors() []string {
+	if x != nil {
+		return x.Ancestors
+	}
+	return nil
+}
+
 type ListCategoriesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -654,7 +882,7 @@ type ListCategoriesRequest struct {
 
 func (x *ListCategoriesRequest) Reset() {
 	*x = ListCategoriesRequest{}
-	mi := &file_proto_inventory_proto_msgTypes[12]
+	mi := &file_proto_inventory_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -666,7 +894,7 @@ func (x *ListCategoriesRequest) String() string {
 func (*ListCategoriesRequest) ProtoMessage() {}
 
 func (x *ListCategoriesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[12]
+	mi := &file_proto_inventory_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -679,7 +907,7 @@ func (x *ListCategoriesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListCategoriesRequest.ProtoReflect.Descriptor instead.
 func (*ListCategoriesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{12}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{15}
 }
 
 type ListCategoriesResponse struct {
@@ -691,7 +919,7 @@ type ListCategoriesResponse struct {
 
 func (x *ListCategoriesResponse) Reset() {
 	*x = ListCategoriesResponse{}
-	mi := &file_proto_inventory_proto_msgTypes[13]
+	mi := &file_proto_inventory_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -703,7 +931,7 @@ func (x *ListCategoriesResponse) String() string {
 func (*ListCategoriesResponse) ProtoMessage() {}
 
 func (x *ListCategoriesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_inventory_proto_msgTypes[13]
+	mi := &file_proto_inventory_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -716,7 +944,7 @@ func (x *ListCategoriesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListCategoriesResponse.ProtoReflect.Descriptor instead.
 func (*ListCategoriesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_inventory_proto_rawDescGZIP(), []int{13}
+	return file_proto_inventory_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ListCategoriesResponse) GetCategories() []*CategoryResponse {
@@ -726,63 +954,334 @@ func (x *ListCategoriesResponse) GetCategories() []*CategoryResponse {
 	return nil
 }
 
+type ListChildCategoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ParentId      string                 `protobuf:"bytes,1,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // empty lists the root categories
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChildCategoriesRequest) Reset() {
+	*x = ListChildCategoriesRequest{}
+	mi := &file_proto_inventory_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChildCategoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChildCategoriesRequest) ProtoMessage() {}
+
+func (x *ListChildCategoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChildCategoriesRequest.ProtoReflect.Descriptor instead.
+func (*ListChildCategoriesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListChildCategoriesRequest) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+type GetCategorySubtreeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCategorySubtreeRequest) Reset() {
+	*x = GetCategorySubtreeRequest{}
+	mi := &file_proto_inventory_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCategorySubtreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCategorySubtreeRequest) ProtoMessage() {}
+
+func (x *GetCategorySubtreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCategorySubtreeRequest.ProtoReflect.Descriptor instead.
+func (*GetCategorySubtreeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetCategorySubtreeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CategoryTreeNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      *CategoryResponse      `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	Children      []*CategoryTreeNode    `protobuf:"bytes,2,rep,name=children,proto3" json:"children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CategoryTreeNode) Reset() {
+	*x = CategoryTreeNode{}
+	mi := &file_proto_inventory_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CategoryTreeNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CategoryTreeNode) ProtoMessage() {}
+
+func (x *CategoryTreeNode) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CategoryTreeNode.ProtoReflect.Descriptor instead.
+func (*CategoryTreeNode) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CategoryTreeNode) GetCategory() *CategoryResponse {
+	if x != nil {
+		return x.Category
+	}
+	return nil
+}
+
+func (x *CategoryTreeNode) GetChildren() []*CategoryTreeNode {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+type GetCategoryBreadcrumbsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCategoryBreadcrumbsRequest) Reset() {
+	*x = GetCategoryBreadcrumbsRequest{}
+	mi := &file_proto_inventory_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCategoryBreadcrumbsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCategoryBreadcrumbsRequest) ProtoMessage() {}
+
+func (x *GetCategoryBreadcrumbsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCategoryBreadcrumbsRequest.ProtoReflect.Descriptor instead.
+func (*GetCategoryBreadcrumbsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetCategoryBreadcrumbsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type MoveCategoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	NewParentId   string                 `protobuf:"bytes,2,opt,name=new_parent_id,json=newParentId,proto3" json:"new_parent_id,omitempty"` // empty moves the category to the root
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveCategoryRequest) Reset() {
+	*x = MoveCategoryRequest{}
+	mi := &file_proto_inventory_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveCategoryRequest) ProtoMessage() {}
+
+func (x *MoveCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_inventory_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveCategoryRequest.ProtoReflect.Descriptor instead.
+func (*MoveCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_inventory_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *MoveCategoryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *MoveCategoryRequest) GetNewParentId() string {
+	if x != nil {
+		return x.NewParentId
+	}
+	return ""
+}
+
 var File_proto_inventory_proto protoreflect.FileDescriptor
 
 const file_proto_inventory_proto_rawDesc = "" +
 	"\n" +
-	"\x15proto/inventory.proto\x12\tinventory\x1a\x1bgoogle/protobuf/empty.proto\"r\n" +
+	"\x15proto/inventory.proto\x12\tinventory\x1a\x1bgoogle/protobuf/empty.proto\x1a google/protobuf/field_mask.proto\"X\n" +
+	"\x05Money\x12#\n" +
+	"\rcurrency_code\x18\x01 \x01(\tR\fcurrencyCode\x12\x14\n" +
+	"\x05units\x18\x02 \x01(\x03R\x05units\x12\x14\n" +
+	"\x05nanos\x18\x03 \x01(\x05R\x05nanos\"\x8a\x01\n" +
 	"\x14CreateProductRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
-	"\x05price\x18\x02 \x01(\x01R\x05price\x12\x1a\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
 	"\bcategory\x18\x03 \x01(\tR\bcategory\x12\x14\n" +
-	"\x05stock\x18\x04 \x01(\x05R\x05stock\"\x82\x01\n" +
+	"\x05stock\x18\x04 \x01(\x05R\x05stock\x12&\n" +
+	"\x05price\x18\x05 \x01(\v2\x10.inventory.MoneyR\x05priceJ\x04\b\x02\x10\x03\"\xf1\x01\n" +
 	"\x14UpdateProductRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x1a\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
 	"\bcategory\x18\x04 \x01(\tR\bcategory\x12\x14\n" +
-	"\x05stock\x18\x05 \x01(\x05R\x05stock\"#\n" +
+	"\x05stock\x18\x05 \x01(\x05R\x05stock\x12&\n" +
+	"\x05price\x18\x06 \x01(\v2\x10.inventory.MoneyR\x05price\x12;\n" +
+	"\vupdate_mask\x18\a \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12\x18\n" +
+	"\aversion\x18\b \x01(\x03R\aversionJ\x04\b\x03\x10\x04\"#\n" +
 	"\x11GetProductRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"&\n" +
 	"\x14DeleteProductRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"}\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xaf\x01\n" +
 	"\x0fProductResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x1a\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
 	"\bcategory\x18\x04 \x01(\tR\bcategory\x12\x14\n" +
-	"\x05stock\x18\x05 \x01(\x05R\x05stock\"\x15\n" +
-	"\x13ListProductsRequest\"N\n" +
+	"\x05stock\x18\x05 \x01(\x05R\x05stock\x12&\n" +
+	"\x05price\x18\x06 \x01(\v2\x10.inventory.MoneyR\x05price\x12\x18\n" +
+	"\aversion\x18\a \x01(\x03R\aversionJ\x04\b\x03\x10\x04\"b\n" +
+	"\x13ListProductsRequest\x12\x1a\n" +
+	"\bcategory\x18\x01 \x01(\tR\bcategory\x12/\n" +
+	"\x13include_descendants\x18\x02 \x01(\bR\x12includeDescendants\"N\n" +
 	"\x14ListProductsResponse\x126\n" +
 	"\bproducts\x18\x01 \x03(\v2\x1a.inventory.ProductResponseR\bproducts\"+\n" +
+	"\x17BatchGetProductsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"v\n" +
+	"\x18BatchGetProductsResponse\x126\n" +
+	"\bproducts\x18\x01 \x03(\v2\x1a.inventory.ProductResponseR\bproducts\x12\"\n" +
+	"\rnot_found_ids\x18\x02 \x03(\tR\vnotFoundIds\"H\n" +
 	"\x15CreateCategoryRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\";\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\tparent_id\x18\x02 \x01(\tR\bparentId\";\n" +
 	"\x15UpdateCategoryRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\"$\n" +
 	"\x12GetCategoryRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"'\n" +
 	"\x15DeleteCategoryRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"6\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"q\n" +
 	"\x10CategoryResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\"\x17\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
+	"\tparent_id\x18\x03 \x01(\tR\bparentId\x12\x1c\n" +
+	"\tancestors\x18\x04 \x03(\tR\tancestors\"\x17\n" +
 	"\x15ListCategoriesRequest\"U\n" +
 	"\x16ListCategoriesResponse\x12;\n" +
 	"\n" +
 	"categories\x18\x01 \x03(\v2\x1b.inventory.CategoryResponseR\n" +
-	"categories2\xa9\x06\n" +
+	"categories\"9\n" +
+	"\x1aListChildCategoriesRequest\x12\x1b\n" +
+	"\tparent_id\x18\x01 \x01(\tR\bparentId\"+\n" +
+	"\x19GetCategorySubtreeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x84\x01\n" +
+	"\x10CategoryTreeNode\x127\n" +
+	"\bcategory\x18\x01 \x01(\v2\x1b.inventory.CategoryResponseR\bcategory\x127\n" +
+	"\bchildren\x18\x02 \x03(\v2\x1b.inventory.CategoryTreeNodeR\bchildren\"/\n" +
+	"\x1dGetCategoryBreadcrumbsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"I\n" +
+	"\x13MoveCategoryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\"\n" +
+	"\rnew_parent_id\x18\x02 \x01(\tR\vnewParentId2\xf4\t\n" +
 	"\x10InventoryService\x12L\n" +
 	"\rCreateProduct\x12\x1f.inventory.CreateProductRequest\x1a\x1a.inventory.ProductResponse\x12J\n" +
 	"\x0eGetProductByID\x12\x1c.inventory.GetProductRequest\x1a\x1a.inventory.ProductResponse\x12L\n" +
 	"\rUpdateProduct\x12\x1f.inventory.UpdateProductRequest\x1a\x1a.inventory.ProductResponse\x12H\n" +
 	"\rDeleteProduct\x12\x1f.inventory.DeleteProductRequest\x1a\x16.google.protobuf.Empty\x12O\n" +
-	"\fListProducts\x12\x1e.inventory.ListProductsRequest\x1a\x1f.inventory.ListProductsResponse\x12O\n" +
+	"\fListProducts\x12\x1e.inventory.ListProductsRequest\x1a\x1f.inventory.ListProductsResponse\x12[\n" +
+	"\x10BatchGetProducts\x12\".inventory.BatchGetProductsRequest\x1a#.inventory.BatchGetProductsResponse\x12O\n" +
 	"\x0eCreateCategory\x12 .inventory.CreateCategoryRequest\x1a\x1b.inventory.CategoryResponse\x12M\n" +
 	"\x0fGetCategoryByID\x12\x1d.inventory.GetCategoryRequest\x1a\x1b.inventory.CategoryResponse\x12O\n" +
 	"\x0eUpdateCategory\x12 .inventory.UpdateCategoryRequest\x1a\x1b.inventory.CategoryResponse\x12J\n" +
 	"\x0eDeleteCategory\x12 .inventory.DeleteCategoryRequest\x1a\x16.google.protobuf.Empty\x12U\n" +
-	"\x0eListCategories\x12 .inventory.ListCategoriesRequest\x1a!.inventory.ListCategoriesResponseBMZKgithub.com/Neroframe/ecommerce-platform/inventory-service/proto;inventorypbb\x06proto3"
+	"\x0eListCategories\x12 .inventory.ListCategoriesRequest\x1a!.inventory.ListCategoriesResponse\x12_\n" +
+	"\x13ListChildCategories\x12%.inventory.ListChildCategoriesRequest\x1a!.inventory.ListCategoriesResponse\x12W\n" +
+	"\x12GetCategorySubtree\x12$.inventory.GetCategorySubtreeRequest\x1a\x1b.inventory.CategoryTreeNode\x12e\n" +
+	"\x16GetCategoryBreadcrumbs\x12(.inventory.GetCategoryBreadcrumbsRequest\x1a!.inventory.ListCategoriesResponse\x12K\n" +
+	"\fMoveCategory\x12\x1e.inventory.MoveCategoryRequest\x1a\x1b.inventory.CategoryResponseBMZKgithub.com/Neroframe/ecommerce-platform/inventory-service/proto;inventorypbb\x06proto3"
 
 var (
 	file_proto_inventory_proto_rawDescOnce sync.Once
@@ -796,52 +1295,78 @@ func file_proto_inventory_proto_rawDescGZIP() []byte {
 	return file_proto_inventory_proto_rawDescData
 }
 
-var file_proto_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_proto_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_proto_inventory_proto_goTypes = []any{
-	(*CreateProductRequest)(nil),   // 0: inventory.CreateProductRequest
-	(*UpdateProductRequest)(nil),   // 1: inventory.UpdateProductRequest
-	(*GetProductRequest)(nil),      // 2: inventory.GetProductRequest
-	(*DeleteProductRequest)(nil),   // 3: inventory.DeleteProductRequest
-	(*ProductResponse)(nil),        // 4: inventory.ProductResponse
-	(*ListProductsRequest)(nil),    // 5: inventory.ListProductsRequest
-	(*ListProductsResponse)(nil),   // 6: inventory.ListProductsResponse
-	(*CreateCategoryRequest)(nil),  // 7: inventory.CreateCategoryRequest
-	(*UpdateCategoryRequest)(nil),  // 8: inventory.UpdateCategoryRequest
-	(*GetCategoryRequest)(nil),     // 9: inventory.GetCategoryRequest
-	(*DeleteCategoryRequest)(nil),  // 10: inventory.DeleteCategoryRequest
-	(*CategoryResponse)(nil),       // 11: inventory.CategoryResponse
-	(*ListCategoriesRequest)(nil),  // 12: inventory.ListCategoriesRequest
-	(*ListCategoriesResponse)(nil), // 13: inventory.ListCategoriesResponse
-	(*empty.Empty)(nil),            // 14: google.protobuf.Empty
+	(*Money)(nil),                         // 0: inventory.Money
+	(*CreateProductRequest)(nil),          // 1: inventory.CreateProductRequest
+	(*UpdateProductRequest)(nil),          // 2: inventory.UpdateProductRequest
+	(*GetProductRequest)(nil),             // 3: inventory.GetProductRequest
+	(*DeleteProductRequest)(nil),          // 4: inventory.DeleteProductRequest
+	(*ProductResponse)(nil),               // 5: inventory.ProductResponse
+	(*ListProductsRequest)(nil),           // 6: inventory.ListProductsRequest
+	(*ListProductsResponse)(nil),          // 7: inventory.ListProductsResponse
+	(*BatchGetProductsRequest)(nil),       // 8: inventory.BatchGetProductsRequest
+	(*BatchGetProductsResponse)(nil),      // 9: inventory.BatchGetProductsResponse
+	(*CreateCategoryRequest)(nil),         // 10: inventory.CreateCategoryRequest
+	(*UpdateCategoryRequest)(nil),         // 11: inventory.UpdateCategoryRequest
+	(*GetCategoryRequest)(nil),            // 12: inventory.GetCategoryRequest
+	(*DeleteCategoryRequest)(nil),         // 13: inventory.DeleteCategoryRequest
+	(*CategoryResponse)(nil),              // 14: inventory.CategoryResponse
+	(*ListCategoriesRequest)(nil),         // 15: inventory.ListCategoriesRequest
+	(*ListCategoriesResponse)(nil),        // 16: inventory.ListCategoriesResponse
+	(*ListChildCategoriesRequest)(nil),    // 17: inventory.ListChildCategoriesRequest
+	(*GetCategorySubtreeRequest)(nil),     // 18: inventory.GetCategorySubtreeRequest
+	(*CategoryTreeNode)(nil),              // 19: inventory.CategoryTreeNode
+	(*GetCategoryBreadcrumbsRequest)(nil), // 20: inventory.GetCategoryBreadcrumbsRequest
+	(*MoveCategoryRequest)(nil),           // 21: inventory.MoveCategoryRequest
+	(*fieldmaskpb.FieldMask)(nil),         // 22: google.protobuf.FieldMask
+	(*empty.Empty)(nil),                   // 23: google.protobuf.Empty
 }
 var file_proto_inventory_proto_depIdxs = []int32{
-	4,  // 0: inventory.ListProductsResponse.products:type_name -> inventory.ProductResponse
-	11, // 1: inventory.ListCategoriesResponse.categories:type_name -> inventory.CategoryResponse
-	0,  // 2: inventory.InventoryService.CreateProduct:input_type -> inventory.CreateProductRequest
-	2,  // 3: inventory.InventoryService.GetProductByID:input_type -> inventory.GetProductRequest
-	1,  // 4: inventory.InventoryService.UpdateProduct:input_type -> inventory.UpdateProductRequest
-	3,  // 5: inventory.InventoryService.DeleteProduct:input_type -> inventory.DeleteProductRequest
-	5,  // 6: inventory.InventoryService.ListProducts:input_type -> inventory.ListProductsRequest
-	7,  // 7: inventory.InventoryService.CreateCategory:input_type -> inventory.CreateCategoryRequest
-	9,  // 8: inventory.InventoryService.GetCategoryByID:input_type -> inventory.GetCategoryRequest
-	8,  // 9: inventory.InventoryService.UpdateCategory:input_type -> inventory.UpdateCategoryRequest
-	10, // 10: inventory.InventoryService.DeleteCategory:input_type -> inventory.DeleteCategoryRequest
-	12, // 11: inventory.InventoryService.ListCategories:input_type -> inventory.ListCategoriesRequest
-	4,  // 12: inventory.InventoryService.CreateProduct:output_type -> inventory.ProductResponse
-	4,  // 13: inventory.InventoryService.GetProductByID:output_type -> inventory.ProductResponse
-	4,  // 14: inventory.InventoryService.UpdateProduct:output_type -> inventory.ProductResponse
-	14, // 15: inventory.InventoryService.DeleteProduct:output_type -> google.protobuf.Empty
-	6,  // 16: inventory.InventoryService.ListProducts:output_type -> inventory.ListProductsResponse
-	11, // 17: inventory.InventoryService.CreateCategory:output_type -> inventory.CategoryResponse
-	11, // 18: inventory.InventoryService.GetCategoryByID:output_type -> inventory.CategoryResponse
-	11, // 19: inventory.InventoryService.UpdateCategory:output_type -> inventory.CategoryResponse
-	14, // 20: inventory.InventoryService.DeleteCategory:output_type -> google.protobuf.Empty
-	13, // 21: inventory.InventoryService.ListCategories:output_type -> inventory.ListCategoriesResponse
-	12, // [12:22] is the sub-list for method output_type
-	2,  // [2:12] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	0,  // 0: inventory.CreateProductRequest.price:type_name -> inventory.Money
+	0,  // 1: inventory.UpdateProductRequest.price:type_name -> inventory.Money
+	22, // 2: inventory.UpdateProductRequest.update_mask:type_name -> google.protobuf.FieldMask
+	0,  // 3: inventory.ProductResponse.price:type_name -> inventory.Money
+	5,  // 4: inventory.ListProductsResponse.products:type_name -> inventory.ProductResponse
+	5,  // 5: inventory.BatchGetProductsResponse.products:type_name -> inventory.ProductResponse
+	14, // 6: inventory.ListCategoriesResponse.categories:type_name -> inventory.CategoryResponse
+	14, // 7: inventory.CategoryTreeNode.category:type_name -> inventory.CategoryResponse
+	19, // 8: inventory.CategoryTreeNode.children:type_name -> inventory.CategoryTreeNode
+	1,  // 9: inventory.InventoryService.CreateProduct:input_type -> inventory.CreateProductRequest
+	3,  // 10: inventory.InventoryService.GetProductByID:input_type -> inventory.GetProductRequest
+	2,  // 11: inventory.InventoryService.UpdateProduct:input_type -> inventory.UpdateProductRequest
+	4,  // 12: inventory.InventoryService.DeleteProduct:input_type -> inventory.DeleteProductRequest
+	6,  // 13: inventory.InventoryService.ListProducts:input_type -> inventory.ListProductsRequest
+	8,  // 14: inventory.InventoryService.BatchGetProducts:input_type -> inventory.BatchGetProductsRequest
+	10, // 15: inventory.InventoryService.CreateCategory:input_type -> inventory.CreateCategoryRequest
+	12, // 16: inventory.InventoryService.GetCategoryByID:input_type -> inventory.GetCategoryRequest
+	11, // 17: inventory.InventoryService.UpdateCategory:input_type -> inventory.UpdateCategoryRequest
+	13, // 18: inventory.InventoryService.DeleteCategory:input_type -> inventory.DeleteCategoryRequest
+	15, // 19: inventory.InventoryService.ListCategories:input_type -> inventory.ListCategoriesRequest
+	17, // 20: inventory.InventoryService.ListChildCategories:input_type -> inventory.ListChildCategoriesRequest
+	18, // 21: inventory.InventoryService.GetCategorySubtree:input_type -> inventory.GetCategorySubtreeRequest
+	20, // 22: inventory.InventoryService.GetCategoryBreadcrumbs:input_type -> inventory.GetCategoryBreadcrumbsRequest
+	21, // 23: inventory.InventoryService.MoveCategory:input_type -> inventory.MoveCategoryRequest
+	5,  // 24: inventory.InventoryService.CreateProduct:output_type -> inventory.ProductResponse
+	5,  // 25: inventory.InventoryService.GetProductByID:output_type -> inventory.ProductResponse
+	5,  // 26: inventory.InventoryService.UpdateProduct:output_type -> inventory.ProductResponse
+	23, // 27: inventory.InventoryService.DeleteProduct:output_type -> google.protobuf.Empty
+	7,  // 28: inventory.InventoryService.ListProducts:output_type -> inventory.ListProductsResponse
+	9,  // 29: inventory.InventoryService.BatchGetProducts:output_type -> inventory.BatchGetProductsResponse
+	14, // 30: inventory.InventoryService.CreateCategory:output_type -> inventory.CategoryResponse
+	14, // 31: inventory.InventoryService.GetCategoryByID:output_type -> inventory.CategoryResponse
+	14, // 32: inventory.InventoryService.UpdateCategory:output_type -> inventory.CategoryResponse
+	23, // 33: inventory.InventoryService.DeleteCategory:output_type -> google.protobuf.Empty
+	16, // 34: inventory.InventoryService.ListCategories:output_type -> inventory.ListCategoriesResponse
+	16, // 35: inventory.InventoryService.ListChildCategories:output_type -> inventory.ListCategoriesResponse
+	19, // 36: inventory.InventoryService.GetCategorySubtree:output_type -> inventory.CategoryTreeNode
+	16, // 37: inventory.InventoryService.GetCategoryBreadcrumbs:output_type -> inventory.ListCategoriesResponse
+	14, // 38: inventory.InventoryService.MoveCategory:output_type -> inventory.CategoryResponse
+	24, // [24:39] is the sub-list for method output_type
+	9,  // [9:24] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_inventory_proto_init() }
@@ -855,7 +1380,7 @@ func file_proto_inventory_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_inventory_proto_rawDesc), len(file_proto_inventory_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   14,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
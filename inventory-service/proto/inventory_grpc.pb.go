@@ -20,16 +20,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	InventoryService_CreateProduct_FullMethodName   = "/inventory.InventoryService/CreateProduct"
-	InventoryService_GetProductByID_FullMethodName  = "/inventory.InventoryService/GetProductByID"
-	InventoryService_UpdateProduct_FullMethodName   = "/inventory.InventoryService/UpdateProduct"
-	InventoryService_DeleteProduct_FullMethodName   = "/inventory.InventoryService/DeleteProduct"
-	InventoryService_ListProducts_FullMethodName    = "/inventory.InventoryService/ListProducts"
-	InventoryService_CreateCategory_FullMethodName  = "/inventory.InventoryService/CreateCategory"
-	InventoryService_GetCategoryByID_FullMethodName = "/inventory.InventoryService/GetCategoryByID"
-	InventoryService_UpdateCategory_FullMethodName  = "/inventory.InventoryService/UpdateCategory"
-	InventoryService_DeleteCategory_FullMethodName  = "/inventory.InventoryService/DeleteCategory"
-	InventoryService_ListCategories_FullMethodName  = "/inventory.InventoryService/ListCategories"
+	InventoryService_CreateProduct_FullMethodName          = "/inventory.InventoryService/CreateProduct"
+	InventoryService_GetProductByID_FullMethodName         = "/inventory.InventoryService/GetProductByID"
+	InventoryService_UpdateProduct_FullMethodName          = "/inventory.InventoryService/UpdateProduct"
+	InventoryService_DeleteProduct_FullMethodName          = "/inventory.InventoryService/DeleteProduct"
+	InventoryService_ListProducts_FullMethodName           = "/inventory.InventoryService/ListProducts"
+	InventoryService_BatchGetProducts_FullMethodName       = "/inventory.InventoryService/BatchGetProducts"
+	InventoryService_CreateCategory_FullMethodName         = "/inventory.InventoryService/CreateCategory"
+	InventoryService_GetCategoryByID_FullMethodName        = "/inventory.InventoryService/GetCategoryByID"
+	InventoryService_UpdateCategory_FullMethodName         = "/inventory.InventoryService/UpdateCategory"
+	InventoryService_DeleteCategory_FullMethodName         = "/inventory.InventoryService/DeleteCategory"
+	InventoryService_ListCategories_FullMethodName         = "/inventory.InventoryService/ListCategories"
+	InventoryService_ListChildCategories_FullMethodName    = "/inventory.InventoryService/ListChildCategories"
+	InventoryService_GetCategorySubtree_FullMethodName     = "/inventory.InventoryService/GetCategorySubtree"
+	InventoryService_GetCategoryBreadcrumbs_FullMethodName = "/inventory.InventoryService/GetCategoryBreadcrumbs"
+	InventoryService_MoveCategory_FullMethodName           = "/inventory.InventoryService/MoveCategory"
 )
 
 // InventoryServiceClient is the client API for InventoryService service.
@@ -42,12 +47,18 @@ type InventoryServiceClient interface {
 	UpdateProduct(ctx context.Context, in *UpdateProductRequest, opts ...grpc.CallOption) (*ProductResponse, error)
 	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
+	BatchGetProducts(ctx context.Context, in *BatchGetProductsRequest, opts ...grpc.CallOption) (*BatchGetProductsResponse, error)
 	// Categories
 	CreateCategory(ctx context.Context, in *CreateCategoryRequest, opts ...grpc.CallOption) (*CategoryResponse, error)
 	GetCategoryByID(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*CategoryResponse, error)
 	UpdateCategory(ctx context.Context, in *UpdateCategoryRequest, opts ...grpc.CallOption) (*CategoryResponse, error)
 	DeleteCategory(ctx context.Context, in *DeleteCategoryRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	ListCategories(ctx context.Context, in *ListCategoriesRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error)
+	// Category tree
+	ListChildCategories(ctx context.Context, in *ListChildCategoriesRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error)
+	GetCategorySubtree(ctx context.Context, in *GetCategorySubtreeRequest, opts ...grpc.CallOption) (*CategoryTreeNode, error)
+	GetCategoryBreadcrumbs(ctx context.Context, in *GetCategoryBreadcrumbsRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error)
+	MoveCategory(ctx context.Context, in *MoveCategoryRequest, opts ...grpc.CallOption) (*CategoryResponse, error)
 }
 
 type inventoryServiceClient struct {
@@ -108,6 +119,16 @@ func (c *inventoryServiceClient) ListProducts(ctx context.Context, in *ListProdu
 	return out, nil
 }
 
+func (c *inventoryServiceClient) BatchGetProducts(ctx context.Context, in *BatchGetProductsRequest, opts ...grpc.CallOption) (*BatchGetProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetProductsResponse)
+	err := c.cc.Invoke(ctx, InventoryService_BatchGetProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *inventoryServiceClient) CreateCategory(ctx context.Context, in *CreateCategoryRequest, opts ...grpc.CallOption) (*CategoryResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CategoryResponse)
@@ -158,6 +179,46 @@ func (c *inventoryServiceClient) ListCategories(ctx context.Context, in *ListCat
 	return out, nil
 }
 
+func (c *inventoryServiceClient) ListChildCategories(ctx context.Context, in *ListChildCategoriesRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCategoriesResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ListChildCategories_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetCategorySubtree(ctx context.Context, in *GetCategorySubtreeRequest, opts ...grpc.CallOption) (*CategoryTreeNode, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CategoryTreeNode)
+	err := c.cc.Invoke(ctx, InventoryService_GetCategorySubtree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetCategoryBreadcrumbs(ctx context.Context, in *GetCategoryBreadcrumbsRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCategoriesResponse)
+	err := c.cc.Invoke(ctx, InventoryService_GetCategoryBreadcrumbs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) MoveCategory(ctx context.Context, in *MoveCategoryRequest, opts ...grpc.CallOption) (*CategoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CategoryResponse)
+	err := c.cc.Invoke(ctx, InventoryService_MoveCategory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // InventoryServiceServer is the server API for InventoryService service.
 // All implementations must embed UnimplementedInventoryServiceServer
 // for forward compatibility.
@@ -168,12 +229,18 @@ type InventoryServiceServer interface {
 	UpdateProduct(context.Context, *UpdateProductRequest) (*ProductResponse, error)
 	DeleteProduct(context.Context, *DeleteProductRequest) (*empty.Empty, error)
 	ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error)
+	BatchGetProducts(context.Context, *BatchGetProductsRequest) (*BatchGetProductsResponse, error)
 	// Categories
 	CreateCategory(context.Context, *CreateCategoryRequest) (*CategoryResponse, error)
 	GetCategoryByID(context.Context, *GetCategoryRequest) (*CategoryResponse, error)
 	UpdateCategory(context.Context, *UpdateCategoryRequest) (*CategoryResponse, error)
 	DeleteCategory(context.Context, *DeleteCategoryRequest) (*empty.Empty, error)
 	ListCategories(context.Context, *ListCategoriesRequest) (*ListCategoriesResponse, error)
+	// Category tree
+	ListChildCategories(context.Context, *ListChildCategoriesRequest) (*ListCategoriesResponse, error)
+	GetCategorySubtree(context.Context, *GetCategorySubtreeRequest) (*CategoryTreeNode, error)
+	GetCategoryBreadcrumbs(context.Context, *GetCategoryBreadcrumbsRequest) (*ListCategoriesResponse, error)
+	MoveCategory(context.Context, *MoveCategoryRequest) (*CategoryResponse, error)
 	mustEmbedUnimplementedInventoryServiceServer()
 }
 
@@ -199,6 +266,9 @@ func (UnimplementedInventoryServiceServer) DeleteProduct(context.Context, *Delet
 func (UnimplementedInventoryServiceServer) ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListProducts not implemented")
 }
+func (UnimplementedInventoryServiceServer) BatchGetProducts(context.Context, *BatchGetProductsRequest) (*BatchGetProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetProducts not implemented")
+}
 func (UnimplementedInventoryServiceServer) CreateCategory(context.Context, *CreateCategoryRequest) (*CategoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateCategory not implemented")
 }
@@ -214,6 +284,18 @@ func (UnimplementedInventoryServiceServer) DeleteCategory(context.Context, *Dele
 func (UnimplementedInventoryServiceServer) ListCategories(context.Context, *ListCategoriesRequest) (*ListCategoriesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListCategories not implemented")
 }
+func (UnimplementedInventoryServiceServer) ListChildCategories(context.Context, *ListChildCategoriesRequest) (*ListCategoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChildCategories not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetCategorySubtree(context.Context, *GetCategorySubtreeRequest) (*CategoryTreeNode, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCategorySubtree not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetCategoryBreadcrumbs(context.Context, *GetCategoryBreadcrumbsRequest) (*ListCategoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCategoryBreadcrumbs not implemented")
+}
+func (UnimplementedInventoryServiceServer) MoveCategory(context.Context, *MoveCategoryRequest) (*CategoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveCategory not implemented")
+}
 func (UnimplementedInventoryServiceServer) mustEmbedUnimplementedInventoryServiceServer() {}
 func (UnimplementedInventoryServiceServer) testEmbeddedByValue()                          {}
 
@@ -325,6 +407,24 @@ func _InventoryService_ListProducts_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InventoryService_BatchGetProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).BatchGetProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_BatchGetProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).BatchGetProducts(ctx, req.(*BatchGetProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _InventoryService_CreateCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateCategoryRequest)
 	if err := dec(in); err != nil {
@@ -415,6 +515,78 @@ func _InventoryService_ListCategories_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InventoryService_ListChildCategories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChildCategoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ListChildCategories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ListChildCategories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ListChildCategories(ctx, req.(*ListChildCategoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetCategorySubtree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCategorySubtreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetCategorySubtree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetCategorySubtree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetCategorySubtree(ctx, req.(*GetCategorySubtreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetCategoryBreadcrumbs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCategoryBreadcrumbsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetCategoryBreadcrumbs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetCategoryBreadcrumbs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetCategoryBreadcrumbs(ctx, req.(*GetCategoryBreadcrumbsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_MoveCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).MoveCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_MoveCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).MoveCategory(ctx, req.(*MoveCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // InventoryService_ServiceDesc is the grpc.ServiceDesc for InventoryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -442,6 +614,10 @@ var InventoryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListProducts",
 			Handler:    _InventoryService_ListProducts_Handler,
 		},
+		{
+			MethodName: "BatchGetProducts",
+			Handler:    _InventoryService_BatchGetProducts_Handler,
+		},
 		{
 			MethodName: "CreateCategory",
 			Handler:    _InventoryService_CreateCategory_Handler,
@@ -462,6 +638,22 @@ var InventoryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListCategories",
 			Handler:    _InventoryService_ListCategories_Handler,
 		},
+		{
+			MethodName: "ListChildCategories",
+			Handler:    _InventoryService_ListChildCategories_Handler,
+		},
+		{
+			MethodName: "GetCategorySubtree",
+			Handler:    _InventoryService_GetCategorySubtree_Handler,
+		},
+		{
+			MethodName: "GetCategoryBreadcrumbs",
+			Handler:    _InventoryService_GetCategoryBreadcrumbs_Handler,
+		},
+		{
+			MethodName: "MoveCategory",
+			Handler:    _InventoryService_MoveCategory_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/inventory.proto",
@@ -20,7 +20,7 @@ product_id=$(curl -s -X POST "$BASE_URL/product" \
   -H "Content-Type: application/json" \
   -d '{
     "name": "apple",
-    "price": 25.99,
+    "price": { "currency_code": "USD", "units": 25, "nanos": 990000000 },
     "category": "'"$category_id"'",
     "stock": 1234
   }' | jq -r .id)
@@ -35,9 +35,20 @@ curl -s -X PUT "$BASE_URL/product" \
   -d '{
     "id": "'"$product_id"'",
     "name": "banana",
-    "price": 24.25,
+    "price": { "currency_code": "USD", "units": 24, "nanos": 250000000 },
     "category": "'"$category_id"'",
-    "stock": 324
+    "stock": 324,
+    "version": 1
+  }' | jq .
+
+echo ">>> Updating stock only..."
+curl -s -X PUT "$BASE_URL/product" \
+  -H "Content-Type: application/json" \
+  -d '{
+    "id": "'"$product_id"'",
+    "stock": 0,
+    "update_mask": { "paths": ["stock"] },
+    "version": 2
   }' | jq .
 
 echo ">>> Listing all products..."
@@ -11,9 +11,19 @@ type (
 	Config struct {
 		Version string `env:"VERSION" envDefault:"1.0.0"`
 
-		Mongo   mongo.Config
-		Server  Server
-		Nats    Nats
+		// DefaultCurrency is assigned to amounts stored before Money existed.
+		DefaultCurrency string `env:"DEFAULT_CURRENCY" envDefault:"USD"`
+
+		Mongo     mongo.Config
+		Server    Server
+		Nats      Nats
+		Outbox    Outbox
+		Inventory Inventory
+	}
+
+	// Inventory is where order prices come from.
+	Inventory struct {
+		GRPCAddr string `env:"INVENTORY_GRPC_ADDR" envDefault:"inventory-service:50051"`
 	}
 
 	Server struct {
@@ -32,12 +42,26 @@ type (
 		NKey         string   `env:"NATS_NKEY" envDefault:"SUACSSL3UAHUDXKFSNVUZRF5UHPMWZ6BFDTJ7M6USDXIEDNPPQYYYCU3VY"`
 		IsTest       bool     `env:"NATS_IS_TEST,notEmpty" envDefault:"true"`
 		NatsSubjects NatsSubjects
+
+		// EventEncoding is "json" or "protobuf", see pkg/events.
+		EventEncoding string `env:"EVENT_ENCODING" envDefault:"json"`
+	}
+
+	// Outbox configures the relay that publishes stored events to NATS.
+	Outbox struct {
+		PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+		BatchSize    int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+		Lease        time.Duration `env:"OUTBOX_LEASE" envDefault:"30s"`
+		MinBackoff   time.Duration `env:"OUTBOX_MIN_BACKOFF" envDefault:"1s"`
+		MaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF" envDefault:"5m"`
 	}
 
 	NatsSubjects struct {
 		OrderCreatedSubject string `env:"NATS_ORDER_CREATED_SUBJECT,notEmpty"`
 		OrderUpdatedSubject string `env:"NATS_ORDER_UPDATED_SUBJECT,notEmpty"`
 		OrderDeletedSubject string `env:"NATS_ORDER_DELETED_SUBJECT,notEmpty"`
+
+		PaymentCreatedSubject string `env:"NATS_PAYMENT_CREATED_SUBJECT,notEmpty" envDefault:"payment.created"`
 	}
 )
 
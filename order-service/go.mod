@@ -4,6 +4,7 @@ go 1.23.6
 
 require (
 	github.com/caarlos0/env/v10 v10.0.0
+	github.com/golang/protobuf v1.5.4
 	github.com/nats-io/nats.go v1.42.0
 	github.com/nats-io/nkeys v0.4.11
 	github.com/redis/go-redis/v9 v9.8.0
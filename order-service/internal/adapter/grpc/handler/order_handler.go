@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/Neroframe/ecommerce-platform/order-service/internal/domain"
@@ -28,6 +29,9 @@ func (h *OrderHandler) CreateOrder(ctx context.Context, req *orderpb.CreateOrder
 	}
 	if err := h.orderUsecase.Create(ctx, order); err != nil {
 		log.Printf("[gRPC] CreateOrder failed: %v", err)
+		if errors.Is(err, domain.ErrUnknownProduct) || errors.Is(err, domain.ErrInvalidItems) || errors.Is(err, domain.ErrInvalidMoney) {
+			return nil, status.Errorf(codes.InvalidArgument, "create order failed: %v", err)
+		}
 		return nil, status.Errorf(codes.Internal, "create order failed: %v", err)
 	}
 	log.Printf("[gRPC] Order created with ID: %s", order.ID)
@@ -86,12 +90,17 @@ func toOrderResponse(o *domain.Order) *orderpb.OrderResponse {
 		items = append(items, &orderpb.OrderItem{
 			ProductId: i.ProductID,
 			Quantity:  int32(i.Quantity),
+			UnitPrice: toMoneyProto(i.UnitPrice),
 		})
 	}
-	return &orderpb.OrderResponse{
+	resp := &orderpb.OrderResponse{
 		Id:     o.ID,
 		UserId: o.UserID,
 		Status: o.Status,
 		Items:  items,
 	}
+	if total, err := o.Total(); err == nil {
+		resp.Total = toMoneyProto(total)
+	}
+	return resp
 }
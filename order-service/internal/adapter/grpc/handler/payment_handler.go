@@ -19,9 +19,17 @@ func NewPaymentHandler(uc domain.PaymentUsecase) *PaymentHandler {
 }
 
 func (h *PaymentHandler) CreatePayment(ctx context.Context, req *orderpb.CreatePaymentRequest) (*orderpb.PaymentResponse, error) {
+	if req.Amount == nil {
+		return nil, status.Error(codes.InvalidArgument, "amount is required")
+	}
+	amount, err := domain.NewMoneyFromUnits(req.Amount.Units, req.Amount.Nanos, req.Amount.CurrencyCode)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid amount: %v", err)
+	}
+
 	payment := &domain.Payment{
 		OrderID:       req.OrderId,
-		Amount:        req.Amount,
+		Amount:        amount,
 		PaymentMethod: req.PaymentMethod,
 	}
 
@@ -33,6 +41,7 @@ func (h *PaymentHandler) CreatePayment(ctx context.Context, req *orderpb.CreateP
 		PaymentId: payment.ID,
 		Status:    payment.Status,
 		Message:   "Payment created successfully",
+		Amount:    toMoneyProto(payment.Amount),
 	}, nil
 }
 
@@ -46,5 +55,15 @@ func (h *PaymentHandler) GetPaymentByID(ctx context.Context, req *orderpb.GetPay
 		PaymentId: payment.ID,
 		Status:    payment.Status,
 		Message:   "Payment retrieved successfully",
+		Amount:    toMoneyProto(payment.Amount),
 	}, nil
 }
+
+func toMoneyProto(m domain.Money) *orderpb.Money {
+	units, nanos := m.Units()
+	return &orderpb.Money{
+		CurrencyCode: m.Currency,
+		Units:        units,
+		Nanos:        nanos,
+	}
+}
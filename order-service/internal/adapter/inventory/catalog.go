@@ -0,0 +1,53 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Neroframe/ecommerce-platform/order-service/internal/domain"
+	inventorypb "github.com/Neroframe/ecommerce-platform/order-service/proto/inventory"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ domain.ProductCatalog = (*Catalog)(nil)
+
+// Catalog reads product prices from inventory-service.
+type Catalog struct {
+	client inventorypb.InventoryServiceClient
+}
+
+func NewCatalog(client inventorypb.InventoryServiceClient) *Catalog {
+	return &Catalog{client: client}
+}
+
+func (c *Catalog) Prices(ctx context.Context, productIDs []string) (map[string]domain.Money, error) {
+	resp, err := c.client.BatchGetProducts(ctx, &inventorypb.BatchGetProductsRequest{Ids: productIDs})
+	if status.Code(err) == codes.InvalidArgument {
+		// too many or malformed product IDs
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidItems, status.Convert(err).Message())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("inventory.BatchGetProducts: %w", err)
+	}
+	if len(resp.NotFoundIds) > 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownProduct, strings.Join(resp.NotFoundIds, ", "))
+	}
+
+	prices := make(map[string]domain.Money, len(resp.Products))
+	for _, p := range resp.Products {
+		if p.Price == nil {
+			return nil, fmt.Errorf("product %s has no price", p.Id)
+		}
+		price, err := domain.NewMoneyFromUnits(p.Price.Units, p.Price.Nanos, p.Price.CurrencyCode)
+		if err != nil {
+			return nil, fmt.Errorf("product %s price: %w", p.Id, err)
+		}
+		prices[p.Id] = price
+	}
+
+	log.Printf("[Inventory] Prices fetched for %d products", len(prices))
+	return prices, nil
+}
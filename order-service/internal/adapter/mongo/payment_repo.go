@@ -14,7 +14,7 @@ type PaymentRepository struct {
 	collection *mongo.Collection
 }
 
-func NewPaymentRepository(db *mongo.Database) domain.PaymentRepository {
+func NewPaymentRepository(db *mongo.Database) *PaymentRepository {
 	return &PaymentRepository{
 		collection: db.Collection("payments"),
 	}
@@ -74,6 +74,40 @@ func (r *PaymentRepository) Update(ctx context.Context, p *domain.Payment) error
 	return nil
 }
 
+// MigrateFloatAmounts rewrites payment amounts stored as plain numbers by
+// older versions of the service into Money in the given currency.
+func (r *PaymentRepository) MigrateFloatAmounts(ctx context.Context, currency string) (int, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"amount": bson.M{"$type": bson.A{"double", "int", "long"}}})
+	if err != nil {
+		return 0, fmt.Errorf("mongo find error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			Amount float64            `bson:"amount"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return migrated, fmt.Errorf("mongo decode error: %w", err)
+		}
+
+		amount, err := domain.NewMoneyFromMajor(doc.Amount, currency)
+		if err != nil {
+			return migrated, fmt.Errorf("convert amount of %s: %w", doc.ID.Hex(), err)
+		}
+
+		filter := bson.M{"_id": doc.ID, "amount": doc.Amount}
+		if _, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"amount": amount}}); err != nil {
+			return migrated, fmt.Errorf("mongo update error: %w", err)
+		}
+		migrated++
+	}
+
+	return migrated, cursor.Err()
+}
+
 func (r *PaymentRepository) Delete(ctx context.Context, id string) error {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
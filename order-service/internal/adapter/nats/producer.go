@@ -2,49 +2,44 @@ package nats
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 
 	"github.com/Neroframe/ecommerce-platform/order-service/internal/domain"
-	"github.com/Neroframe/ecommerce-platform/order-service/pkg/nats"
+	natscl "github.com/Neroframe/ecommerce-platform/order-service/pkg/nats"
+	"github.com/nats-io/nats.go"
 )
 
-var _ domain.OrderEventPublisher = (*OrderEventPublisher)(nil)
+var _ domain.MessagePublisher = (*MessagePublisher)(nil)
 
-type OrderEventPublisher struct {
-	client *nats.Client
+// MessagePublisher sends outbox messages to NATS. The outbox message ID goes
+// in the Nats-Msg-Id header, which JetStream uses to drop duplicates.
+type MessagePublisher struct {
+	client *natscl.Client
 }
 
-func NewOrderEventPublisher(client *nats.Client) *OrderEventPublisher {
-	return &OrderEventPublisher{client: client}
+func NewMessagePublisher(client *natscl.Client) *MessagePublisher {
+	return &MessagePublisher{client: client}
 }
 
-func (p *OrderEventPublisher) PublishOrderCreated(ctx context.Context, payload any) error {
-	return p.publish(ctx, "order.created", payload)
-}
-
-func (p *OrderEventPublisher) PublishOrderUpdated(ctx context.Context, payload any) error {
-	return p.publish(ctx, "order.updated", payload)
-}
-
-func (p *OrderEventPublisher) PublishOrderDeleted(ctx context.Context, payload any) error {
-	return p.publish(ctx, "order.deleted", payload)
-}
-
-func (p *OrderEventPublisher) publish(ctx context.Context, subject string, payload any) error {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("[NATS] Marshal error: %v", err)
-		return fmt.Errorf("payload marshal error: %w", err)
+func (p *MessagePublisher) Publish(ctx context.Context, subject, id string, headers map[string]string, data []byte) error {
+	msg := nats.NewMsg(subject)
+	for k, v := range headers {
+		msg.Header.Set(k, v)
 	}
+	msg.Header.Set(nats.MsgIdHdr, id)
+	msg.Data = data
 
-	log.Printf("[NATS] Publishing to subject '%s': %s", subject, data)
+	log.Printf("[NATS] Publishing %d bytes to subject '%s'", len(data), subject)
 
-	if err := p.client.Conn.Publish(subject, data); err != nil {
+	if err := p.client.Conn.PublishMsg(msg); err != nil {
 		log.Printf("[NATS] Publish failed on subject '%s': %v", subject, err)
 		return fmt.Errorf("nats publish error: %w", err)
 	}
+	// the message only counts as sent once the server has it
+	if err := p.client.Conn.FlushTimeout(nats.DefaultTimeout); err != nil {
+		return fmt.Errorf("nats flush error: %w", err)
+	}
 
 	log.Printf("[NATS] Successfully published to subject '%s'", subject)
 	return nil
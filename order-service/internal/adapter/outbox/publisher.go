@@ -0,0 +1,95 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Neroframe/ecommerce-platform/order-service/config"
+	"github.com/Neroframe/ecommerce-platform/order-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/order-service/pkg/events"
+	eventspb "github.com/Neroframe/ecommerce-platform/order-service/proto/events"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	_ domain.OrderEventPublisher   = (*EventPublisher)(nil)
+	_ domain.PaymentEventPublisher = (*EventPublisher)(nil)
+)
+
+// EventPublisher records order and payment events in the outbox instead of sending them.
+// Called inside a transaction, the event is stored atomically with the
+// change; the Relay publishes it to NATS once committed. Events are encoded
+// as configured by EVENT_ENCODING, see package events.
+type EventPublisher struct {
+	outbox   domain.OutboxRepository
+	subjects config.NatsSubjects
+	encoding events.Encoding
+	producer string
+}
+
+func NewEventPublisher(outbox domain.OutboxRepository, subjects config.NatsSubjects, encoding events.Encoding, producer string) *EventPublisher {
+	return &EventPublisher{outbox: outbox, subjects: subjects, encoding: encoding, producer: producer}
+}
+
+func (p *EventPublisher) PublishOrderCreated(ctx context.Context, payload domain.OrderCreatedEvent) error {
+	items := make([]*eventspb.OrderItem, 0, len(payload.Items))
+	for _, item := range payload.Items {
+		items = append(items, &eventspb.OrderItem{
+			ProductId: item.ProductID,
+			Quantity:  int32(item.Quantity),
+			UnitPrice: toMoneyEvent(item.UnitPrice),
+		})
+	}
+
+	return p.add(ctx, p.subjects.OrderCreatedSubject, events.TypeOrderCreated, &eventspb.OrderCreated{
+		OrderId: payload.OrderID,
+		UserId:  payload.UserID,
+		Items:   items,
+	}, payload)
+}
+
+func (p *EventPublisher) PublishOrderUpdated(ctx context.Context, payload domain.OrderUpdatedEvent) error {
+	return p.add(ctx, p.subjects.OrderUpdatedSubject, events.TypeOrderUpdated, &eventspb.OrderUpdated{
+		OrderId: payload.OrderID,
+		Status:  payload.Status,
+	}, payload)
+}
+
+func (p *EventPublisher) PublishOrderDeleted(ctx context.Context, payload domain.OrderDeletedEvent) error {
+	return p.add(ctx, p.subjects.OrderDeletedSubject, events.TypeOrderDeleted, &eventspb.OrderDeleted{
+		OrderId: payload.OrderID,
+	}, payload)
+}
+
+func (p *EventPublisher) PublishPaymentCreated(ctx context.Context, payload domain.PaymentCreatedEvent) error {
+	return p.add(ctx, p.subjects.PaymentCreatedSubject, events.TypePaymentCreated, &eventspb.PaymentCreated{
+		PaymentId: payload.PaymentID,
+		OrderId:   payload.OrderID,
+		Amount:    toMoneyEvent(payload.Amount),
+		Method:    payload.Method,
+		Status:    payload.Status,
+	}, payload)
+}
+
+// add encodes the event and stores it. The outbox message ID doubles as the
+// event ID.
+func (p *EventPublisher) add(ctx context.Context, subject, eventType string, payload proto.Message, legacy any) error {
+	id := primitive.NewObjectID().Hex()
+	meta := events.NewMetadata(ctx, id, eventType, p.producer)
+
+	data, headers, err := events.Encode(p.encoding, meta, payload, legacy)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", eventType, err)
+	}
+
+	msg := &domain.OutboxMessage{ID: id, Subject: subject, Headers: headers, Payload: data}
+	if err := p.outbox.Add(ctx, msg); err != nil {
+		return fmt.Errorf("outbox.Add: %w", err)
+	}
+	return nil
+}
+
+func toMoneyEvent(m domain.Money) *eventspb.Money {
+	return &eventspb.Money{Amount: m.Amount, Currency: m.Currency}
+}
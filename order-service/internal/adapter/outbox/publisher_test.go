@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Neroframe/ecommerce-platform/order-service/config"
+	"github.com/Neroframe/ecommerce-platform/order-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/order-service/pkg/events"
+)
+
+func TestEventContracts(t *testing.T) {
+	subjects := config.NatsSubjects{
+		OrderCreatedSubject:   "order.created",
+		OrderUpdatedSubject:   "order.updated",
+		OrderDeletedSubject:   "order.deleted",
+		PaymentCreatedSubject: "payment.created",
+	}
+
+	for _, enc := range []events.Encoding{events.EncodingJSON, events.EncodingProtobuf} {
+		t.Run(string(enc), func(t *testing.T) {
+			ctx := context.Background()
+			outbox := &recordingOutbox{}
+			p := NewEventPublisher(outbox, subjects, enc, "order-service")
+
+			if err := p.PublishOrderCreated(ctx, domain.OrderCreatedEvent{
+				OrderID: "o1",
+				UserID:  "u1",
+				Items: []domain.OrderItem{
+					{ProductID: "p1", Quantity: 2, UnitPrice: domain.Money{Amount: 499, Currency: "USD"}},
+					{ProductID: "p2", Quantity: 1, UnitPrice: domain.Money{Amount: 1250, Currency: "USD"}},
+				},
+			}); err != nil {
+				t.Fatalf("PublishOrderCreated: %v", err)
+			}
+			if err := p.PublishPaymentCreated(ctx, domain.PaymentCreatedEvent{
+				PaymentID: "pay1",
+				OrderID:   "o1",
+				Amount:    domain.Money{Amount: 2248, Currency: "USD"},
+				Method:    "card",
+				Status:    "Completed",
+			}); err != nil {
+				t.Fatalf("PublishPaymentCreated: %v", err)
+			}
+			if err := p.PublishOrderUpdated(ctx, domain.OrderUpdatedEvent{OrderID: "o1", Status: "Completed"}); err != nil {
+				t.Fatalf("PublishOrderUpdated: %v", err)
+			}
+			if err := p.PublishOrderDeleted(ctx, domain.OrderDeletedEvent{OrderID: "o1"}); err != nil {
+				t.Fatalf("PublishOrderDeleted: %v", err)
+			}
+
+			names := []string{events.TypeOrderCreated, events.TypePaymentCreated, events.TypeOrderUpdated, events.TypeOrderDeleted}
+			if len(outbox.msgs) != len(names) {
+				t.Fatalf("%d messages added, want %d", len(outbox.msgs), len(names))
+			}
+			for i, msg := range outbox.msgs {
+				checkContract(t, enc, names[i], msg)
+			}
+		})
+	}
+}
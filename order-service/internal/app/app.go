@@ -10,18 +10,27 @@ import (
 
 	"github.com/Neroframe/ecommerce-platform/order-service/config"
 	grpcadapter "github.com/Neroframe/ecommerce-platform/order-service/internal/adapter/grpc"
+	"github.com/Neroframe/ecommerce-platform/order-service/internal/adapter/inventory"
 	mongoadapter "github.com/Neroframe/ecommerce-platform/order-service/internal/adapter/mongo"
 	natsadapter "github.com/Neroframe/ecommerce-platform/order-service/internal/adapter/nats"
+	"github.com/Neroframe/ecommerce-platform/order-service/internal/adapter/outbox"
 	"github.com/Neroframe/ecommerce-platform/order-service/internal/usecase"
 
+	"github.com/Neroframe/ecommerce-platform/order-service/pkg/events"
 	mongoconn "github.com/Neroframe/ecommerce-platform/order-service/pkg/mongo"
 	natsconn "github.com/Neroframe/ecommerce-platform/order-service/pkg/nats"
+	"github.com/Neroframe/ecommerce-platform/order-service/pkg/safe"
+	inventorypb "github.com/Neroframe/ecommerce-platform/order-service/proto/inventory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 const serviceName = "order-service"
 
 type App struct {
-	grpcServer *grpcadapter.API
+	grpcServer    *grpcadapter.API
+	outboxRelay   *outbox.Relay
+	inventoryConn *grpc.ClientConn
 	// natsConsumer *natsconsumer.PubSub
 }
 
@@ -35,6 +44,18 @@ func New(ctx context.Context, cfg *config.Config) (*App, error) {
 
 	orderRepo := mongoadapter.NewOrderRepository(mongoDB.Conn)
 	paymentRepo := mongoadapter.NewPaymentRepository(mongoDB.Conn)
+	migrated, err := paymentRepo.MigrateFloatAmounts(ctx, cfg.DefaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("paymentRepo.MigrateFloatAmounts: %w", err)
+	}
+	if migrated > 0 {
+		log.Printf("migrated %d payment amounts to %s minor units", migrated, cfg.DefaultCurrency)
+	}
+	outboxRepo := mongoadapter.NewOutboxRepository(mongoDB.Conn)
+	if err := outboxRepo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("outboxRepo.EnsureIndexes: %w", err)
+	}
+	transactor := mongoadapter.NewTransactor(mongoDB.Client)
 
 	// NATS client
 	natsClient, err := natsconn.NewClient(ctx, cfg.Nats.Hosts, cfg.Nats.NKey, cfg.Nats.IsTest)
@@ -43,21 +64,36 @@ func New(ctx context.Context, cfg *config.Config) (*App, error) {
 	}
 	log.Printf("NATS status: %s", natsClient.Conn.Status())
 
-	// NATS publisher
-	eventPublisher := natsadapter.NewOrderEventPublisher(natsClient)
+	// Events go to the outbox and are relayed to NATS after commit
+	eventEncoding, err := events.ParseEncoding(cfg.Nats.EventEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("events.ParseEncoding: %w", err)
+	}
+	eventPublisher := outbox.NewEventPublisher(outboxRepo, cfg.Nats.NatsSubjects, eventEncoding, serviceName)
+	outboxRelay := outbox.NewRelay(outboxRepo, natsadapter.NewMessagePublisher(natsClient), outbox.RelayConfig(cfg.Outbox))
 
-	orderUC := usecase.NewOrderUsecase(orderRepo, eventPublisher)
-	paymentUC := usecase.NewPaymentUsecase(paymentRepo)
+	// Inventory client, orders are priced from it
+	inventoryConn, err := grpc.NewClient(cfg.Inventory.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("inventory client: %w", err)
+	}
+	catalog := inventory.NewCatalog(inventorypb.NewInventoryServiceClient(inventoryConn))
+
+	orderUC := usecase.NewOrderUsecase(orderRepo, eventPublisher, transactor, catalog)
+	paymentUC := usecase.NewPaymentUsecase(paymentRepo, eventPublisher, transactor)
 
 	grpcAPI := grpcadapter.New(cfg.Server.GRPCServer, orderUC, paymentUC)
 
-	return &App{grpcServer: grpcAPI}, nil
+	return &App{grpcServer: grpcAPI, outboxRelay: outboxRelay, inventoryConn: inventoryConn}, nil
 }
 
 func (a *App) Run() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Publish committed events
+	go safe.Do(ctx, func() { a.outboxRelay.Run(ctx) })
+
 	errCh := make(chan error, 1)
 	a.grpcServer.Run(ctx, errCh)
 	log.Println("Order service is running")
@@ -73,6 +109,9 @@ func (a *App) Run() error {
 		if cerr := a.grpcServer.Stop(ctx); cerr != nil {
 			log.Printf("gRPC stop error: %v", cerr)
 		}
+		if cerr := a.inventoryConn.Close(); cerr != nil {
+			log.Printf("inventory client close error: %v", cerr)
+		}
 		return nil
 	}
 }
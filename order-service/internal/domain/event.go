@@ -3,22 +3,34 @@ package domain
 import "context"
 
 type OrderCreatedEvent struct {
-    OrderID string
-    UserID  string
-    Items   []OrderItem
+	OrderID string      `json:"order_id"`
+	UserID  string      `json:"user_id"`
+	Items   []OrderItem `json:"items"`
 }
 
 type OrderUpdatedEvent struct {
-    OrderID string
-    Status  string
+	OrderID string `json:"order_id"`
+	Status  string `json:"status"`
 }
 
 type OrderDeletedEvent struct {
-    OrderID string
+	OrderID string `json:"order_id"`
+}
+
+type PaymentCreatedEvent struct {
+	PaymentID string `json:"payment_id"`
+	OrderID   string `json:"order_id"`
+	Amount    Money  `json:"amount"`
+	Method    string `json:"method"`
+	Status    string `json:"status"`
 }
 
 type OrderEventPublisher interface {
-	PublishOrderCreated(ctx context.Context, payload any) error
-	PublishOrderUpdated(ctx context.Context, payload any) error
-	PublishOrderDeleted(ctx context.Context, payload any) error
-}
\ No newline at end of file
+	PublishOrderCreated(ctx context.Context, payload OrderCreatedEvent) error
+	PublishOrderUpdated(ctx context.Context, payload OrderUpdatedEvent) error
+	PublishOrderDeleted(ctx context.Context, payload OrderDeletedEvent) error
+}
+
+type PaymentEventPublisher interface {
+	PublishPaymentCreated(ctx context.Context, payload PaymentCreatedEvent) error
+}
@@ -6,7 +6,11 @@ import (
 	"time"
 )
 
-var ErrNotFound = errors.New("not found")
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrUnknownProduct = errors.New("unknown product")
+	ErrInvalidItems   = errors.New("invalid order items")
+)
 
 type Order struct {
 	ID        string      `bson:"_id,omitempty"`
@@ -18,8 +22,37 @@ type Order struct {
 }
 
 type OrderItem struct {
-	ProductID string `bson:"product_id"`
-	Quantity  int    `bson:"quantity"`
+	ProductID string `bson:"product_id" json:"product_id"`
+	Quantity  int    `bson:"quantity" json:"quantity"`
+	UnitPrice Money  `bson:"unit_price" json:"unit_price"` // product price when the order was placed
+}
+
+// Total returns the sum of the line totals. Orders placed before items had
+// prices total zero.
+func (o *Order) Total() (Money, error) {
+	var total Money
+	for i, item := range o.Items {
+		line, err := item.UnitPrice.Mul(int64(item.Quantity))
+		if err != nil {
+			return Money{}, err
+		}
+		if i == 0 {
+			total = line
+			continue
+		}
+		if total, err = total.Add(line); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// ProductCatalog looks up the current prices of products.
+type ProductCatalog interface {
+	// Prices returns the price of every product in productIDs, or
+	// ErrUnknownProduct if one of them does not exist and ErrInvalidItems if
+	// the IDs are malformed or too many.
+	Prices(ctx context.Context, productIDs []string) (map[string]Money, error)
 }
 
 type OrderRepository interface {
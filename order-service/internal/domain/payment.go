@@ -8,7 +8,7 @@ import (
 type Payment struct {
 	ID            string    `bson:"_id,omitempty"`
 	OrderID       string    `bson:"order_id"`
-	Amount        float64   `bson:"amount"`         // The amount paid
+	Amount        Money     `bson:"amount"`         // The amount paid
 	PaymentMethod string    `bson:"payment_method"` // e.g. Credit Card, PayPal
 	Status        string    `bson:"status"`         // e.g. Completed, Failed
 	CreatedAt     time.Time `bson:"created_at"`
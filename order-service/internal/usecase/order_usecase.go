@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Neroframe/ecommerce-platform/order-service/internal/domain"
@@ -11,31 +12,43 @@ import (
 type orderUsecase struct {
 	repo      domain.OrderRepository
 	publisher domain.OrderEventPublisher
+	tx        domain.Transactor
+	catalog   domain.ProductCatalog
 }
 
-func NewOrderUsecase(r domain.OrderRepository, p domain.OrderEventPublisher) domain.OrderUsecase {
-	return &orderUsecase{repo: r, publisher: p}
+// NewOrderUsecase wires the order usecase. p must write to the outbox: events
+// are published in the same transaction as the change.
+func NewOrderUsecase(r domain.OrderRepository, p domain.OrderEventPublisher, tx domain.Transactor, catalog domain.ProductCatalog) domain.OrderUsecase {
+	return &orderUsecase{repo: r, publisher: p, tx: tx, catalog: catalog}
 }
 
 func (u *orderUsecase) Create(ctx context.Context, o *domain.Order) error {
 	if o.UserID == "" || len(o.Items) == 0 {
 		return errors.New("invalid order: missing user or items")
 	}
+	if err := u.priceItems(ctx, o); err != nil {
+		return err
+	}
 	o.Status = "Pending"
 	o.CreatedAt = time.Now()
 	o.UpdatedAt = time.Now()
 
-	err := u.repo.Create(ctx, o)
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.repo.Create(ctx, o); err != nil {
+			return err
+		}
+
+		event := domain.OrderCreatedEvent{
+			OrderID: o.ID,
+			UserID:  o.UserID,
+			Items:   o.Items,
+		}
+		return u.publisher.PublishOrderCreated(ctx, event)
+	})
 	if err != nil {
-		return err
+		o.ID = ""
 	}
-
-	event := domain.OrderCreatedEvent{
-		OrderID: o.ID,
-		UserID:  o.UserID,
-		Items:   o.Items,
-	}
-	return u.publisher.PublishOrderCreated(ctx, event)
+	return err
 }
 
 func (u *orderUsecase) GetByID(ctx context.Context, id string) (*domain.Order, error) {
@@ -50,17 +63,18 @@ func (u *orderUsecase) Update(ctx context.Context, o *domain.Order) error {
 		return errors.New("missing order ID")
 	}
 	o.UpdatedAt = time.Now()
-	err := u.repo.Update(ctx, o)
-	if err != nil {
-		return err
-	}
-
-	event := domain.OrderUpdatedEvent{
-		OrderID: o.ID,
-		Status:  o.Status,
-	}
 
-	return u.publisher.PublishOrderUpdated(ctx, event)
+	return u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.repo.Update(ctx, o); err != nil {
+			return err
+		}
+
+		event := domain.OrderUpdatedEvent{
+			OrderID: o.ID,
+			Status:  o.Status,
+		}
+		return u.publisher.PublishOrderUpdated(ctx, event)
+	})
 }
 
 func (u *orderUsecase) Delete(ctx context.Context, id string) error {
@@ -68,16 +82,16 @@ func (u *orderUsecase) Delete(ctx context.Context, id string) error {
 		return errors.New("missing order ID")
 	}
 
-	err := u.repo.Delete(ctx, id)
-	if err != nil {
-		return err
-	}
+	return u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.repo.Delete(ctx, id); err != nil {
+			return err
+		}
 
-	event := domain.OrderDeletedEvent{
-		OrderID: id,
-	}
-
-	return u.publisher.PublishOrderDeleted(ctx, event)
+		event := domain.OrderDeletedEvent{
+			OrderID: id,
+		}
+		return u.publisher.PublishOrderDeleted(ctx, event)
+	})
 }
 
 func (u *orderUsecase) ListByUserID(ctx context.Context, userID string) ([]*domain.Order, error) {
@@ -86,3 +100,32 @@ func (u *orderUsecase) ListByUserID(ctx context.Context, userID string) ([]*doma
 	}
 	return u.repo.ListByUserID(ctx, userID)
 }
+
+// priceItems sets the unit price of every item to the current product price.
+// All items must be priced in the same currency.
+func (u *orderUsecase) priceItems(ctx context.Context, o *domain.Order) error {
+	ids := make([]string, 0, len(o.Items))
+	seen := make(map[string]struct{}, len(o.Items))
+	for _, item := range o.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("invalid order: quantity of %s must be positive", item.ProductID)
+		}
+		if _, ok := seen[item.ProductID]; !ok {
+			seen[item.ProductID] = struct{}{}
+			ids = append(ids, item.ProductID)
+		}
+	}
+
+	prices, err := u.catalog.Prices(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range o.Items {
+		o.Items[i].UnitPrice = prices[o.Items[i].ProductID]
+	}
+
+	if _, err := o.Total(); err != nil {
+		return fmt.Errorf("invalid order: %w", err)
+	}
+	return nil
+}
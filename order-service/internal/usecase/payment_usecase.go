@@ -9,21 +9,46 @@ import (
 )
 
 type paymentUsecase struct {
-	repo domain.PaymentRepository
+	repo      domain.PaymentRepository
+	publisher domain.PaymentEventPublisher
+	tx        domain.Transactor
 }
 
-func NewPaymentUsecase(r domain.PaymentRepository) domain.PaymentUsecase {
-	return &paymentUsecase{repo: r}
+// NewPaymentUsecase wires the payment usecase. p must write to the outbox:
+// events are published in the same transaction as the change.
+func NewPaymentUsecase(r domain.PaymentRepository, p domain.PaymentEventPublisher, tx domain.Transactor) domain.PaymentUsecase {
+	return &paymentUsecase{repo: r, publisher: p, tx: tx}
 }
 
 func (u *paymentUsecase) Create(ctx context.Context, p *domain.Payment) error {
-	if p.OrderID == "" || p.Amount <= 0 || p.PaymentMethod == "" {
+	if p.OrderID == "" || p.Amount.Amount <= 0 || p.PaymentMethod == "" {
 		return errors.New("invalid payment data")
 	}
+	if err := p.Amount.Validate(); err != nil {
+		return err
+	}
 	p.Status = "Completed"
 	p.CreatedAt = time.Now()
 	p.UpdatedAt = time.Now()
-	return u.repo.Create(ctx, p)
+
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.repo.Create(ctx, p); err != nil {
+			return err
+		}
+
+		event := domain.PaymentCreatedEvent{
+			PaymentID: p.ID,
+			OrderID:   p.OrderID,
+			Amount:    p.Amount,
+			Method:    p.PaymentMethod,
+			Status:    p.Status,
+		}
+		return u.publisher.PublishPaymentCreated(ctx, event)
+	})
+	if err != nil {
+		p.ID = ""
+	}
+	return err
 }
 
 func (u *paymentUsecase) GetByID(ctx context.Context, id string) (*domain.Payment, error) {
@@ -77,6 +77,7 @@ type OrderItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
 	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice     *Money                 `protobuf:"bytes,3,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"` // set by the service from the inventory when the order is placed
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,6 +126,13 @@ func (x *OrderItem) GetQuantity() int32 {
 	return 0
 }
 
+func (x *OrderItem) GetUnitPrice() *Money {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return nil
+}
+
 type GetOrderRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -271,6 +279,7 @@ type OrderResponse struct {
 	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Items         []*OrderItem           `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
 	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Total         *Money                 `protobuf:"bytes,5,opt,name=total,proto3" json:"total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -333,6 +342,13 @@ func (x *OrderResponse) GetStatus() string {
 	return ""
 }
 
+func (x *OrderResponse) GetTotal() *Money {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
 type ListOrdersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Orders        []*OrderResponse       `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
@@ -381,26 +397,29 @@ var File_proto_order_proto protoreflect.FileDescriptor
 
 const file_proto_order_proto_rawDesc = "" +
 	"\n" +
-	"\x11proto/order.proto\x12\x05order\"U\n" +
+	"\x11proto/order.proto\x12\x05order\x1a\x11proto/money.proto\"U\n" +
 	"\x12CreateOrderRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12&\n" +
-	"\x05items\x18\x02 \x03(\v2\x10.order.OrderItemR\x05items\"F\n" +
+	"\x05items\x18\x02 \x03(\v2\x10.order.OrderItemR\x05items\"s\n" +
 	"\tOrderItem\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x05R\bquantity\"!\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12+\n" +
+	"\n" +
+	"unit_price\x18\x03 \x01(\v2\f.order.MoneyR\tunitPrice\"!\n" +
 	"\x0fGetOrderRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"B\n" +
 	"\x18UpdateOrderStatusRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\",\n" +
 	"\x11ListOrdersRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"x\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x9c\x01\n" +
 	"\rOrderResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12&\n" +
 	"\x05items\x18\x03 \x03(\v2\x10.order.OrderItemR\x05items\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\tR\x06status\"B\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\"\n" +
+	"\x05total\x18\x05 \x01(\v2\f.order.MoneyR\x05total\"B\n" +
 	"\x12ListOrdersResponse\x12,\n" +
 	"\x06orders\x18\x01 \x03(\v2\x14.order.OrderResponseR\x06orders2\x9f\x02\n" +
 	"\fOrderService\x12>\n" +
@@ -430,24 +449,27 @@ var file_proto_order_proto_goTypes = []any{
 	(*ListOrdersRequest)(nil),        // 4: order.ListOrdersRequest
 	(*OrderResponse)(nil),            // 5: order.OrderResponse
 	(*ListOrdersResponse)(nil),       // 6: order.ListOrdersResponse
+	(*Money)(nil),                    // 7: order.Money
 }
 var file_proto_order_proto_depIdxs = []int32{
 	1, // 0: order.CreateOrderRequest.items:type_name -> order.OrderItem
-	1, // 1: order.OrderResponse.items:type_name -> order.OrderItem
-	5, // 2: order.ListOrdersResponse.orders:type_name -> order.OrderResponse
-	0, // 3: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
-	2, // 4: order.OrderService.GetOrderByID:input_type -> order.GetOrderRequest
-	3, // 5: order.OrderService.UpdateOrderStatus:input_type -> order.UpdateOrderStatusRequest
-	4, // 6: order.OrderService.ListUserOrders:input_type -> order.ListOrdersRequest
-	5, // 7: order.OrderService.CreateOrder:output_type -> order.OrderResponse
-	5, // 8: order.OrderService.GetOrderByID:output_type -> order.OrderResponse
-	5, // 9: order.OrderService.UpdateOrderStatus:output_type -> order.OrderResponse
-	6, // 10: order.OrderService.ListUserOrders:output_type -> order.ListOrdersResponse
-	7, // [7:11] is the sub-list for method output_type
-	3, // [3:7] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	7, // 1: order.OrderItem.unit_price:type_name -> order.Money
+	1, // 2: order.OrderResponse.items:type_name -> order.OrderItem
+	7, // 3: order.OrderResponse.total:type_name -> order.Money
+	5, // 4: order.ListOrdersResponse.orders:type_name -> order.OrderResponse
+	0, // 5: order.OrderService.CreateOrder:input_type -> order.CreateOrderRequest
+	2, // 6: order.OrderService.GetOrderByID:input_type -> order.GetOrderRequest
+	3, // 7: order.OrderService.UpdateOrderStatus:input_type -> order.UpdateOrderStatusRequest
+	4, // 8: order.OrderService.ListUserOrders:input_type -> order.ListOrdersRequest
+	5, // 9: order.OrderService.CreateOrder:output_type -> order.OrderResponse
+	5, // 10: order.OrderService.GetOrderByID:output_type -> order.OrderResponse
+	5, // 11: order.OrderService.UpdateOrderStatus:output_type -> order.OrderResponse
+	6, // 12: order.OrderService.ListUserOrders:output_type -> order.ListOrdersResponse
+	9, // [9:13] is the sub-list for method output_type
+	5, // [5:9] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_proto_order_proto_init() }
@@ -455,6 +477,7 @@ func file_proto_order_proto_init() {
 	if File_proto_order_proto != nil {
 		return
 	}
+	file_proto_money_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
@@ -24,8 +24,8 @@ const (
 type CreatePaymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
 	PaymentMethod string                 `protobuf:"bytes,3,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
+	Amount        *Money                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -67,18 +67,18 @@ func (x *CreatePaymentRequest) GetOrderId() string {
 	return ""
 }
 
-func (x *CreatePaymentRequest) GetAmount() float64 {
+func (x *CreatePaymentRequest) GetPaymentMethod() string {
 	if x != nil {
-		return x.Amount
+		return x.PaymentMethod
 	}
-	return 0
+	return ""
 }
 
-func (x *CreatePaymentRequest) GetPaymentMethod() string {
+func (x *CreatePaymentRequest) GetAmount() *Money {
 	if x != nil {
-		return x.PaymentMethod
+		return x.Amount
 	}
-	return ""
+	return nil
 }
 
 type PaymentResponse struct {
@@ -86,6 +86,7 @@ type PaymentResponse struct {
 	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
 	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
 	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Amount        *Money                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -141,6 +142,13 @@ func (x *PaymentResponse) GetMessage() string {
 	return ""
 }
 
+func (x *PaymentResponse) GetAmount() *Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
 type GetPaymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PaymentId     string                 `protobuf:"bytes,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
@@ -189,16 +197,17 @@ var File_proto_payment_proto protoreflect.FileDescriptor
 
 const file_proto_payment_proto_rawDesc = "" +
 	"\n" +
-	"\x13proto/payment.proto\x12\x05order\"p\n" +
+	"\x13proto/payment.proto\x12\x05order\x1a\x11proto/money.proto\"\x84\x01\n" +
 	"\x14CreatePaymentRequest\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x01R\x06amount\x12%\n" +
-	"\x0epayment_method\x18\x03 \x01(\tR\rpaymentMethod\"b\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12%\n" +
+	"\x0epayment_method\x18\x03 \x01(\tR\rpaymentMethod\x12$\n" +
+	"\x06amount\x18\x04 \x01(\v2\f.order.MoneyR\x06amountJ\x04\b\x02\x10\x03\"\x88\x01\n" +
 	"\x0fPaymentResponse\x12\x1d\n" +
 	"\n" +
 	"payment_id\x18\x01 \x01(\tR\tpaymentId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"2\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12$\n" +
+	"\x06amount\x18\x04 \x01(\v2\f.order.MoneyR\x06amount\"2\n" +
 	"\x11GetPaymentRequest\x12\x1d\n" +
 	"\n" +
 	"payment_id\x18\x01 \x01(\tR\tpaymentId2\x9a\x01\n" +
@@ -223,17 +232,20 @@ var file_proto_payment_proto_goTypes = []any{
 	(*CreatePaymentRequest)(nil), // 0: order.CreatePaymentRequest
 	(*PaymentResponse)(nil),      // 1: order.PaymentResponse
 	(*GetPaymentRequest)(nil),    // 2: order.GetPaymentRequest
+	(*Money)(nil),                // 3: order.Money
 }
 var file_proto_payment_proto_depIdxs = []int32{
-	0, // 0: order.PaymentService.CreatePayment:input_type -> order.CreatePaymentRequest
-	2, // 1: order.PaymentService.GetPaymentByID:input_type -> order.GetPaymentRequest
-	1, // 2: order.PaymentService.CreatePayment:output_type -> order.PaymentResponse
-	1, // 3: order.PaymentService.GetPaymentByID:output_type -> order.PaymentResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	3, // 0: order.CreatePaymentRequest.amount:type_name -> order.Money
+	3, // 1: order.PaymentResponse.amount:type_name -> order.Money
+	0, // 2: order.PaymentService.CreatePayment:input_type -> order.CreatePaymentRequest
+	2, // 3: order.PaymentService.GetPaymentByID:input_type -> order.GetPaymentRequest
+	1, // 4: order.PaymentService.CreatePayment:output_type -> order.PaymentResponse
+	1, // 5: order.PaymentService.GetPaymentByID:output_type -> order.PaymentResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_proto_payment_proto_init() }
@@ -241,6 +253,7 @@ func file_proto_payment_proto_init() {
 	if File_proto_payment_proto != nil {
 		return
 	}
+	file_proto_money_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
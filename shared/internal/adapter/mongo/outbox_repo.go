@@ -0,0 +1,161 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/shared/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sentOutboxRetention is how long published messages are kept for
+// troubleshooting before a TTL index removes them.
+const sentOutboxRetention = 7 * 24 * time.Hour
+
+var _ domain.OutboxRepository = (*OutboxRepository)(nil)
+
+type OutboxRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOutboxRepository(db *mongo.Database) *OutboxRepository {
+	return &OutboxRepository{collection: db.Collection("outbox")}
+}
+
+// EnsureIndexes creates the index the relay polls with and the TTL index
+// that removes sent messages.
+func (r *OutboxRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}, {Key: "created_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "sent_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(sentOutboxRetention.Seconds())),
+		},
+	})
+	return err
+}
+
+func (r *OutboxRepository) Add(ctx context.Context, msg *domain.OutboxMessage) error {
+	now := time.Now().UTC()
+	oid := primitive.NewObjectID()
+	if msg.ID != "" {
+		var err error
+		if oid, err = primitive.ObjectIDFromHex(msg.ID); err != nil {
+			return fmt.Errorf("invalid outbox message ID %q: %w", msg.ID, err)
+		}
+	}
+
+	doc := bson.M{
+		"_id":             oid,
+		"subject":         msg.Subject,
+		"headers":         msg.Headers,
+		"payload":         msg.Payload,
+		"status":          domain.OutboxStatusPending,
+		"attempts":        0,
+		"created_at":      now,
+		"next_attempt_at": now,
+		"locked_until":    time.Time{},
+	}
+	if _, err := r.collection.InsertOne(ctx, doc); err != nil {
+		return fmt.Errorf("insert outbox message: %w", err)
+	}
+
+	msg.ID = oid.Hex()
+	msg.Status = domain.OutboxStatusPending
+	msg.CreatedAt = now
+	msg.NextAttemptAt = now
+	return nil
+}
+
+// ClaimPending leases a batch in three round trips whatever its size: it
+// reads the IDs of the due messages, leases those still free under a new
+// lease token with one UpdateMany, and reads back the ones carrying the
+// token. Relays racing for the same messages each get the ones they leased.
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	now := time.Now().UTC()
+	due := bson.M{
+		"status":          domain.OutboxStatusPending,
+		"next_attempt_at": bson.M{"$lte": now},
+		"locked_until":    bson.M{"$lte": now},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1})
+	cur, err := r.collection.Find(ctx, due, opts)
+	if err != nil {
+		return nil, fmt.Errorf("find due outbox messages: %w", err)
+	}
+	var candidates []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cur.All(ctx, &candidates); err != nil {
+		return nil, fmt.Errorf("find due outbox messages: %w", err)
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	ids := make(bson.A, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.ID
+	}
+	token := primitive.NewObjectID()
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+	for k, v := range due {
+		filter[k] = v
+	}
+	update := bson.M{"$set": bson.M{"locked_until": now.Add(lease), "lease_token": token}}
+	if _, err := r.collection.UpdateMany(ctx, filter, update); err != nil {
+		return nil, fmt.Errorf("lease outbox messages: %w", err)
+	}
+
+	cur, err = r.collection.Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "lease_token": token},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("read leased outbox messages: %w", err)
+	}
+	var claimed []*domain.OutboxMessage
+	if err := cur.All(ctx, &claimed); err != nil {
+		return nil, fmt.Errorf("read leased outbox messages: %w", err)
+	}
+
+	return claimed, nil
+}
+
+func (r *OutboxRepository) MarkSent(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid outbox message ID %q: %w", id, err)
+	}
+
+	now := time.Now().UTC()
+	_, err = r.collection.UpdateByID(ctx, oid, bson.M{
+		"$set": bson.M{"status": domain.OutboxStatusSent, "sent_at": now},
+		"$inc": bson.M{"attempts": 1},
+	})
+	return err
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id string, cause error, nextAttemptAt time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid outbox message ID %q: %w", id, err)
+	}
+
+	_, err = r.collection.UpdateByID(ctx, oid, bson.M{
+		"$set": bson.M{
+			"last_error":      cause.Error(),
+			"next_attempt_at": nextAttemptAt.UTC(),
+			"locked_until":    time.Time{},
+		},
+		"$inc": bson.M{"attempts": 1},
+	})
+	return err
+}
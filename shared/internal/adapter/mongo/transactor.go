@@ -0,0 +1,48 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Neroframe/ecommerce-platform/shared/internal/domain"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var _ domain.Transactor = (*Transactor)(nil)
+
+// Transactor runs functions in MongoDB multi-document transactions, which
+// need a replica set.
+type Transactor struct {
+	client *mongo.Client
+}
+
+func NewTransactor(client *mongo.Client) *Transactor {
+	return &Transactor{client: client}
+}
+
+// WithinTransaction commits if fn succeeds and aborts otherwise. fn is run
+// exactly once: it is not retried on transient errors because callers fill
+// in generated IDs as they go.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	return mongo.WithSession(ctx, session, func(sc mongo.SessionContext) error {
+		if err := session.StartTransaction(); err != nil {
+			return fmt.Errorf("start transaction: %w", err)
+		}
+
+		if err := fn(sc); err != nil {
+			_ = session.AbortTransaction(context.WithoutCancel(sc))
+			return err
+		}
+
+		if err := session.CommitTransaction(sc); err != nil {
+			return fmt.Errorf("commit transaction: %w", err)
+		}
+		return nil
+	})
+}
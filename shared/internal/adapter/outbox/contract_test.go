@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/shared/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/shared/pkg/events"
+	eventspb "github.com/Neroframe/ecommerce-platform/shared/proto/events"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// contractDir holds the messages the producers send, one file per event in
+// a directory per encoding. statistics-service decodes the same files, so a
+// producer change the consumer cannot read fails one of the two tests.
+const contractDir = "../../../../proto/events/testdata"
+
+var update = flag.Bool("update", false, "rewrite the event contracts in "+contractDir)
+
+// The metadata that differs on every publish is replaced by these.
+const (
+	contractEventID    = "6650f0c0a1b2c3d4e5f60718"
+	contractOccurredAt = "2025-05-01T12:00:00Z"
+)
+
+// contractMessage is a message as sent to NATS. A JSON body is kept as is
+// so that the file can be read, a protobuf one in base64.
+type contractMessage struct {
+	Subject string            `json:"subject"`
+	Headers map[string]string `json:"headers"`
+	JSON    json.RawMessage   `json:"json,omitempty"`
+	Data    []byte            `json:"data,omitempty"`
+}
+
+// recordingOutbox keeps the messages added to it.
+type recordingOutbox struct {
+	msgs []*domain.OutboxMessage
+}
+
+func (o *recordingOutbox) Add(_ context.Context, msg *domain.OutboxMessage) error {
+	o.msgs = append(o.msgs, msg)
+	return nil
+}
+
+func (o *recordingOutbox) ClaimPending(context.Context, int, time.Duration) ([]*domain.OutboxMessage, error) {
+	return nil, nil
+}
+
+func (o *recordingOutbox) MarkSent(context.Context, string) error { return nil }
+
+func (o *recordingOutbox) MarkFailed(context.Context, string, error, time.Time) error { return nil }
+
+// checkContract compares msg with the contract file of its event type and
+// encoding, or rewrites the file when the tests run with -update.
+func checkContract(t *testing.T, enc events.Encoding, eventType string, msg *domain.OutboxMessage) {
+	t.Helper()
+
+	got := normalizeContract(t, msg)
+	path := filepath.Join(contractDir, string(enc), eventType+".json")
+	if *update {
+		data, err := json.MarshalIndent(got, "", "  ")
+		if err != nil {
+			t.Fatalf("json.MarshalIndent: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	var want contractMessage
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("unmarshal %s: %v", path, err)
+	}
+	if want.JSON != nil {
+		var body bytes.Buffer
+		if err := json.Compact(&body, want.JSON); err != nil {
+			t.Fatalf("compact %s: %v", path, err)
+		}
+		want.JSON = body.Bytes()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s changed; if statistics-service still reads it, rewrite the contract with go test -update\ngot:  %+v\nwant: %+v", path, got, want)
+	}
+}
+
+func normalizeContract(t *testing.T, msg *domain.OutboxMessage) contractMessage {
+	t.Helper()
+
+	headers := maps.Clone(msg.Headers)
+	headers[events.HeaderEventID] = contractEventID
+	headers[events.HeaderOccurredAt] = contractOccurredAt
+
+	if headers[events.HeaderContentType] != events.ContentTypeProtobuf {
+		return contractMessage{Subject: msg.Subject, Headers: headers, JSON: msg.Payload}
+	}
+
+	var env eventspb.Envelope
+	if err := proto.Unmarshal(msg.Payload, &env); err != nil {
+		t.Fatalf("proto.Unmarshal envelope: %v", err)
+	}
+	occurredAt, err := time.Parse(time.RFC3339, contractOccurredAt)
+	if err != nil {
+		t.Fatalf("time.Parse: %v", err)
+	}
+	env.EventId = contractEventID
+	env.OccurredAt = timestamppb.New(occurredAt)
+	data, err := (proto.MarshalOptions{Deterministic: true}).Marshal(&env)
+	if err != nil {
+		t.Fatalf("proto.Marshal envelope: %v", err)
+	}
+
+	return contractMessage{Subject: msg.Subject, Headers: headers, Data: data}
+}
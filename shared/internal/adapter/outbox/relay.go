@@ -0,0 +1,85 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/shared/internal/domain"
+)
+
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Lease        time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+}
+
+// Relay publishes committed outbox messages. A message is marked sent only
+// after the broker accepted it, so delivery is at least once; consumers drop
+// duplicates by the message ID sent along. Failed messages are retried with
+// exponential backoff. Several relays can run side by side, each message is
+// leased to one of them at a time.
+type Relay struct {
+	outbox    domain.OutboxRepository
+	publisher domain.MessagePublisher
+	cfg       RelayConfig
+}
+
+func NewRelay(outbox domain.OutboxRepository, publisher domain.MessagePublisher, cfg RelayConfig) *Relay {
+	return &Relay{outbox: outbox, publisher: publisher, cfg: cfg}
+}
+
+// Run polls the outbox until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// keep draining while batches come back full
+		if r.relayBatch(ctx) == r.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// relayBatch publishes one batch and returns how many messages it claimed.
+func (r *Relay) relayBatch(ctx context.Context) int {
+	msgs, err := r.outbox.ClaimPending(ctx, r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		log.Printf("[Outbox] claim failed: %v", err)
+	}
+
+	for _, msg := range msgs {
+		if err := r.publisher.Publish(ctx, msg.Subject, msg.ID, msg.Headers, msg.Payload); err != nil {
+			next := time.Now().Add(r.backoff(msg.Attempts))
+			log.Printf("[Outbox] publish %s to '%s' failed, attempt %d, retry at %s: %v", msg.ID, msg.Subject, msg.Attempts+1, next.Format(time.RFC3339), err)
+			if err := r.outbox.MarkFailed(ctx, msg.ID, err, next); err != nil {
+				log.Printf("[Outbox] mark %s failed: %v", msg.ID, err)
+			}
+			continue
+		}
+
+		if err := r.outbox.MarkSent(ctx, msg.ID); err != nil {
+			// the lease expires and the message is sent again
+			log.Printf("[Outbox] mark %s sent: %v", msg.ID, err)
+		}
+	}
+
+	return len(msgs)
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.cfg.MinBackoff
+	for i := 0; i < attempts && d < r.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, r.cfg.MaxBackoff)
+}
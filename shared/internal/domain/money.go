@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+const nanosPerUnit = 1_000_000_000
+
+// Money is an amount of Currency expressed in its minor units, e.g. 499 USD
+// is $4.99 and 500 JPY is ¥500.
+type Money struct {
+	Amount   int64  `bson:"amount" json:"amount"`
+	Currency string `bson:"currency" json:"currency"`
+}
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit is not a
+// hundredth of the major unit.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyExponent returns the number of minor unit digits of currency.
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// NewMoneyFromMajor converts a major unit amount such as 4.99 into Money.
+//
+// The amount is rounded to the currency's minor unit half away from zero
+// using its shortest decimal representation, so 4.995 USD becomes 500 and
+// -4.995 USD becomes -500 even though 4.995 is not exact as a float64.
+func NewMoneyFromMajor(amount float64, currency string) (Money, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return Money{}, ErrInvalidMoney
+	}
+
+	minor, err := decimalToMinor(strconv.FormatFloat(amount, 'f', -1, 64), CurrencyExponent(currency))
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// NewMoneyFromUnits converts the google.type.Money representation (whole
+// units plus nanos, both carrying the same sign) into Money. Nanos below the
+// currency's minor unit are rounded half away from zero.
+func NewMoneyFromUnits(units int64, nanos int32, currency string) (Money, error) {
+	if nanos <= -nanosPerUnit || nanos >= nanosPerUnit {
+		return Money{}, fmt.Errorf("%w: nanos out of range", ErrInvalidMoney)
+	}
+	if (units > 0 && nanos < 0) || (units < 0 && nanos > 0) {
+		return Money{}, fmt.Errorf("%w: units and nanos signs differ", ErrInvalidMoney)
+	}
+
+	exp := CurrencyExponent(currency)
+	scale := pow10(exp)
+	if units > math.MaxInt64/scale || units < math.MinInt64/scale {
+		return Money{}, fmt.Errorf("%w: amount overflows", ErrInvalidMoney)
+	}
+
+	step := int64(nanosPerUnit) / scale
+	frac := int64(nanos) / step
+	if rem := int64(nanos) % step; rem*2 >= step {
+		frac++
+	} else if rem*2 <= -step {
+		frac--
+	}
+
+	amount := units * scale
+	if (frac > 0 && amount > math.MaxInt64-frac) || (frac < 0 && amount < math.MinInt64-frac) {
+		return Money{}, fmt.Errorf("%w: amount overflows", ErrInvalidMoney)
+	}
+
+	return Money{Amount: amount + frac, Currency: currency}, nil
+}
+
+// Units returns m in the google.type.Money representation.
+func (m Money) Units() (int64, int32) {
+	scale := pow10(CurrencyExponent(m.Currency))
+	step := int64(nanosPerUnit) / scale
+	return m.Amount / scale, int32((m.Amount % scale) * step)
+}
+
+// Major returns m in major units. It is meant for display only.
+func (m Money) Major() float64 {
+	return float64(m.Amount) / float64(pow10(CurrencyExponent(m.Currency)))
+}
+
+// Mul returns m multiplied by qty, or ErrInvalidMoney if the product does
+// not fit in an int64.
+func (m Money) Mul(qty int64) (Money, error) {
+	amount := m.Amount * qty
+	if qty != 0 && (amount/qty != m.Amount || (qty == -1 && m.Amount == math.MinInt64)) {
+		return Money{}, fmt.Errorf("%w: amount overflows", ErrInvalidMoney)
+	}
+	return Money{Amount: amount, Currency: m.Currency}, nil
+}
+
+// Add returns the sum of m and other, which must share a currency, or
+// ErrInvalidMoney if the sum does not fit in an int64.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: currency mismatch %s and %s", ErrInvalidMoney, m.Currency, other.Currency)
+	}
+	amount := m.Amount + other.Amount
+	if (other.Amount > 0 && amount < m.Amount) || (other.Amount < 0 && amount > m.Amount) {
+		return Money{}, fmt.Errorf("%w: amount overflows", ErrInvalidMoney)
+	}
+	return Money{Amount: amount, Currency: m.Currency}, nil
+}
+
+// Validate checks that Currency looks like an ISO 4217 code.
+func (m Money) Validate() error {
+	if len(m.Currency) != 3 || strings.ToUpper(m.Currency) != m.Currency {
+		return fmt.Errorf("%w: currency must be a 3-letter ISO 4217 code", ErrInvalidMoney)
+	}
+	for _, r := range m.Currency {
+		if r < 'A' || r > 'Z' {
+			return fmt.Errorf("%w: currency must be a 3-letter ISO 4217 code", ErrInvalidMoney)
+		}
+	}
+	return nil
+}
+
+func (m Money) String() string {
+	exp := CurrencyExponent(m.Currency)
+	return strconv.FormatFloat(m.Major(), 'f', exp, 64) + " " + m.Currency
+}
+
+// decimalToMinor parses a plain decimal string and rounds it to exp fraction
+// digits, half away from zero.
+func decimalToMinor(s string, exp int) (int64, error) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" {
+		whole = "0"
+	}
+
+	roundUp := false
+	if len(frac) > exp {
+		roundUp = frac[exp] >= '5'
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidMoney, err)
+	}
+	if roundUp {
+		if minor == math.MaxInt64 {
+			return 0, fmt.Errorf("%w: amount overflows", ErrInvalidMoney)
+		}
+		minor++
+	}
+	if neg {
+		minor = -minor
+	}
+
+	return minor, nil
+}
+
+func pow10(exp int) int64 {
+	p := int64(1)
+	for i := 0; i < exp; i++ {
+		p *= 10
+	}
+	return p
+}
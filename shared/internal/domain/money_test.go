@@ -0,0 +1,230 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCurrencyExponent(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     int
+	}{
+		{"USD", 2},
+		{"EUR", 2},
+		{"XYZ", 2},
+		{"JPY", 0},
+		{"KRW", 0},
+		{"KWD", 3},
+		{"BHD", 3},
+	}
+	for _, tt := range tests {
+		if got := CurrencyExponent(tt.currency); got != tt.want {
+			t.Errorf("CurrencyExponent(%s) = %d, want %d", tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestNewMoneyFromMajor(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   float64
+		currency string
+		want     int64
+		err      bool
+	}{
+		{"exact", 4.99, "USD", 499, false},
+		{"half rounds up", 4.995, "USD", 500, false},
+		{"below half rounds down", 4.994, "USD", 499, false},
+		{"shortest representation", 1.005, "USD", 101, false},
+		{"negative half rounds away from zero", -4.995, "USD", -500, false},
+		{"negative below half", -4.994, "USD", -499, false},
+		{"smallest half", 0.005, "USD", 1, false},
+		{"smallest negative half", -0.005, "USD", -1, false},
+		{"zero", 0, "USD", 0, false},
+		{"unknown currency has two digits", 1.5, "XYZ", 150, false},
+		{"zero digits", 500, "JPY", 500, false},
+		{"zero digits half", 499.5, "JPY", 500, false},
+		{"zero digits below half", 499.4, "JPY", 499, false},
+		{"zero digits negative half", -499.5, "JPY", -500, false},
+		{"three digits", 1.234, "KWD", 1234, false},
+		{"three digits half", 1.2345, "KWD", 1235, false},
+		{"three digits below half", 1.2344, "KWD", 1234, false},
+		{"three digits negative half", -0.0005, "KWD", -1, false},
+		{"NaN", math.NaN(), "USD", 0, true},
+		{"infinity", math.Inf(1), "USD", 0, true},
+		{"overflow", 1e17, "USD", 0, true},
+		{"negative overflow", -1e17, "USD", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMoneyFromMajor(tt.amount, tt.currency)
+			if tt.err {
+				if !errors.Is(err, ErrInvalidMoney) {
+					t.Fatalf("NewMoneyFromMajor(%v, %s) error = %v, want ErrInvalidMoney", tt.amount, tt.currency, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewMoneyFromMajor(%v, %s) error = %v", tt.amount, tt.currency, err)
+			}
+			if got != (Money{Amount: tt.want, Currency: tt.currency}) {
+				t.Errorf("NewMoneyFromMajor(%v, %s) = %v, want %d", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMoneyFromUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		units    int64
+		nanos    int32
+		currency string
+		want     int64
+		err      bool
+	}{
+		{"exact", 4, 990_000_000, "USD", 499, false},
+		{"half rounds up", 4, 995_000_000, "USD", 500, false},
+		{"below half rounds down", 4, 994_999_999, "USD", 499, false},
+		{"negative half rounds away from zero", -4, -995_000_000, "USD", -500, false},
+		{"negative below half", -4, -994_999_999, "USD", -499, false},
+		{"negative nanos only", 0, -5_000_000, "USD", -1, false},
+		{"zero digits", 500, 0, "JPY", 500, false},
+		{"zero digits half", 499, 500_000_000, "JPY", 500, false},
+		{"zero digits negative half", -499, -500_000_000, "JPY", -500, false},
+		{"three digits", 1, 234_000_000, "KWD", 1234, false},
+		{"three digits half", 1, 234_500_000, "KWD", 1235, false},
+		{"three digits negative", -1, -234_400_000, "KWD", -1234, false},
+		{"largest", math.MaxInt64 / 100, 70_000_000, "USD", math.MaxInt64, false},
+		{"nanos out of range", 1, 1_000_000_000, "USD", 0, true},
+		{"negative nanos out of range", -1, -1_000_000_000, "USD", 0, true},
+		{"signs differ", 1, -1, "USD", 0, true},
+		{"units overflow", math.MaxInt64/100 + 1, 0, "USD", 0, true},
+		{"negative units overflow", math.MinInt64/100 - 1, 0, "USD", 0, true},
+		{"rounding overflows", math.MaxInt64, 500_000_000, "JPY", 0, true},
+		{"negative rounding overflows", math.MinInt64, -500_000_000, "JPY", 0, true},
+		{"three digits units overflow", math.MaxInt64/1000 + 1, 0, "KWD", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMoneyFromUnits(tt.units, tt.nanos, tt.currency)
+			if tt.err {
+				if !errors.Is(err, ErrInvalidMoney) {
+					t.Fatalf("NewMoneyFromUnits(%d, %d, %s) error = %v, want ErrInvalidMoney", tt.units, tt.nanos, tt.currency, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewMoneyFromUnits(%d, %d, %s) error = %v", tt.units, tt.nanos, tt.currency, err)
+			}
+			if got != (Money{Amount: tt.want, Currency: tt.currency}) {
+				t.Errorf("NewMoneyFromUnits(%d, %d, %s) = %v, want %d", tt.units, tt.nanos, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyUnits(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		units int64
+		nanos int32
+	}{
+		{"two digits", Money{Amount: 499, Currency: "USD"}, 4, 990_000_000},
+		{"two digits negative", Money{Amount: -499, Currency: "USD"}, -4, -990_000_000},
+		{"cents only negative", Money{Amount: -1, Currency: "USD"}, 0, -10_000_000},
+		{"zero digits", Money{Amount: 500, Currency: "JPY"}, 500, 0},
+		{"zero digits negative", Money{Amount: -500, Currency: "JPY"}, -500, 0},
+		{"three digits", Money{Amount: 1235, Currency: "KWD"}, 1, 235_000_000},
+		{"three digits negative", Money{Amount: -1, Currency: "KWD"}, 0, -1_000_000},
+		{"largest", Money{Amount: math.MaxInt64, Currency: "USD"}, math.MaxInt64 / 100, 70_000_000},
+		{"smallest", Money{Amount: math.MinInt64, Currency: "USD"}, math.MinInt64 / 100, -80_000_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			units, nanos := tt.money.Units()
+			if units != tt.units || nanos != tt.nanos {
+				t.Fatalf("%v.Units() = (%d, %d), want (%d, %d)", tt.money, units, nanos, tt.units, tt.nanos)
+			}
+			back, err := NewMoneyFromUnits(units, nanos, tt.money.Currency)
+			if err != nil || back != tt.money {
+				t.Errorf("NewMoneyFromUnits(%d, %d) = %v, %v, want %v", units, nanos, back, err, tt.money)
+			}
+		})
+	}
+}
+
+func TestMoneyMul(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		qty    int64
+		want   int64
+		err    bool
+	}{
+		{"positive", 499, 3, 1497, false},
+		{"negative amount", -499, 3, -1497, false},
+		{"negative quantity", 499, -3, -1497, false},
+		{"zero quantity", 499, 0, 0, false},
+		{"zero amount", 0, math.MaxInt64, 0, false},
+		{"largest", math.MaxInt64, 1, math.MaxInt64, false},
+		{"overflow", math.MaxInt64/2 + 1, 2, 0, true},
+		{"negative overflow", math.MinInt64/2 - 1, 2, 0, true},
+		{"negated smallest", math.MinInt64, -1, 0, true},
+		{"smallest times minus one", -1, math.MinInt64, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Money{Amount: tt.amount, Currency: "USD"}.Mul(tt.qty)
+			if tt.err {
+				if !errors.Is(err, ErrInvalidMoney) {
+					t.Fatalf("Mul(%d, %d) error = %v, want ErrInvalidMoney", tt.amount, tt.qty, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Mul(%d, %d) error = %v", tt.amount, tt.qty, err)
+			}
+			if got != (Money{Amount: tt.want, Currency: "USD"}) {
+				t.Errorf("Mul(%d, %d) = %v, want %d", tt.amount, tt.qty, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Money
+		want int64
+		err  bool
+	}{
+		{"positive", Money{499, "USD"}, Money{1, "USD"}, 500, false},
+		{"negative", Money{-499, "USD"}, Money{-1, "USD"}, -500, false},
+		{"mixed signs", Money{499, "USD"}, Money{-500, "USD"}, -1, false},
+		{"largest", Money{math.MaxInt64 - 1, "USD"}, Money{1, "USD"}, math.MaxInt64, false},
+		{"currency mismatch", Money{499, "USD"}, Money{499, "EUR"}, 0, true},
+		{"overflow", Money{math.MaxInt64, "USD"}, Money{1, "USD"}, 0, true},
+		{"negative overflow", Money{math.MinInt64, "USD"}, Money{-1, "USD"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Add(tt.b)
+			if tt.err {
+				if !errors.Is(err, ErrInvalidMoney) {
+					t.Fatalf("%v.Add(%v) error = %v, want ErrInvalidMoney", tt.a, tt.b, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%v.Add(%v) error = %v", tt.a, tt.b, err)
+			}
+			if got != (Money{Amount: tt.want, Currency: tt.a.Currency}) {
+				t.Errorf("%v.Add(%v) = %v, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
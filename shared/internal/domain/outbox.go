@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+)
+
+// OutboxMessage is an event stored in the same transaction as the change it
+// describes. The outbox relay publishes it afterwards, so an event is sent if
+// and only if its change was committed.
+type OutboxMessage struct {
+	ID            string            `bson:"_id,omitempty"`
+	Subject       string            `bson:"subject"`
+	Headers       map[string]string `bson:"headers,omitempty"`
+	Payload       []byte            `bson:"payload"`
+	Status        string            `bson:"status"`
+	Attempts      int               `bson:"attempts"`
+	LastError     string            `bson:"last_error,omitempty"`
+	CreatedAt     time.Time         `bson:"created_at"`
+	NextAttemptAt time.Time         `bson:"next_attempt_at"`
+	LockedUntil   time.Time         `bson:"locked_until"`
+	SentAt        *time.Time        `bson:"sent_at,omitempty"`
+}
+
+type OutboxRepository interface {
+	// Add stores msg under msg.ID, or a new ID if it is empty.
+	Add(ctx context.Context, msg *OutboxMessage) error
+	// ClaimPending leases up to limit messages that are due, oldest first, so
+	// that other relays skip them until the lease expires.
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*OutboxMessage, error)
+	MarkSent(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, cause error, nextAttemptAt time.Time) error
+}
+
+// Transactor runs fn in a database transaction. Repositories called with the
+// context passed to fn take part in the transaction.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// MessagePublisher delivers an outbox message to the broker. id is used by
+// the broker to drop duplicates of a redelivered message.
+type MessagePublisher interface {
+	Publish(ctx context.Context, subject, id string, headers map[string]string, data []byte) error
+}
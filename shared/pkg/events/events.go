@@ -0,0 +1,225 @@
+// Package events encodes and decodes the events of proto/events sent over
+// NATS.
+//
+// An event is sent either as JSON, the bare payload with its snake_case
+// proto field names, or as protobuf, the payload wrapped in an
+// eventspb.Envelope. The Content-Type header tells them apart, the other
+// envelope fields are sent as headers with both encodings so that JSON and
+// protobuf consumers can coexist while producers are migrated. Messages
+// without it are JSON from producers that predate the envelope.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	eventspb "github.com/Neroframe/ecommerce-platform/shared/proto/events"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NATS headers.
+const (
+	HeaderContentType   = "Content-Type"
+	HeaderEventID       = "Event-Id"
+	HeaderEventType     = "Event-Type"
+	HeaderSchemaVersion = "Event-Schema-Version"
+	HeaderOccurredAt    = "Event-Occurred-At"
+	HeaderProducer      = "Event-Producer"
+	HeaderTraceparent   = "traceparent"
+	HeaderTracestate    = "tracestate"
+)
+
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/protobuf"
+)
+
+// Event types. They match the default NATS subjects.
+const (
+	TypeOrderCreated = "order.created"
+	TypeOrderUpdated = "order.updated"
+	TypeOrderDeleted = "order.deleted"
+
+	TypePaymentCreated = "payment.created"
+
+	TypeProductCreated = "product.created"
+	TypeProductUpdated = "product.updated"
+	TypeProductDeleted = "product.deleted"
+
+	TypeCategoryCreated = "category.created"
+	TypeCategoryUpdated = "category.updated"
+	TypeCategoryDeleted = "category.deleted"
+)
+
+// SchemaVersion is the version of proto/events the producers write.
+const SchemaVersion = 1
+
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+type Encoding string
+
+const (
+	EncodingJSON     Encoding = "json"
+	EncodingProtobuf Encoding = "protobuf"
+)
+
+func ParseEncoding(s string) (Encoding, error) {
+	switch enc := Encoding(s); enc {
+	case EncodingJSON, EncodingProtobuf:
+		return enc, nil
+	default:
+		return "", fmt.Errorf("unknown event encoding %q", s)
+	}
+}
+
+// Metadata is the envelope of an event without its payload. Legacy JSON
+// events have none of it.
+type Metadata struct {
+	EventID       string
+	Type          string
+	SchemaVersion int32
+	Producer      string
+	OccurredAt    time.Time
+	Traceparent   string
+	Tracestate    string
+}
+
+// NewMetadata returns the metadata of an event that occurs now, taking the
+// trace context from the incoming gRPC request in ctx if there is one.
+func NewMetadata(ctx context.Context, eventID, eventType, producer string) Metadata {
+	meta := Metadata{
+		EventID:       eventID,
+		Type:          eventType,
+		SchemaVersion: SchemaVersion,
+		Producer:      producer,
+		OccurredAt:    time.Now().UTC(),
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(HeaderTraceparent); len(v) > 0 {
+			meta.Traceparent = v[0]
+		}
+		if v := md.Get(HeaderTracestate); len(v) > 0 {
+			meta.Tracestate = v[0]
+		}
+	}
+	return meta
+}
+
+// Encode returns the message body and headers of an event. legacy is the
+// JSON form of payload; its field names must match the proto field names.
+func Encode(enc Encoding, meta Metadata, payload proto.Message, legacy any) ([]byte, map[string]string, error) {
+	headers := map[string]string{
+		HeaderEventID:       meta.EventID,
+		HeaderEventType:     meta.Type,
+		HeaderSchemaVersion: fmt.Sprint(SchemaVersion),
+		HeaderOccurredAt:    meta.OccurredAt.Format(time.RFC3339Nano),
+		HeaderProducer:      meta.Producer,
+	}
+	if meta.Traceparent != "" {
+		headers[HeaderTraceparent] = meta.Traceparent
+		headers[HeaderTracestate] = meta.Tracestate
+	}
+
+	switch enc {
+	case EncodingJSON:
+		data, err := json.Marshal(legacy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("json.Marshal: %w", err)
+		}
+		headers[HeaderContentType] = ContentTypeJSON
+		return data, headers, nil
+
+	case EncodingProtobuf:
+		body, err := anypb.New(payload)
+		if err != nil {
+			return nil, nil, fmt.Errorf("anypb.New: %w", err)
+		}
+		env := &eventspb.Envelope{
+			EventId:       meta.EventID,
+			Type:          meta.Type,
+			SchemaVersion: SchemaVersion,
+			OccurredAt:    timestamppb.New(meta.OccurredAt),
+			Producer:      meta.Producer,
+			Payload:       body,
+		}
+		if meta.Traceparent != "" {
+			env.TraceContext = &eventspb.TraceContext{
+				Traceparent: meta.Traceparent,
+				Tracestate:  meta.Tracestate,
+			}
+		}
+		data, err := proto.Marshal(env)
+		if err != nil {
+			return nil, nil, fmt.Errorf("proto.Marshal: %w", err)
+		}
+		headers[HeaderContentType] = ContentTypeProtobuf
+		return data, headers, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown event encoding %q", enc)
+	}
+}
+
+// Decode reads an event of eventType into payload. Fields a newer schema
+// version added are ignored.
+func Decode(header map[string][]string, data []byte, eventType string, payload proto.Message) (Metadata, error) {
+	switch contentType := first(header, HeaderContentType); contentType {
+	case ContentTypeProtobuf:
+		var env eventspb.Envelope
+		if err := proto.Unmarshal(data, &env); err != nil {
+			return Metadata{}, fmt.Errorf("proto.Unmarshal envelope: %w", err)
+		}
+		if env.GetType() != eventType {
+			return Metadata{}, fmt.Errorf("event type %q, want %q", env.GetType(), eventType)
+		}
+		if err := env.GetPayload().UnmarshalTo(payload); err != nil {
+			return Metadata{}, fmt.Errorf("unmarshal payload: %w", err)
+		}
+		return Metadata{
+			EventID:       env.GetEventId(),
+			Type:          env.GetType(),
+			SchemaVersion: env.GetSchemaVersion(),
+			Producer:      env.GetProducer(),
+			OccurredAt:    env.GetOccurredAt().AsTime(),
+			Traceparent:   env.GetTraceContext().GetTraceparent(),
+			Tracestate:    env.GetTraceContext().GetTracestate(),
+		}, nil
+
+	case ContentTypeJSON, "":
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, payload); err != nil {
+			return Metadata{}, fmt.Errorf("protojson.Unmarshal: %w", err)
+		}
+		meta := Metadata{
+			EventID:     first(header, HeaderEventID),
+			Type:        eventType,
+			Producer:    first(header, HeaderProducer),
+			Traceparent: first(header, HeaderTraceparent),
+			Tracestate:  first(header, HeaderTracestate),
+		}
+		if v, err := strconv.ParseInt(first(header, HeaderSchemaVersion), 10, 32); err == nil {
+			meta.SchemaVersion = int32(v)
+		}
+		if t, err := time.Parse(time.RFC3339Nano, first(header, HeaderOccurredAt)); err == nil {
+			meta.OccurredAt = t
+		}
+		return meta, nil
+
+	default:
+		return Metadata{}, fmt.Errorf("%w %q", ErrUnsupportedContentType, contentType)
+	}
+}
+
+func first(header map[string][]string, key string) string {
+	if v := header[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
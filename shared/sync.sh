@@ -0,0 +1,68 @@
+#!/bin/bash
+# Copies the sources shared by several services into each of them. Every
+# service is built on its own, so shared code cannot be imported from one
+# place; it is kept here once instead and the copies are generated.
+#
+# Go files live under shared/ at the path they get in a service and import
+# each other as github.com/Neroframe/ecommerce-platform/shared/..., which is
+# rewritten to the module path of the service. Other files are copied as they
+# are, from shared/ or, for proto/events, from the repository root.
+#
+# usage: shared/sync.sh          write the copies
+#        shared/sync.sh -check   list copies that differ and exit 1 if any do
+set -euo pipefail
+
+cd "$(dirname "$0")/.."
+
+# source, then the services that get a copy at the same relative path
+targets=(
+	"shared/internal/domain/outbox.go inventory-service order-service"
+	"shared/internal/domain/money.go inventory-service order-service statistics-service"
+	"shared/internal/domain/money_test.go inventory-service order-service statistics-service"
+	"shared/internal/adapter/outbox/relay.go inventory-service order-service"
+	"shared/internal/adapter/mongo/transactor.go inventory-service order-service"
+	"shared/internal/adapter/mongo/outbox_repo.go inventory-service order-service"
+	"shared/internal/adapter/outbox/contract_test.go inventory-service order-service"
+	"shared/pkg/events/events.go inventory-service order-service statistics-service"
+	"proto/events/events.proto inventory-service order-service statistics-service"
+)
+
+check=false
+if [[ "${1:-}" == "-check" ]]; then
+	check=true
+fi
+
+render() {
+	local src=$1 service=$2
+	case $src in
+	*.go)
+		printf '// Code generated by shared/sync.sh from %s. DO NOT EDIT.\n\n' "$src"
+		sed "s#github.com/Neroframe/ecommerce-platform/shared/#github.com/Neroframe/ecommerce-platform/$service/#g" "$src"
+		;;
+	*)
+		cat "$src"
+		;;
+	esac
+}
+
+stale=0
+for target in "${targets[@]}"; do
+	read -r src services <<<"$target"
+	for service in $services; do
+		dst=$service/${src#shared/}
+		if $check; then
+			if ! render "$src" "$service" | cmp -s - "$dst"; then
+				echo "$dst differs from $src"
+				stale=1
+			fi
+			continue
+		fi
+		mkdir -p "$(dirname "$dst")"
+		render "$src" "$service" >"$dst"
+	done
+done
+
+if ((stale)); then
+	echo "run shared/sync.sh to update the copies" >&2
+	exit 1
+fi
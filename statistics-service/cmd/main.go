@@ -3,12 +3,22 @@ package main
 import (
 	"context"
 	"log"
+	"os"
+	_ "time/tzdata" // time zones of the heatmaps, the image has no zoneinfo
 
 	"github.com/Neroframe/ecommerce-platform/statistics-service/config"
 	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/app"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/cli"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := cli.Run(context.Background(), os.Args[1:]); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	cfg, err := config.New()
 	if err != nil {
 		log.Fatalf("config load error: %v", err)
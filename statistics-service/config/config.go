@@ -11,9 +11,39 @@ type (
 	Config struct {
 		Version string `env:"VERSION"`
 
+		// DefaultCurrency is the currency of sales statistics requested
+		// without one.
+		DefaultCurrency string `env:"DEFAULT_CURRENCY" envDefault:"USD"`
+
+		// ActiveUsersTTL is how long the daily active user sketches are kept.
+		ActiveUsersTTL time.Duration `env:"ACTIVE_USERS_SKETCH_TTL" envDefault:"9600h"`
+
+		// RelatedMinOrders is how many orders two products must have in
+		// common to be related.
+		RelatedMinOrders int64 `env:"RELATED_MIN_ORDERS" envDefault:"2"`
+
 		Mongo  mongo.Config
 		Server Server
 		Nats   Nats
+		Redis  Redis
+		RFM    RFM
+		Watch  Watch
+	}
+
+	// Watch sets how live statistics are pushed to dashboards.
+	Watch struct {
+		// Interval is the least time between two pushes to a dashboard.
+		Interval time.Duration `env:"WATCH_MIN_INTERVAL" envDefault:"1s"`
+		LowStock int64         `env:"LOW_STOCK_THRESHOLD" envDefault:"5"`
+	}
+
+	// RFM sets the thresholds of the customer segments.
+	RFM struct {
+		Recent         time.Duration `env:"RFM_RECENT" envDefault:"720h"`
+		Lapsed         time.Duration `env:"RFM_LAPSED" envDefault:"2160h"`
+		FrequentOrders int64         `env:"RFM_FREQUENT_ORDERS" envDefault:"5"`
+		// HighValue is in minor units of DefaultCurrency.
+		HighValue int64 `env:"RFM_HIGH_VALUE" envDefault:"50000"`
 	}
 
 	Server struct {
@@ -27,11 +57,36 @@ type (
 		MaxConnectionAgeGrace time.Duration `env:"GRPC_MAX_CONNECTION_AGE_GRACE" envDefault:"10s"`
 	}
 
+	Redis struct {
+		Host         string        `env:"REDIS_HOSTS,notEmpty" envSeparator:","`
+		Password     string        `env:"REDIS_PASSWORD"`
+		TLSEnable    bool          `env:"REDIS_TLS_ENABLE" envDefault:"true"`
+		DialTimeout  time.Duration `env:"REDIS_DIAL_TIMEOUT" envDefault:"60s"`
+		WriteTimeout time.Duration `env:"REDIS_WRITE_TIMEOUT" envDefault:"60s"`
+		ReadTimeout  time.Duration `env:"REDIS_READ_TIMEOUT" envDefault:"30s"`
+	}
+
 	Nats struct {
 		Hosts        []string `env:"NATS_HOSTS,notEmpty" envSeparator:","`
 		NKey         string   `env:"NATS_NKEY" envDefault:"SUACSSL3UAHUDXKFSNVUZRF5UHPMWZ6BFDTJ7M6USDXIEDNPPQYYYCU3VY"`
 		IsTest       bool     `env:"NATS_IS_TEST,notEmpty" envDefault:"true"`
 		NatsSubjects NatsSubjects
+		JetStream    JetStream
+	}
+
+	JetStream struct {
+		OrderStream       string          `env:"NATS_JS_ORDER_STREAM" envDefault:"ORDERS"`
+		PaymentStream     string          `env:"NATS_JS_PAYMENT_STREAM" envDefault:"PAYMENTS"`
+		ProductStream     string          `env:"NATS_JS_PRODUCT_STREAM" envDefault:"PRODUCTS"`
+		CategoryStream    string          `env:"NATS_JS_CATEGORY_STREAM" envDefault:"CATEGORIES"`
+		ClickstreamStream string          `env:"NATS_JS_CLICKSTREAM_STREAM" envDefault:"CLICKSTREAM"`
+		DeadLetterStream  string          `env:"NATS_JS_DEAD_LETTER_STREAM" envDefault:"STATISTICS_DLQ"`
+		StreamMaxAge      time.Duration   `env:"NATS_JS_STREAM_MAX_AGE" envDefault:"168h"`
+		Durable           string          `env:"NATS_JS_DURABLE" envDefault:"statistics-service"`
+		AckWait           time.Duration   `env:"NATS_JS_ACK_WAIT" envDefault:"30s"`
+		MaxDeliver        int             `env:"NATS_JS_MAX_DELIVER" envDefault:"5"`
+		Backoff           []time.Duration `env:"NATS_JS_BACKOFF" envSeparator:"," envDefault:"1s,5s,30s,2m"`
+		DeadLetterSubject string          `env:"NATS_JS_DEAD_LETTER_SUBJECT" envDefault:"statistics.dlq"`
 	}
 
 	NatsSubjects struct {
@@ -39,11 +94,24 @@ type (
 		OrderUpdated string `env:"NATS_ORDER_UPDATED_SUBJECT,notEmpty"`
 		OrderDeleted string `env:"NATS_ORDER_DELETED_SUBJECT,notEmpty"`
 
+		PaymentCreated string `env:"NATS_PAYMENT_CREATED_SUBJECT,notEmpty" envDefault:"payment.created"`
+
 		ProductCreated string `env:"NATS_PRODUCT_CREATED_SUBJECT,notEmpty"`
 		ProductUpdated string `env:"NATS_PRODUCT_UPDATED_SUBJECT,notEmpty"`
 		ProductDeleted string `env:"NATS_PRODUCT_DELETED_SUBJECT,notEmpty"`
 
+		CategoryCreated string `env:"NATS_CATEGORY_CREATED_SUBJECT,notEmpty" envDefault:"category.created"`
+		CategoryUpdated string `env:"NATS_CATEGORY_UPDATED_SUBJECT,notEmpty" envDefault:"category.updated"`
+		CategoryDeleted string `env:"NATS_CATEGORY_DELETED_SUBJECT,notEmpty" envDefault:"category.deleted"`
+
 		UserRegistered string `env:"NATS_USER_REGISTERED_SUBJECT,notEmpty"`
+
+		// clickstream events of the API gateway, in JSON
+		CategoryBrowsed  string `env:"NATS_CATEGORY_BROWSED_SUBJECT,notEmpty" envDefault:"clickstream.category.browsed"`
+		ProductViewed    string `env:"NATS_PRODUCT_VIEWED_SUBJECT,notEmpty" envDefault:"clickstream.product.viewed"`
+		OrderPlaced      string `env:"NATS_ORDER_PLACED_SUBJECT,notEmpty" envDefault:"clickstream.order.created"`
+		PaymentAttempted string `env:"NATS_PAYMENT_ATTEMPTED_SUBJECT,notEmpty" envDefault:"clickstream.payment.attempted"`
+		PaymentCompleted string `env:"NATS_PAYMENT_COMPLETED_SUBJECT,notEmpty" envDefault:"clickstream.payment.completed"`
 	}
 )
 
@@ -2,10 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
 	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/usecase"
 	statisticspb "github.com/Neroframe/ecommerce-platform/statistics-service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type StatisticsHandler struct {
@@ -22,13 +28,19 @@ func NewStatisticsHandler(uc *usecase.StatisticsUsecase) *StatisticsHandler {
 func (h *StatisticsHandler) GetUserOrdersStatistics(ctx context.Context, req *statisticspb.UserOrderStatisticsRequest) (*statisticspb.UserOrderStatisticsResponse, error) {
 	log.Printf("[gRPC] GetUserOrdersStatistics called for user_id=%s", req.UserId)
 
-	resp, err := h.uc.GetUserOrdersStatistics(ctx, req.UserId)
+	resp, err := h.uc.GetUserOrdersStatistics(ctx, req.UserId, req.TimeZone)
 	if err != nil {
 		log.Printf("[gRPC] GetUserOrdersStatistics error: %v", err)
+		if errors.Is(err, domain.ErrInvalidTimeZone) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, err
 	}
-	log.Printf("[gRPC] GetUserOrdersStatistics result: total_orders=%d", resp.TotalOrders)
-	return &statisticspb.UserOrderStatisticsResponse{TotalOrders: resp.TotalOrders}, nil
+	log.Printf("[gRPC] GetUserOrdersStatistics result: total_orders=%d, peak_order_hour=%s", resp.TotalOrders, resp.PeakOrderHour)
+	return &statisticspb.UserOrderStatisticsResponse{
+		TotalOrders:   resp.TotalOrders,
+		PeakOrderHour: resp.PeakOrderHour,
+	}, nil
 }
 
 func (h *StatisticsHandler) GetUserStatistics(ctx context.Context, req *statisticspb.UserStatisticsRequest) (*statisticspb.UserStatisticsResponse, error) {
@@ -39,10 +51,339 @@ func (h *StatisticsHandler) GetUserStatistics(ctx context.Context, req *statisti
 		log.Printf("[gRPC] GetUserStatistics error: %v", err)
 		return nil, err
 	}
-	log.Printf("[gRPC] GetUserStatistics result: total_users=%d, daily_active_users=%d",
-		resp.TotalUsers, resp.DailyActiveUsers)
+	log.Printf("[gRPC] GetUserStatistics result: total_users=%d, daily_active_users=%d, weekly_active_users=%d, monthly_active_users=%d",
+		resp.TotalUsers, resp.DailyActiveUsers, resp.WeeklyActiveUsers, resp.MonthlyActiveUsers)
 	return &statisticspb.UserStatisticsResponse{
-		TotalUsers:       resp.TotalUsers,
-		DailyActiveUsers: resp.DailyActiveUsers,
+		TotalUsers:         resp.TotalUsers,
+		DailyActiveUsers:   resp.DailyActiveUsers,
+		WeeklyActiveUsers:  resp.WeeklyActiveUsers,
+		MonthlyActiveUsers: resp.MonthlyActiveUsers,
 	}, nil
 }
+
+func (h *StatisticsHandler) GetSalesStatistics(ctx context.Context, req *statisticspb.SalesStatisticsRequest) (*statisticspb.SalesStatisticsResponse, error) {
+	log.Printf("[gRPC] GetSalesStatistics called: granularity=%s currency=%s", req.Granularity, req.CurrencyCode)
+
+	q := domain.SalesQuery{Currency: req.CurrencyCode}
+	if req.From != nil {
+		q.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		q.To = req.To.AsTime()
+	}
+	switch req.Granularity {
+	case statisticspb.Granularity_GRANULARITY_UNSPECIFIED, statisticspb.Granularity_GRANULARITY_DAY:
+		q.Granularity = domain.GranularityDay
+	case statisticspb.Granularity_GRANULARITY_WEEK:
+		q.Granularity = domain.GranularityWeek
+	case statisticspb.Granularity_GRANULARITY_MONTH:
+		q.Granularity = domain.GranularityMonth
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown granularity %d", req.Granularity)
+	}
+
+	resp, err := h.uc.GetSalesStatistics(ctx, q)
+	if err != nil {
+		log.Printf("[gRPC] GetSalesStatistics error: %v", err)
+		if errors.Is(err, domain.ErrInvalidSalesQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	log.Printf("[gRPC] GetSalesStatistics result: %d periods", len(resp.Periods))
+	return resp, nil
+}
+
+func (h *StatisticsHandler) GetTopProducts(ctx context.Context, req *statisticspb.TopProductsRequest) (*statisticspb.TopProductsResponse, error) {
+	log.Printf("[gRPC] GetTopProducts called: metric=%s dimension=%s limit=%d currency=%s",
+		req.Metric, req.Dimension, req.Limit, req.CurrencyCode)
+
+	q := domain.TopQuery{Currency: req.CurrencyCode, Limit: int(req.Limit)}
+	if req.From != nil {
+		q.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		q.To = req.To.AsTime()
+	}
+	switch req.Metric {
+	case statisticspb.RankingMetric_RANKING_METRIC_UNSPECIFIED, statisticspb.RankingMetric_RANKING_METRIC_UNITS_SOLD:
+		q.Metric = domain.MetricUnits
+	case statisticspb.RankingMetric_RANKING_METRIC_REVENUE:
+		q.Metric = domain.MetricRevenue
+	case statisticspb.RankingMetric_RANKING_METRIC_ORDER_COUNT:
+		q.Metric = domain.MetricOrders
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown metric %d", req.Metric)
+	}
+	switch req.Dimension {
+	case statisticspb.RankingDimension_RANKING_DIMENSION_UNSPECIFIED, statisticspb.RankingDimension_RANKING_DIMENSION_PRODUCT:
+		q.Dimension = domain.DimensionProduct
+	case statisticspb.RankingDimension_RANKING_DIMENSION_CATEGORY:
+		q.Dimension = domain.DimensionCategory
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown dimension %d", req.Dimension)
+	}
+
+	resp, err := h.uc.GetTopProducts(ctx, q)
+	if err != nil {
+		log.Printf("[gRPC] GetTopProducts error: %v", err)
+		if errors.Is(err, domain.ErrInvalidTopQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	log.Printf("[gRPC] GetTopProducts result: %d entries", len(resp.Entries))
+	return resp, nil
+}
+
+func (h *StatisticsHandler) GetOrderHeatmap(ctx context.Context, req *statisticspb.OrderHeatmapRequest) (*statisticspb.OrderHeatmapResponse, error) {
+	log.Printf("[gRPC] GetOrderHeatmap called: user_id=%q time_zone=%s", req.UserId, req.TimeZone)
+
+	q := domain.HeatmapQuery{UserID: req.UserId, TimeZone: req.TimeZone}
+	if req.From != nil {
+		q.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		q.To = req.To.AsTime()
+	}
+
+	resp, err := h.uc.GetOrderHeatmap(ctx, q)
+	if err != nil {
+		log.Printf("[gRPC] GetOrderHeatmap error: %v", err)
+		if errors.Is(err, domain.ErrInvalidTimeZone) || errors.Is(err, domain.ErrInvalidHeatmapQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	log.Printf("[gRPC] GetOrderHeatmap result: total_orders=%d", resp.TotalOrders)
+	return resp, nil
+}
+
+func (h *StatisticsHandler) GetActiveUsers(ctx context.Context, req *statisticspb.ActiveUsersRequest) (*statisticspb.ActiveUsersResponse, error) {
+	log.Printf("[gRPC] GetActiveUsers called: mode=%s", req.Mode)
+
+	var q domain.ActiveUsersQuery
+	if req.From != nil {
+		q.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		q.To = req.To.AsTime()
+	}
+	switch req.Mode {
+	case statisticspb.CountMode_COUNT_MODE_AUTO:
+		q.Mode = domain.CountAuto
+	case statisticspb.CountMode_COUNT_MODE_EXACT:
+		q.Mode = domain.CountExact
+	case statisticspb.CountMode_COUNT_MODE_APPROXIMATE:
+		q.Mode = domain.CountApproximate
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown mode %d", req.Mode)
+	}
+
+	resp, err := h.uc.GetActiveUsers(ctx, q)
+	if err != nil {
+		log.Printf("[gRPC] GetActiveUsers error: %v", err)
+		if errors.Is(err, domain.ErrInvalidActiveUsersQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	log.Printf("[gRPC] GetActiveUsers result: active_users=%d exact=%t", resp.ActiveUsers, resp.Exact)
+	return resp, nil
+}
+
+func (h *StatisticsHandler) GetRetentionCohorts(ctx context.Context, req *statisticspb.RetentionCohortsRequest) (*statisticspb.RetentionCohortsResponse, error) {
+	log.Printf("[gRPC] GetRetentionCohorts called: granularity=%s periods=%d", req.Granularity, req.Periods)
+
+	q := domain.RetentionQuery{Periods: int(req.Periods)}
+	if req.From != nil {
+		q.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		q.To = req.To.AsTime()
+	}
+	switch req.Granularity {
+	case statisticspb.Granularity_GRANULARITY_UNSPECIFIED, statisticspb.Granularity_GRANULARITY_WEEK:
+		q.Granularity = domain.GranularityWeek
+	case statisticspb.Granularity_GRANULARITY_MONTH:
+		q.Granularity = domain.GranularityMonth
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "granularity must be week or month")
+	}
+
+	resp, err := h.uc.GetRetentionCohorts(ctx, q)
+	if err != nil {
+		log.Printf("[gRPC] GetRetentionCohorts error: %v", err)
+		if errors.Is(err, domain.ErrInvalidRetentionQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	log.Printf("[gRPC] GetRetentionCohorts result: %d cohorts", len(resp.Cohorts))
+	return resp, nil
+}
+
+func (h *StatisticsHandler) GetCustomerProfile(ctx context.Context, req *statisticspb.CustomerProfileRequest) (*statisticspb.CustomerProfileResponse, error) {
+	log.Printf("[gRPC] GetCustomerProfile called: user_id=%s", req.UserId)
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	resp, err := h.uc.GetCustomerProfile(ctx, req.UserId)
+	if err != nil {
+		log.Printf("[gRPC] GetCustomerProfile error: %v", err)
+		if errors.Is(err, domain.ErrCustomerNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+	log.Printf("[gRPC] GetCustomerProfile result: segment=%s", resp.Profile.Segment)
+	return resp, nil
+}
+
+var segmentNames = map[statisticspb.CustomerSegment]string{
+	statisticspb.CustomerSegment_CUSTOMER_SEGMENT_CHAMPIONS:       domain.SegmentChampions,
+	statisticspb.CustomerSegment_CUSTOMER_SEGMENT_LOYAL:           domain.SegmentLoyal,
+	statisticspb.CustomerSegment_CUSTOMER_SEGMENT_NEW:             domain.SegmentNew,
+	statisticspb.CustomerSegment_CUSTOMER_SEGMENT_PROMISING:       domain.SegmentPromising,
+	statisticspb.CustomerSegment_CUSTOMER_SEGMENT_NEEDS_ATTENTION: domain.SegmentNeedsAttention,
+	statisticspb.CustomerSegment_CUSTOMER_SEGMENT_AT_RISK:         domain.SegmentAtRisk,
+	statisticspb.CustomerSegment_CUSTOMER_SEGMENT_HIBERNATING:     domain.SegmentHibernating,
+}
+
+func (h *StatisticsHandler) ListSegmentMembers(ctx context.Context, req *statisticspb.SegmentMembersRequest) (*statisticspb.SegmentMembersResponse, error) {
+	log.Printf("[gRPC] ListSegmentMembers called: segment=%s limit=%d", req.Segment, req.Limit)
+
+	segment, ok := segmentNames[req.Segment]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown segment %d", req.Segment)
+	}
+	resp, err := h.uc.ListSegmentMembers(ctx, domain.SegmentQuery{
+		Segment: segment,
+		Limit:   int(req.Limit),
+		After:   req.PageToken,
+	})
+	if err != nil {
+		log.Printf("[gRPC] ListSegmentMembers error: %v", err)
+		if errors.Is(err, domain.ErrInvalidSegmentQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	log.Printf("[gRPC] ListSegmentMembers result: %d customers", len(resp.Customers))
+	return resp, nil
+}
+
+func (h *StatisticsHandler) GetRelatedProducts(ctx context.Context, req *statisticspb.RelatedProductsRequest) (*statisticspb.RelatedProductsResponse, error) {
+	log.Printf("[gRPC] GetRelatedProducts called: product_id=%s limit=%d", req.ProductId, req.Limit)
+
+	resp, err := h.uc.GetRelatedProducts(ctx, domain.RelatedQuery{
+		ProductID: req.ProductId,
+		Limit:     int(req.Limit),
+	})
+	if err != nil {
+		log.Printf("[gRPC] GetRelatedProducts error: %v", err)
+		if errors.Is(err, domain.ErrInvalidRelatedQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	log.Printf("[gRPC] GetRelatedProducts result: %d products", len(resp.Products))
+	return resp, nil
+}
+
+var funnelStepNames = map[statisticspb.FunnelStep]string{
+	statisticspb.FunnelStep_FUNNEL_STEP_CATEGORY_BROWSED:  domain.StepCategoryBrowsed,
+	statisticspb.FunnelStep_FUNNEL_STEP_PRODUCT_VIEWED:    domain.StepProductViewed,
+	statisticspb.FunnelStep_FUNNEL_STEP_ORDER_CREATED:     domain.StepOrderCreated,
+	statisticspb.FunnelStep_FUNNEL_STEP_PAYMENT_ATTEMPTED: domain.StepPaymentAttempted,
+	statisticspb.FunnelStep_FUNNEL_STEP_PAYMENT_COMPLETED: domain.StepPaymentCompleted,
+}
+
+func (h *StatisticsHandler) GetConversionFunnel(ctx context.Context, req *statisticspb.ConversionFunnelRequest) (*statisticspb.ConversionFunnelResponse, error) {
+	log.Printf("[gRPC] GetConversionFunnel called: steps=%v", req.Steps)
+
+	var q domain.FunnelQuery
+	if req.From != nil {
+		q.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		q.To = req.To.AsTime()
+	}
+	for _, s := range req.Steps {
+		step, ok := funnelStepNames[s]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown funnel step %d", s)
+		}
+		q.Steps = append(q.Steps, step)
+	}
+
+	resp, err := h.uc.GetConversionFunnel(ctx, q)
+	if err != nil {
+		log.Printf("[gRPC] GetConversionFunnel error: %v", err)
+		if errors.Is(err, domain.ErrInvalidFunnelQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+	log.Printf("[gRPC] GetConversionFunnel result: %d steps", len(resp.Steps))
+	return resp, nil
+}
+
+func (h *StatisticsHandler) WatchStatistics(req *statisticspb.WatchStatisticsRequest, stream statisticspb.StatisticsService_WatchStatisticsServer) error {
+	var minInterval time.Duration
+	if req.MinInterval != nil {
+		if err := req.MinInterval.CheckValid(); err != nil {
+			return status.Errorf(codes.InvalidArgument, "min_interval: %v", err)
+		}
+		minInterval = req.MinInterval.AsDuration()
+	}
+	log.Printf("[gRPC] WatchStatistics called: min_interval=%s", minInterval)
+
+	err := h.uc.WatchStatistics(stream.Context(), minInterval, stream.Send)
+	if ctxErr := stream.Context().Err(); ctxErr != nil {
+		log.Printf("[gRPC] WatchStatistics done: %v", ctxErr)
+		return status.FromContextError(ctxErr).Err()
+	}
+	log.Printf("[gRPC] WatchStatistics error: %v", err)
+	return err
+}
+
+var rebuildPhases = map[string]statisticspb.RebuildPhase{
+	domain.RebuildScan:    statisticspb.RebuildPhase_REBUILD_PHASE_SCAN,
+	domain.RebuildCatchUp: statisticspb.RebuildPhase_REBUILD_PHASE_CATCH_UP,
+	domain.RebuildSwap:    statisticspb.RebuildPhase_REBUILD_PHASE_SWAP,
+	domain.RebuildDone:    statisticspb.RebuildPhase_REBUILD_PHASE_DONE,
+}
+
+func (h *StatisticsHandler) RebuildRollups(req *statisticspb.RebuildRollupsRequest, stream statisticspb.StatisticsService_RebuildRollupsServer) error {
+	if req.From == nil || req.To == nil {
+		return status.Error(codes.InvalidArgument, "from and to are required")
+	}
+	from, to := req.From.AsTime(), req.To.AsTime()
+	log.Printf("[gRPC] RebuildRollups called: from=%s to=%s", from, to)
+
+	err := h.uc.RebuildRollups(stream.Context(), from, to, func(p domain.RebuildProgress) error {
+		msg := &statisticspb.RebuildRollupsProgress{
+			RebuildId:       p.ID,
+			Phase:           rebuildPhases[p.Phase],
+			EventsProcessed: p.Events,
+		}
+		if !p.LastEventTime.IsZero() {
+			msg.LastEventTime = timestamppb.New(p.LastEventTime)
+		}
+		return stream.Send(msg)
+	})
+	if err != nil {
+		log.Printf("[gRPC] RebuildRollups error: %v", err)
+		if errors.Is(err, domain.ErrInvalidRebuild) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, domain.ErrRollupsSwapping) {
+			return status.Error(codes.Aborted, err.Error())
+		}
+		return err
+	}
+	log.Println("[gRPC] RebuildRollups done")
+	return nil
+}
@@ -0,0 +1,325 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/config"
+	cache "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/inmemory"
+	natsadapter "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/nats"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/usecase"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/pkg/events"
+	eventspb "github.com/Neroframe/ecommerce-platform/statistics-service/proto/events"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+)
+
+// contractDir holds the messages inventory-service and order-service send,
+// written by their outbox publisher tests from the producer structs.
+const contractDir = "../../../../proto/events/testdata"
+
+// countingRepository keeps what the usecase writes while handling an event
+// in memory. The orders and rollups are read from the events the way
+// Repository reads them.
+type countingRepository struct {
+	domain.StatisticsRepository
+
+	events   map[string]bool
+	orders   map[string]*orderStateDoc
+	rollups  map[string]int64
+	sales    map[string]int64 // units sold by product
+	pairs    int64            // orders counted in the product pairs
+	products map[string]bool  // products and categories by ID, deleted or not
+	users    map[string]bool  // users whose customer was refreshed
+	swapping bool
+}
+
+func newCountingRepository() *countingRepository {
+	return &countingRepository{
+		events:   make(map[string]bool),
+		orders:   make(map[string]*orderStateDoc),
+		rollups:  make(map[string]int64),
+		sales:    make(map[string]int64),
+		products: make(map[string]bool),
+		users:    make(map[string]bool),
+	}
+}
+
+func (r *countingRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *countingRepository) RollupSwapLeased(context.Context) (bool, error) {
+	return r.swapping, nil
+}
+
+func (r *countingRepository) InsertEvent(_ context.Context, evt domain.Event) error {
+	if r.events[evt.EventID] {
+		return domain.ErrDuplicateEvent
+	}
+	r.events[evt.EventID] = true
+	return nil
+}
+
+func (r *countingRepository) ApplyOrderEvent(_ context.Context, evt domain.Event) (*domain.OrderState, error) {
+	doc := r.order(evt.EntityID)
+	switch evt.EventType {
+	case domain.EventOrderCreated:
+		created := createdOrder(evt)
+		if doc.SalesCounted || doc.PairsCounted {
+			created.Items = doc.Items
+		}
+		created.Status, created.StatusAt, created.Deleted = doc.Status, doc.StatusAt, doc.Deleted
+		created.SalesCounted, created.PairsCounted = doc.SalesCounted, doc.PairsCounted
+		*doc = created
+	case domain.EventOrderUpdated:
+		doc.Status, _ = evt.Data["status"].(string)
+		doc.StatusAt = evt.Timestamp.UTC()
+	case domain.EventOrderDeleted:
+		doc.Deleted = true
+	}
+	return toOrderState(*doc), nil
+}
+
+func (r *countingRepository) ApplyPaymentEvent(_ context.Context, evt domain.Event) (*domain.OrderState, error) {
+	orderID, _ := evt.Data["order_id"].(string)
+	if orderID == "" {
+		return nil, fmt.Errorf("payment %s has no order", evt.EntityID)
+	}
+	return toOrderState(*r.order(orderID)), nil
+}
+
+func (r *countingRepository) RefreshCustomer(_ context.Context, userID string) error {
+	r.users[userID] = true
+	return nil
+}
+
+func (r *countingRepository) CountProductSales(_ context.Context, state *domain.OrderState, remove bool) error {
+	sign := int64(1)
+	if remove {
+		sign = -1
+	}
+	for _, line := range state.Items {
+		r.sales[line.ProductID] += sign * line.Quantity
+	}
+	r.order(state.OrderID).SalesCounted = !remove
+	return nil
+}
+
+func (r *countingRepository) CountProductPairs(_ context.Context, state *domain.OrderState, remove bool) error {
+	if remove {
+		r.pairs--
+	} else {
+		r.pairs++
+	}
+	r.order(state.OrderID).PairsCounted = !remove
+	return nil
+}
+
+func (r *countingRepository) ApplyProductEvent(_ context.Context, evt domain.Event) error {
+	r.products[evt.EntityID] = evt.EventType != domain.EventProductDeleted
+	return nil
+}
+
+func (r *countingRepository) ApplyCategoryEvent(_ context.Context, evt domain.Event) error {
+	r.products[evt.EntityID] = evt.EventType != domain.EventCategoryDeleted
+	return nil
+}
+
+func (r *countingRepository) UpdateRollups(_ context.Context, evt domain.Event) error {
+	inc, err := rollupIncrements(evt)
+	if err != nil {
+		return err
+	}
+	for k, v := range inc {
+		r.rollups[k] += v
+	}
+	return nil
+}
+
+func (r *countingRepository) order(id string) *orderStateDoc {
+	doc, ok := r.orders[id]
+	if !ok {
+		doc = &orderStateDoc{OrderID: id}
+		r.orders[id] = doc
+	}
+	return doc
+}
+
+type noActiveUsers struct{}
+
+func (noActiveUsers) Add(context.Context, []domain.Event) error { return nil }
+
+func (noActiveUsers) Count(context.Context, time.Time, time.Time) (int64, error) { return 0, nil }
+
+// readContract reads a contract file as sent to NATS under the event ID id.
+// The producers write every contract with the same ID.
+func readContract(t *testing.T, enc, eventType, id string) *nats.Msg {
+	t.Helper()
+
+	path := filepath.Join(contractDir, enc, eventType+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	var cm struct {
+		Subject string            `json:"subject"`
+		Headers map[string]string `json:"headers"`
+		JSON    json.RawMessage   `json:"json"`
+		Data    []byte            `json:"data"`
+	}
+	if err := json.Unmarshal(data, &cm); err != nil {
+		t.Fatalf("unmarshal %s: %v", path, err)
+	}
+
+	msg := &nats.Msg{Subject: cm.Subject, Header: nats.Header{}, Data: cm.JSON}
+	for k, v := range cm.Headers {
+		msg.Header.Set(k, v)
+	}
+	msg.Header.Set(events.HeaderEventID, id)
+	if cm.JSON == nil {
+		var env eventspb.Envelope
+		if err := proto.Unmarshal(cm.Data, &env); err != nil {
+			t.Fatalf("unmarshal envelope of %s: %v", path, err)
+		}
+		env.EventId = id
+		if msg.Data, err = proto.Marshal(&env); err != nil {
+			t.Fatalf("marshal envelope of %s: %v", path, err)
+		}
+	}
+	return msg
+}
+
+// TestHandleProducerContracts hands what the producers send to the usecase,
+// the way the NATS consumers do, and checks what is counted from it.
+func TestHandleProducerContracts(t *testing.T) {
+	subjects := config.NatsSubjects{
+		OrderCreated:    "order.created",
+		OrderUpdated:    "order.updated",
+		OrderDeleted:    "order.deleted",
+		PaymentCreated:  "payment.created",
+		ProductCreated:  "product.created",
+		ProductUpdated:  "product.updated",
+		ProductDeleted:  "product.deleted",
+		CategoryCreated: "category.created",
+		CategoryUpdated: "category.updated",
+		CategoryDeleted: "category.deleted",
+	}
+
+	placed := map[string]int64{
+		"products_created":   1,
+		"products_updated":   1,
+		"categories_created": 1,
+		"categories_updated": 1,
+		"orders_created":     1,
+		"order_value.USD":    2248,
+		"payments":           1,
+		"revenue.USD":        2248,
+		"orders_updated":     1,
+	}
+	deleted := map[string]int64{"orders_deleted": 1, "products_deleted": 1, "categories_deleted": 1}
+	for k, v := range placed {
+		deleted[k] = v
+	}
+
+	steps := []struct {
+		name        string
+		events      []string
+		wantRollups map[string]int64
+		wantSales   map[string]int64
+		wantPairs   int64
+	}{
+		{
+			name: "placed",
+			events: []string{
+				"product.created", "product.updated", "category.created", "category.updated",
+				"order.created", "payment.created", "order.updated",
+			},
+			wantRollups: placed,
+			wantSales:   map[string]int64{"p1": 2, "p2": 1},
+			wantPairs:   1,
+		},
+		{
+			name:        "redelivered",
+			events:      []string{"order.created"},
+			wantRollups: placed,
+			wantSales:   map[string]int64{"p1": 2, "p2": 1},
+			wantPairs:   1,
+		},
+		{
+			name:        "deleted",
+			events:      []string{"order.deleted", "product.deleted", "category.deleted"},
+			wantRollups: deleted,
+			wantSales:   map[string]int64{"p1": 0, "p2": 0},
+			wantPairs:   0,
+		},
+	}
+
+	for _, enc := range []string{"json", "protobuf"} {
+		t.Run(enc, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newCountingRepository()
+			uc := usecase.NewStatisticsUsecase(repo, cache.NewInMemoryEventCache(), repo, noActiveUsers{},
+				domain.RFMThresholds{}, domain.WatchConfig{}, 1, "USD")
+			h := natsadapter.NewStatisticsHandler(uc, nil, subjects)
+
+			ids := make(map[string]string)
+			for _, step := range steps {
+				for _, eventType := range step.events {
+					id, ok := ids[eventType]
+					if !ok {
+						id = fmt.Sprintf("evt-%d", len(ids)+1)
+						ids[eventType] = id
+					}
+					if err := h.Replay(ctx, readContract(t, enc, eventType, id)); err != nil {
+						t.Fatalf("%s: Replay(%s): %v", step.name, eventType, err)
+					}
+				}
+
+				if !reflect.DeepEqual(repo.rollups, step.wantRollups) {
+					t.Errorf("%s: rollups = %v, want %v", step.name, repo.rollups, step.wantRollups)
+				}
+				if !reflect.DeepEqual(repo.sales, step.wantSales) {
+					t.Errorf("%s: units sold = %v, want %v", step.name, repo.sales, step.wantSales)
+				}
+				if repo.pairs != step.wantPairs {
+					t.Errorf("%s: orders in pairs = %d, want %d", step.name, repo.pairs, step.wantPairs)
+				}
+			}
+
+			order := repo.orders["o1"]
+			want := orderStateDoc{
+				OrderID:  "o1",
+				UserID:   "u1",
+				Status:   "Completed",
+				StatusAt: order.StatusAt,
+				Created:  true,
+				Deleted:  true,
+				PlacedAt: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC),
+				Total:    2248,
+				Currency: "USD",
+				Units:    3,
+				Items: []orderLineDoc{
+					{ProductID: "p1", Quantity: 2, UnitPrice: 499},
+					{ProductID: "p2", Quantity: 1, UnitPrice: 1250},
+				},
+			}
+			if !reflect.DeepEqual(*order, want) {
+				t.Errorf("order state = %+v, want %+v", *order, want)
+			}
+			if !repo.users["u1"] {
+				t.Error("customer u1 not refreshed")
+			}
+			if got := repo.products; !reflect.DeepEqual(got, map[string]bool{"p1": false, "c1": false}) {
+				t.Errorf("products and categories = %v, want p1 and c1 deleted", got)
+			}
+		})
+	}
+}
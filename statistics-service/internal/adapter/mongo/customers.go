@@ -0,0 +1,221 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// customerDoc keeps the money per currency code so that segments can filter
+// on paid.<currency>.
+type customerDoc struct {
+	UserID       string           `bson:"_id"`
+	Orders       int64            `bson:"orders"`
+	FirstOrderAt time.Time        `bson:"first_order_at,omitempty"`
+	LastOrderAt  time.Time        `bson:"last_order_at,omitempty"`
+	OrderValue   map[string]int64 `bson:"order_value"`
+	Paid         map[string]int64 `bson:"paid"`
+	UpdatedAt    time.Time        `bson:"updated_at"`
+}
+
+type paymentDoc struct {
+	PaymentID string `bson:"payment_id"`
+	Amount    int64  `bson:"amount"`
+	Currency  string `bson:"currency"`
+	Status    string `bson:"status"`
+}
+
+// ApplyPaymentEvent adds the payment to the payments of its order state,
+// creating the state if the order is not known yet.
+func (r *Repository) ApplyPaymentEvent(ctx context.Context, evt domain.Event) (*domain.OrderState, error) {
+	orderID, _ := evt.Data["order_id"].(string)
+	if orderID == "" {
+		return nil, fmt.Errorf("ApplyPaymentEvent: payment %s has no order", evt.EntityID)
+	}
+	amount, _ := moneyField(evt.Data, "amount")
+	status, _ := evt.Data["status"].(string)
+	payment := paymentDoc{
+		PaymentID: evt.EntityID,
+		Amount:    amount.Amount,
+		Currency:  amount.Currency,
+		Status:    status,
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var doc orderStateDoc
+	err := r.states.FindOneAndUpdate(ctx, bson.M{"_id": orderID},
+		bson.M{"$addToSet": bson.M{"payments": payment}}, opts).Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("ApplyPaymentEvent: %w", err)
+	}
+	return toOrderState(doc), nil
+}
+
+// RefreshCustomer reads all order states of the user and replaces their
+// customer document with the sums. Doing it over from the states keeps it
+// right whatever order the events came in.
+func (r *Repository) RefreshCustomer(ctx context.Context, userID string) error {
+	cur, err := r.states.Find(ctx, bson.M{"user_id": userID}, options.Find().SetProjection(bson.M{
+		"created": 1, "deleted": 1, "placed_at": 1, "total": 1, "currency": 1, "payments": 1,
+	}))
+	if err != nil {
+		return fmt.Errorf("RefreshCustomer: %w", err)
+	}
+	var states []struct {
+		Created  bool         `bson:"created"`
+		Deleted  bool         `bson:"deleted"`
+		PlacedAt time.Time    `bson:"placed_at"`
+		Total    int64        `bson:"total"`
+		Currency string       `bson:"currency"`
+		Payments []paymentDoc `bson:"payments"`
+	}
+	if err := cur.All(ctx, &states); err != nil {
+		return fmt.Errorf("RefreshCustomer: %w", err)
+	}
+
+	doc := customerDoc{
+		UserID:     userID,
+		OrderValue: map[string]int64{},
+		Paid:       map[string]int64{},
+		UpdatedAt:  time.Now().UTC(),
+	}
+	for _, s := range states {
+		for _, p := range s.Payments {
+			if p.Status == "Completed" && p.Currency != "" {
+				doc.Paid[p.Currency] += p.Amount
+			}
+		}
+		if !s.Created || s.Deleted {
+			continue
+		}
+		doc.Orders++
+		if doc.FirstOrderAt.IsZero() || s.PlacedAt.Before(doc.FirstOrderAt) {
+			doc.FirstOrderAt = s.PlacedAt
+		}
+		if s.PlacedAt.After(doc.LastOrderAt) {
+			doc.LastOrderAt = s.PlacedAt
+		}
+		if s.Currency != "" {
+			doc.OrderValue[s.Currency] += s.Total
+		}
+	}
+
+	_, err = r.customers.ReplaceOne(ctx, bson.M{"_id": userID}, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("RefreshCustomer: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetCustomer(ctx context.Context, userID string) (*domain.Customer, error) {
+	log.Printf("[Mongo] GetCustomer user_id=%s", userID)
+
+	var doc customerDoc
+	err := r.customers.FindOne(ctx, bson.M{"_id": userID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetCustomer: %w", err)
+	}
+	c := toCustomer(doc)
+	return &c, nil
+}
+
+// ListSegment filters the customers by the bounds of the segment, the same
+// rules as domain.RFMThresholds.Segment.
+func (r *Repository) ListSegment(ctx context.Context, q domain.SegmentQuery, t domain.RFMThresholds, now time.Time) ([]domain.Customer, error) {
+	log.Printf("[Mongo] ListSegment %s after %q limit %d", q.Segment, q.After, q.Limit)
+
+	filter, err := segmentFilter(q.Segment, t, now)
+	if err != nil {
+		return nil, fmt.Errorf("ListSegment: %w", err)
+	}
+	if q.After != "" {
+		filter = append(filter, bson.M{"_id": bson.M{"$gt": q.After}})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(q.Limit))
+	cur, err := r.customers.Find(ctx, bson.M{"$and": filter}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("ListSegment: %w", err)
+	}
+	var docs []customerDoc
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("ListSegment: %w", err)
+	}
+
+	out := make([]domain.Customer, len(docs))
+	for i, doc := range docs {
+		out[i] = toCustomer(doc)
+	}
+	log.Printf("[Mongo] ListSegment result: %d customers", len(out))
+	return out, nil
+}
+
+func segmentFilter(segment string, t domain.RFMThresholds, now time.Time) (bson.A, error) {
+	recentCut, lapsedCut := now.Add(-t.Recent), now.Add(-t.Lapsed)
+	var (
+		recent     = bson.M{"last_order_at": bson.M{"$gte": recentCut}}
+		midway     = bson.M{"last_order_at": bson.M{"$lt": recentCut, "$gte": lapsedCut}}
+		lapsed     = bson.M{"last_order_at": bson.M{"$lt": lapsedCut}}
+		frequent   = bson.M{"orders": bson.M{"$gte": t.FrequentOrders}}
+		occasional = bson.M{"orders": bson.M{"$lt": t.FrequentOrders}}
+		highValue  = bson.M{"paid." + t.Currency: bson.M{"$gte": t.HighValue}}
+		lowValue   = bson.M{"paid." + t.Currency: bson.M{"$not": bson.M{"$gte": t.HighValue}}}
+		withOrders = bson.M{"orders": bson.M{"$gte": 1}}
+		firstOrder = bson.M{"orders": 1}
+		moreOrders = bson.M{"orders": bson.M{"$gt": 1}}
+		conditions bson.A
+	)
+	switch segment {
+	case domain.SegmentChampions:
+		conditions = bson.A{recent, frequent, highValue}
+	case domain.SegmentLoyal:
+		conditions = bson.A{frequent, bson.M{"$or": bson.A{
+			bson.M{"$and": bson.A{recent, lowValue}},
+			midway,
+		}}}
+	case domain.SegmentNew:
+		conditions = bson.A{recent, firstOrder}
+	case domain.SegmentPromising:
+		conditions = bson.A{recent, moreOrders, occasional}
+	case domain.SegmentNeedsAttention:
+		conditions = bson.A{midway, occasional}
+	case domain.SegmentAtRisk:
+		conditions = bson.A{lapsed, bson.M{"$or": bson.A{frequent, highValue}}}
+	case domain.SegmentHibernating:
+		conditions = bson.A{lapsed, occasional, lowValue}
+	default:
+		return nil, fmt.Errorf("unknown segment %q", segment)
+	}
+	return append(bson.A{withOrders}, conditions...), nil
+}
+
+func toCustomer(doc customerDoc) domain.Customer {
+	return domain.Customer{
+		UserID:       doc.UserID,
+		Orders:       doc.Orders,
+		FirstOrderAt: doc.FirstOrderAt,
+		LastOrderAt:  doc.LastOrderAt,
+		OrderValue:   toMoneyList(doc.OrderValue),
+		Paid:         toMoneyList(doc.Paid),
+	}
+}
+
+// toMoneyList returns the amounts by currency code.
+func toMoneyList(amounts map[string]int64) []domain.Money {
+	out := make([]domain.Money, 0, len(amounts))
+	for currency, amount := range amounts {
+		out = append(out, domain.Money{Amount: amount, Currency: currency})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out
+}
@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ConversionFunnel pushes the event types of every visitor in the order they
+// happened and walks them with $reduce, moving on to the next step whenever
+// its event comes up, then counts the visitors by the number of steps they
+// got through.
+func (r *Repository) ConversionFunnel(ctx context.Context, q domain.FunnelQuery) ([]int64, error) {
+	log.Printf("[Mongo] ConversionFunnel %v from %s to %s", q.Steps, q.From, q.To)
+
+	types := make(bson.A, len(q.Steps))
+	for i, step := range q.Steps {
+		types[i] = domain.FunnelStepEvents[step]
+	}
+	cur, err := r.col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"event_type": bson.M{"$in": types},
+			"timestamp":  bson.M{"$gte": q.From, "$lt": q.To},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"$ifNull": bson.A{"$data.session_id", "$user_id"}},
+			"events": bson.M{"$push": "$event_type"},
+		}}},
+		{{Key: "$match", Value: bson.M{"_id": bson.M{"$nin": bson.A{"", nil}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$reduce": bson.M{
+				"input":        "$events",
+				"initialValue": 0,
+				"in": bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{"$$this", bson.M{"$arrayElemAt": bson.A{types, "$$value"}}}},
+					bson.M{"$add": bson.A{"$$value", 1}},
+					"$$value",
+				}},
+			}},
+			"visitors": bson.M{"$sum": 1},
+		}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("ConversionFunnel: %w", err)
+	}
+	var rows []struct {
+		Steps    int   `bson:"_id"`
+		Visitors int64 `bson:"visitors"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("ConversionFunnel: %w", err)
+	}
+
+	// a visitor through n steps counts for each of them
+	visitors := make([]int64, len(q.Steps))
+	for _, row := range rows {
+		for i := 0; i < row.Steps && i < len(visitors); i++ {
+			visitors[i] += row.Visitors
+		}
+	}
+	log.Printf("[Mongo] ConversionFunnel result: %v", visitors)
+	return visitors, nil
+}
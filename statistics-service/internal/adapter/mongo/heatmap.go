@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OrderHeatmap splits placed_at of the orders into ISO weekday and hour with
+// $dateToParts in the time zone of the query and counts the orders per cell.
+func (r *Repository) OrderHeatmap(ctx context.Context, q domain.HeatmapQuery) ([]domain.HeatmapCell, error) {
+	log.Printf("[Mongo] OrderHeatmap user_id=%q time_zone=%s", q.UserID, q.TimeZone)
+
+	match := bson.M{"created": true, "deleted": bson.M{"$ne": true}}
+	if q.UserID != "" {
+		match["user_id"] = q.UserID
+	}
+	placedAt := bson.M{"$exists": true}
+	if !q.From.IsZero() {
+		placedAt["$gte"] = q.From
+	}
+	if !q.To.IsZero() {
+		placedAt["$lt"] = q.To
+	}
+	match["placed_at"] = placedAt
+
+	cur, err := r.states.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.M{"parts": bson.M{"$dateToParts": bson.M{
+			"date":     "$placed_at",
+			"timezone": q.TimeZone,
+			"iso8601":  true,
+		}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"weekday": "$parts.isoDayOfWeek", "hour": "$parts.hour"},
+			"orders": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("OrderHeatmap: %w", err)
+	}
+	var rows []struct {
+		ID struct {
+			Weekday int `bson:"weekday"`
+			Hour    int `bson:"hour"`
+		} `bson:"_id"`
+		Orders int64 `bson:"orders"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("OrderHeatmap: %w", err)
+	}
+
+	cells := make([]domain.HeatmapCell, len(rows))
+	for i, row := range rows {
+		cells[i] = domain.HeatmapCell{Weekday: row.ID.Weekday, Hour: row.ID.Hour, Orders: row.Orders}
+	}
+	log.Printf("[Mongo] OrderHeatmap result: %d cells", len(cells))
+	return cells, nil
+}
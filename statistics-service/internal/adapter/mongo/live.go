@@ -0,0 +1,82 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LiveStatistics sums up the minute buckets before now and reads the day
+// bucket of now, then lists the products low on stock. It is called on
+// every push to a watcher, so it does not log.
+func (r *Repository) LiveStatistics(ctx context.Context, now time.Time, minutes int, threshold int64, limit int) (*domain.LiveStatistics, error) {
+	out := &domain.LiveStatistics{Minutes: minutes}
+	now = now.UTC()
+
+	minute, err := r.rollup(domain.RollupMinute)
+	if err != nil {
+		return nil, fmt.Errorf("LiveStatistics: %w", err)
+	}
+	to := now.Truncate(time.Minute)
+	cur, err := minute.col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": bson.M{"$gte": to.Add(-time.Duration(minutes) * time.Minute), "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "orders": bson.M{"$sum": "$orders_created"}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("LiveStatistics.minutes: %w", err)
+	}
+	var orders []struct {
+		Orders int64 `bson:"orders"`
+	}
+	if err := cur.All(ctx, &orders); err != nil {
+		return nil, fmt.Errorf("LiveStatistics.minutes: %w", err)
+	}
+	if len(orders) > 0 {
+		out.Orders = orders[0].Orders
+	}
+
+	day, err := r.rollup(domain.RollupDay)
+	if err != nil {
+		return nil, fmt.Errorf("LiveStatistics: %w", err)
+	}
+	var today struct {
+		ActiveUsers int64            `bson:"active_users"`
+		Revenue     map[string]int64 `bson:"revenue"`
+	}
+	err = day.col.FindOne(ctx, bson.M{"_id": now.Truncate(24 * time.Hour)}).Decode(&today)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("LiveStatistics.day: %w", err)
+	}
+	out.ActiveUsersToday = today.ActiveUsers
+	out.RevenueToday = toMoneyList(today.Revenue)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "stock", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"name": 1, "stock": 1})
+	cur, err = r.products.Find(ctx, bson.M{
+		"stock":   bson.M{"$lte": threshold},
+		"deleted": bson.M{"$ne": true},
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("LiveStatistics.stock: %w", err)
+	}
+	var products []struct {
+		ID    string `bson:"_id"`
+		Name  string `bson:"name"`
+		Stock int64  `bson:"stock"`
+	}
+	if err := cur.All(ctx, &products); err != nil {
+		return nil, fmt.Errorf("LiveStatistics.stock: %w", err)
+	}
+	for _, p := range products {
+		out.LowStock = append(out.LowStock, domain.LowStockProduct{ProductID: p.ID, Name: p.Name, Stock: p.Stock})
+	}
+	return out, nil
+}
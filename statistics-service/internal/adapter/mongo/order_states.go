@@ -0,0 +1,251 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type orderStateDoc struct {
+	OrderID  string    `bson:"_id"`
+	UserID   string    `bson:"user_id,omitempty"`
+	Status   string    `bson:"status,omitempty"`
+	StatusAt time.Time `bson:"status_at,omitempty"`
+	Created  bool      `bson:"created,omitempty"`
+	Deleted  bool      `bson:"deleted,omitempty"`
+	PlacedAt time.Time `bson:"placed_at,omitempty"`
+	Total    int64     `bson:"total,omitempty"`
+	Currency string    `bson:"currency,omitempty"`
+	Units    int64     `bson:"units,omitempty"`
+
+	Items        []orderLineDoc `bson:"items,omitempty"`
+	SalesCounted bool           `bson:"sales_counted,omitempty"`
+	PairsCounted bool           `bson:"pairs_counted,omitempty"`
+	PairsLimit   int            `bson:"pairs_limit,omitempty"`
+}
+
+type orderLineDoc struct {
+	ProductID  string `bson:"product_id"`
+	CategoryID string `bson:"category_id,omitempty"`
+	Quantity   int64  `bson:"quantity"`
+	UnitPrice  int64  `bson:"unit_price"`
+}
+
+// ApplyOrderEvent upserts the order state, so an update or delete that
+// overtook the create of its order is kept rather than lost. Every change is
+// a plain $set or only moves forward in time, which makes applying an event
+// again harmless.
+func (r *Repository) ApplyOrderEvent(ctx context.Context, evt domain.Event) (*domain.OrderState, error) {
+	ts := evt.Timestamp.UTC()
+
+	var update any
+	switch evt.EventType {
+	case domain.EventOrderCreated:
+		created := createdOrder(evt)
+		// counted items keep the categories and pairs they were counted in
+		update = mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"user_id":   created.UserID,
+			"created":   true,
+			"placed_at": created.PlacedAt,
+			"total":     created.Total,
+			"currency":  created.Currency,
+			"units":     created.Units,
+			"items": bson.M{"$cond": bson.A{
+				bson.M{"$or": bson.A{
+					bson.M{"$eq": bson.A{"$sales_counted", true}},
+					bson.M{"$eq": bson.A{"$pairs_counted", true}},
+				}},
+				"$items",
+				bson.M{"$literal": created.Items},
+			}},
+		}}}}
+
+	case domain.EventOrderUpdated:
+		status, _ := evt.Data["status"].(string)
+		// an update older than the stored status arrived late and is dropped
+		update = mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"status": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{ts, bson.M{"$ifNull": bson.A{"$status_at", time.Time{}}}}},
+				status,
+				"$status",
+			}},
+			"status_at": bson.M{"$max": bson.A{"$status_at", ts}},
+		}}}}
+
+	case domain.EventOrderDeleted:
+		update = bson.M{"$set": bson.M{"deleted": true, "deleted_at": ts}}
+
+	default:
+		return nil, fmt.Errorf("ApplyOrderEvent: not an order event: %s", evt.EventType)
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var doc orderStateDoc
+	if err := r.states.FindOneAndUpdate(ctx, bson.M{"_id": evt.EntityID}, update, opts).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ApplyOrderEvent: %w", err)
+	}
+	return toOrderState(doc), nil
+}
+
+func toOrderState(doc orderStateDoc) *domain.OrderState {
+	return &domain.OrderState{
+		OrderID:  doc.OrderID,
+		UserID:   doc.UserID,
+		Status:   doc.Status,
+		StatusAt: doc.StatusAt,
+		Created:  doc.Created,
+		Deleted:  doc.Deleted,
+		PlacedAt: doc.PlacedAt,
+		Total:    domain.Money{Amount: doc.Total, Currency: doc.Currency},
+		Units:    doc.Units,
+		Items:    toOrderLines(doc.Items),
+
+		SalesCounted: doc.SalesCounted,
+		PairsCounted: doc.PairsCounted,
+		PairsLimit:   doc.PairsLimit,
+	}
+}
+
+// createdOrder reads what an order created event as decoded by the NATS
+// adapter sets in the state of its order.
+func createdOrder(evt domain.Event) orderStateDoc {
+	total, _ := evt.Data["total"].(map[string]interface{})
+	amount, _ := total["amount"].(int64)
+	currency, _ := total["currency"].(string)
+	units, _ := evt.Data["units"].(int64)
+	return orderStateDoc{
+		OrderID:  evt.EntityID,
+		UserID:   evt.UserID,
+		Created:  true,
+		PlacedAt: evt.Timestamp.UTC(),
+		Total:    amount,
+		Currency: currency,
+		Units:    units,
+		Items:    orderLines(evt.Data),
+	}
+}
+
+// orderLines reads the items of an order created event as decoded by the
+// NATS adapter.
+func orderLines(data map[string]interface{}) []orderLineDoc {
+	items, _ := data["items"].([]interface{})
+	lines := make([]orderLineDoc, 0, len(items))
+	for _, it := range items {
+		item, _ := it.(map[string]interface{})
+		productID, _ := item["product_id"].(string)
+		quantity, _ := item["quantity"].(int32)
+		price, _ := item["unit_price"].(map[string]interface{})
+		amount, _ := price["amount"].(int64)
+		lines = append(lines, orderLineDoc{ProductID: productID, Quantity: int64(quantity), UnitPrice: amount})
+	}
+	return lines
+}
+
+func toOrderLines(docs []orderLineDoc) []domain.OrderLine {
+	lines := make([]domain.OrderLine, len(docs))
+	for i, d := range docs {
+		lines[i] = domain.OrderLine{
+			ProductID:  d.ProductID,
+			CategoryID: d.CategoryID,
+			Quantity:   d.Quantity,
+			UnitPrice:  d.UnitPrice,
+		}
+	}
+	return lines
+}
+
+// SalesByPeriod sums the orders placed from order_states and the payments
+// received from the hour rollups, each grouped by $dateTrunc. Revenue is
+// counted by the hour, from is rounded down to one.
+func (r *Repository) SalesByPeriod(ctx context.Context, q domain.SalesQuery) ([]domain.SalesPeriod, error) {
+	log.Printf("[Mongo] SalesByPeriod %s %s from %s to %s", q.Currency, q.Granularity, q.From, q.To)
+
+	truncate := func(field string) bson.M {
+		return bson.M{"$dateTrunc": bson.M{"date": field, "unit": q.Granularity, "startOfWeek": "monday"}}
+	}
+
+	periods := make(map[time.Time]*domain.SalesPeriod)
+	period := func(start time.Time) *domain.SalesPeriod {
+		start = start.UTC()
+		p, ok := periods[start]
+		if !ok {
+			p = &domain.SalesPeriod{Start: start}
+			periods[start] = p
+		}
+		return p
+	}
+
+	orders, err := r.states.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"created":   true,
+			"deleted":   bson.M{"$ne": true},
+			"currency":  q.Currency,
+			"placed_at": bson.M{"$gte": q.From, "$lt": q.To},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         truncate("$placed_at"),
+			"orders":      bson.M{"$sum": 1},
+			"order_value": bson.M{"$sum": "$total"},
+			"units":       bson.M{"$sum": "$units"},
+		}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SalesByPeriod.orders: %w", err)
+	}
+	var orderRows []struct {
+		Start      time.Time `bson:"_id"`
+		Orders     int64     `bson:"orders"`
+		OrderValue int64     `bson:"order_value"`
+		Units      int64     `bson:"units"`
+	}
+	if err := orders.All(ctx, &orderRows); err != nil {
+		return nil, fmt.Errorf("SalesByPeriod.orders: %w", err)
+	}
+	for _, row := range orderRows {
+		p := period(row.Start)
+		p.Orders, p.OrderValue, p.Units = row.Orders, row.OrderValue, row.Units
+	}
+
+	hours, err := r.rollup(domain.RollupHour)
+	if err != nil {
+		return nil, fmt.Errorf("SalesByPeriod: %w", err)
+	}
+	// the revenue of a bucket falls into the period of the hour it starts
+	payments, err := hours.col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"_id":                   bson.M{"$gte": q.From.Truncate(time.Hour), "$lt": q.To},
+			"revenue." + q.Currency: bson.M{"$exists": true},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     truncate("$_id"),
+			"revenue": bson.M{"$sum": "$revenue." + q.Currency},
+		}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SalesByPeriod.payments: %w", err)
+	}
+	var paymentRows []struct {
+		Start   time.Time `bson:"_id"`
+		Revenue int64     `bson:"revenue"`
+	}
+	if err := payments.All(ctx, &paymentRows); err != nil {
+		return nil, fmt.Errorf("SalesByPeriod.payments: %w", err)
+	}
+	for _, row := range paymentRows {
+		period(row.Start).Revenue = row.Revenue
+	}
+
+	out := make([]domain.SalesPeriod, 0, len(periods))
+	for _, p := range periods {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, nil
+}
@@ -0,0 +1,164 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxPairedProducts is the most distinct products of an order whose pairs
+// are counted. Bigger orders say little about what goes together and would
+// cost a write per pair in the transaction of the event, up to 380; they
+// still count for their products. Orders counted before the limit was kept
+// in their state were counted up to legacyPairedProducts.
+const (
+	maxPairedProducts    = 20
+	legacyPairedProducts = 50
+)
+
+// CountProductPairs increments product_pairs, one document per direction of
+// every pair so that the products bought with one are read by its ID, and
+// product_baskets, the orders of every product. Orders are counted once per
+// product whatever the quantity.
+func (r *Repository) CountProductPairs(ctx context.Context, state *domain.OrderState, remove bool) error {
+	log.Printf("[Mongo] CountProductPairs order_id=%s remove=%t", state.OrderID, remove)
+
+	seen := make(map[string]bool, len(state.Items))
+	var ids []string
+	for _, line := range state.Items {
+		if line.ProductID != "" && !seen[line.ProductID] {
+			seen[line.ProductID] = true
+			ids = append(ids, line.ProductID)
+		}
+	}
+	sort.Strings(ids)
+
+	sign := int64(1)
+	if remove {
+		sign = -1
+	}
+	if len(ids) > 0 {
+		baskets := make([]mongo.WriteModel, len(ids))
+		for i, id := range ids {
+			baskets[i] = mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": id}).
+				SetUpdate(bson.M{"$inc": bson.M{"orders": sign}}).
+				SetUpsert(true)
+		}
+		if _, err := r.productBaskets.BulkWrite(ctx, baskets, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("CountProductPairs.baskets: %w", err)
+		}
+	}
+	// taken out with the limit they were counted with
+	limit := maxPairedProducts
+	if remove {
+		limit = state.PairsLimit
+		if limit == 0 {
+			limit = legacyPairedProducts
+		}
+	}
+	if len(ids) > 1 && len(ids) <= limit {
+		pairs := make([]mongo.WriteModel, 0, len(ids)*(len(ids)-1))
+		for _, a := range ids {
+			for _, b := range ids {
+				if a == b {
+					continue
+				}
+				pairs = append(pairs, mongo.NewUpdateOneModel().
+					SetFilter(bson.M{"product_id": a, "related_id": b}).
+					SetUpdate(bson.M{"$inc": bson.M{"orders": sign}}).
+					SetUpsert(true))
+			}
+		}
+		if _, err := r.productPairs.BulkWrite(ctx, pairs, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("CountProductPairs.pairs: %w", err)
+		}
+	}
+
+	update := bson.M{"$set": bson.M{"pairs_counted": !remove, "pairs_limit": limit}}
+	if _, err := r.states.UpdateOne(ctx, bson.M{"_id": state.OrderID}, update); err != nil {
+		return fmt.Errorf("CountProductPairs.state: %w", err)
+	}
+	state.PairsCounted = !remove
+	state.PairsLimit = limit
+	return nil
+}
+
+// ProductPairs reads the pairs of the product with the most orders, ties
+// broken by ID, and the names and categories of the related products. The
+// orders counted are those whose state says so.
+func (r *Repository) ProductPairs(ctx context.Context, q domain.RelatedQuery) (*domain.ProductPairs, error) {
+	log.Printf("[Mongo] ProductPairs product_id=%s min_orders=%d limit=%d", q.ProductID, q.MinOrders, q.Limit)
+
+	out := &domain.ProductPairs{}
+	var product struct {
+		CategoryID string `bson:"category_id"`
+	}
+	err := r.products.FindOne(ctx, bson.M{"_id": q.ProductID}).Decode(&product)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("ProductPairs.product: %w", err)
+	}
+	out.CategoryID = product.CategoryID
+
+	var basket struct {
+		Orders int64 `bson:"orders"`
+	}
+	err = r.productBaskets.FindOne(ctx, bson.M{"_id": q.ProductID}).Decode(&basket)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("ProductPairs.baskets: %w", err)
+	}
+	out.Orders = basket.Orders
+
+	out.Baskets, err = r.states.CountDocuments(ctx, bson.M{"pairs_counted": true})
+	if err != nil {
+		return nil, fmt.Errorf("ProductPairs.count: %w", err)
+	}
+
+	cur, err := r.productPairs.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"product_id": q.ProductID,
+			"orders":     bson.M{"$gte": max(q.MinOrders, 1)},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "orders", Value: -1}, {Key: "related_id", Value: 1}}}},
+		{{Key: "$limit", Value: q.Limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         productsCollection,
+			"localField":   "related_id",
+			"foreignField": "_id",
+			"as":           "meta",
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"name":        bson.M{"$first": "$meta.name"},
+			"category_id": bson.M{"$first": "$meta.category_id"},
+		}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ProductPairs: %w", err)
+	}
+	var rows []struct {
+		RelatedID  string `bson:"related_id"`
+		Name       string `bson:"name"`
+		CategoryID string `bson:"category_id"`
+		Orders     int64  `bson:"orders"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("ProductPairs: %w", err)
+	}
+	for _, row := range rows {
+		out.Related = append(out.Related, domain.RelatedProduct{
+			ProductID:  row.RelatedID,
+			Name:       row.Name,
+			CategoryID: row.CategoryID,
+			Orders:     row.Orders,
+		})
+	}
+	log.Printf("[Mongo] ProductPairs result: %d related of %d orders", len(out.Related), out.Orders)
+	return out, nil
+}
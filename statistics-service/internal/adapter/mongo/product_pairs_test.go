@@ -0,0 +1,72 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// TestCountProductPairsLimit runs CountProductPairs against a mocked
+// deployment and checks which orders are counted in the pairs, and that an
+// order is taken out with the limit it was counted with.
+func TestCountProductPairsLimit(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	products := func(n int) []domain.OrderLine {
+		lines := make([]domain.OrderLine, n)
+		for i := range lines {
+			lines[i] = domain.OrderLine{ProductID: fmt.Sprintf("p%02d", i), Quantity: 1}
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name      string
+		products  int
+		remove    bool
+		limit     int
+		wantPairs int
+		wantLimit int
+	}{
+		{name: "at the limit", products: 20, wantPairs: 380, wantLimit: 20},
+		{name: "over the limit", products: 21, wantLimit: 20},
+		{name: "removed under the limit it was counted with", products: 20, remove: true, limit: 20, wantPairs: 380, wantLimit: 20},
+		{name: "removed over the limit it was counted with", products: 30, remove: true, limit: 20, wantLimit: 20},
+		{name: "removed under the legacy limit", products: 30, remove: true, wantPairs: 870, wantLimit: 50},
+		{name: "removed over the legacy limit", products: 51, remove: true, wantLimit: 50},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			ok := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1})
+			mt.AddMockResponses(ok, ok, ok)
+			repo := NewRepository(mt.DB)
+
+			state := &domain.OrderState{OrderID: "o1", Items: products(tt.products), PairsCounted: tt.remove, PairsLimit: tt.limit}
+			if err := repo.CountProductPairs(context.Background(), state, tt.remove); err != nil {
+				t.Fatalf("CountProductPairs: %v", err)
+			}
+
+			pairs := 0
+			for _, evt := range mt.GetAllStartedEvents() {
+				if evt.CommandName == "update" && evt.Command.Lookup("update").StringValue() == productPairsCollection {
+					docs, err := evt.Command.Lookup("updates").Array().Values()
+					if err != nil {
+						t.Fatalf("updates: %v", err)
+					}
+					pairs += len(docs)
+				}
+			}
+			if pairs != tt.wantPairs {
+				t.Errorf("%d pairs written, want %d", pairs, tt.wantPairs)
+			}
+			if state.PairsCounted == tt.remove || state.PairsLimit != tt.wantLimit {
+				t.Errorf("state counted %t with limit %d, want %t with limit %d", state.PairsCounted, state.PairsLimit, !tt.remove, tt.wantLimit)
+			}
+		})
+	}
+}
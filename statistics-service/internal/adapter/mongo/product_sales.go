@@ -0,0 +1,314 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// salesDelta is what one order adds to a daily sales document.
+type salesDelta struct {
+	units   int64
+	revenue int64
+}
+
+// CountProductSales increments the daily product_sales and category_sales
+// documents of the order, one per product and category, so that rankings
+// only have to sum up days. The categories are looked up when the order is
+// counted and kept in the order state, so that removing it takes it out of
+// the same categories even if a product moved since.
+func (r *Repository) CountProductSales(ctx context.Context, state *domain.OrderState, remove bool) error {
+	log.Printf("[Mongo] CountProductSales order_id=%s remove=%t", state.OrderID, remove)
+
+	lines := append([]domain.OrderLine(nil), state.Items...)
+	if !remove {
+		categories, err := r.productCategories(ctx, lines)
+		if err != nil {
+			return fmt.Errorf("CountProductSales: %w", err)
+		}
+		for i := range lines {
+			lines[i].CategoryID = categories[lines[i].ProductID]
+		}
+	}
+
+	sign := int64(1)
+	if remove {
+		sign = -1
+	}
+	day := state.PlacedAt.UTC().Truncate(24 * time.Hour)
+	currency := state.Total.Currency
+
+	products := make(map[string]*salesDelta)
+	categories := make(map[string]*salesDelta)
+	for _, line := range lines {
+		revenue := line.UnitPrice * line.Quantity
+		addDelta(products, line.ProductID, line.Quantity, revenue)
+		if line.CategoryID != "" {
+			addDelta(categories, line.CategoryID, line.Quantity, revenue)
+		}
+	}
+
+	if err := bulkIncSales(ctx, r.productSales, "product_id", day, currency, products, sign); err != nil {
+		return fmt.Errorf("CountProductSales.products: %w", err)
+	}
+	if err := bulkIncSales(ctx, r.categorySales, "category_id", day, currency, categories, sign); err != nil {
+		return fmt.Errorf("CountProductSales.categories: %w", err)
+	}
+
+	docs := make([]orderLineDoc, len(lines))
+	for i, line := range lines {
+		docs[i] = orderLineDoc{
+			ProductID:  line.ProductID,
+			CategoryID: line.CategoryID,
+			Quantity:   line.Quantity,
+			UnitPrice:  line.UnitPrice,
+		}
+	}
+	update := bson.M{"$set": bson.M{"items": docs, "sales_counted": !remove}}
+	if _, err := r.states.UpdateOne(ctx, bson.M{"_id": state.OrderID}, update); err != nil {
+		return fmt.Errorf("CountProductSales.state: %w", err)
+	}
+	state.Items = lines
+	state.SalesCounted = !remove
+	return nil
+}
+
+func addDelta(deltas map[string]*salesDelta, id string, units, revenue int64) {
+	d, ok := deltas[id]
+	if !ok {
+		d = &salesDelta{}
+		deltas[id] = d
+	}
+	d.units += units
+	d.revenue += revenue
+}
+
+// bulkIncSales applies the deltas to the documents of day, each entity
+// counting as one order.
+func bulkIncSales(ctx context.Context, col *mongo.Collection, key string, day time.Time, currency string, deltas map[string]*salesDelta, sign int64) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, 0, len(deltas))
+	for id, d := range deltas {
+		update := bson.M{"$inc": bson.M{
+			"units":   sign * d.units,
+			"revenue": sign * d.revenue,
+			"orders":  sign,
+		}}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"currency": currency, "day": day, key: id}).
+			SetUpdate(update).
+			SetUpsert(true))
+	}
+	_, err := col.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// categoryProducts returns the IDs of the products now in the category.
+func (r *Repository) categoryProducts(ctx context.Context, categoryID string) ([]string, error) {
+	cur, err := r.products.Find(ctx, bson.M{"category_id": categoryID, "deleted": bson.M{"$ne": true}},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(docs))
+	for i, d := range docs {
+		ids[i] = d.ID
+	}
+	return ids, nil
+}
+
+// productCategories returns the current category of the products of lines.
+func (r *Repository) productCategories(ctx context.Context, lines []domain.OrderLine) (map[string]string, error) {
+	ids := make([]string, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.ProductID)
+	}
+	cur, err := r.products.Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"category_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		ID         string `bson:"_id"`
+		CategoryID string `bson:"category_id"`
+	}
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	categories := make(map[string]string, len(docs))
+	for _, d := range docs {
+		categories[d.ID] = d.CategoryID
+	}
+	return categories, nil
+}
+
+// ApplyProductEvent keeps the latest name, category and stock of every
+// product; events from before the stock was sent leave it as it was.
+// An update older than the stored version leaves the product as it is, a
+// deleted product keeps its name for the rankings.
+func (r *Repository) ApplyProductEvent(ctx context.Context, evt domain.Event) error {
+	var update any
+	switch evt.EventType {
+	case domain.EventProductCreated, domain.EventProductUpdated:
+		name, _ := evt.Data["name"].(string)
+		categoryID, _ := evt.Data["category_id"].(string)
+		version, _ := evt.Data["version"].(int64)
+		// the upsert always matches the product, so the version is compared
+		// per field rather than in the filter where a stale event would
+		// insert a duplicate _id
+		newer := bson.M{"$gte": bson.A{version, bson.M{"$ifNull": bson.A{"$version", int64(0)}}}}
+		latest := func(field string, value any) bson.M {
+			return bson.M{"$cond": bson.A{newer, bson.M{"$literal": value}, "$" + field}}
+		}
+		set := bson.M{
+			"name":        latest("name", name),
+			"category_id": latest("category_id", categoryID),
+			"version":     latest("version", version),
+		}
+		if stock, ok := evt.Data["stock"].(int64); ok {
+			set["stock"] = latest("stock", stock)
+		}
+		update = mongo.Pipeline{{{Key: "$set", Value: set}}}
+
+	case domain.EventProductDeleted:
+		update = bson.M{"$set": bson.M{"deleted": true}}
+
+	default:
+		return fmt.Errorf("ApplyProductEvent: not a product event: %s", evt.EventType)
+	}
+
+	if _, err := r.products.UpdateOne(ctx, bson.M{"_id": evt.EntityID}, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("ApplyProductEvent: %w", err)
+	}
+	return nil
+}
+
+// ApplyCategoryEvent keeps the latest name of every category.
+func (r *Repository) ApplyCategoryEvent(ctx context.Context, evt domain.Event) error {
+	var update bson.M
+	switch evt.EventType {
+	case domain.EventCategoryCreated, domain.EventCategoryUpdated:
+		name, _ := evt.Data["name"].(string)
+		parentID, _ := evt.Data["parent_id"].(string)
+		update = bson.M{"$set": bson.M{"name": name, "parent_id": parentID}}
+
+	case domain.EventCategoryDeleted:
+		update = bson.M{"$set": bson.M{"deleted": true}}
+
+	default:
+		return fmt.Errorf("ApplyCategoryEvent: not a category event: %s", evt.EventType)
+	}
+
+	if _, err := r.categories.UpdateOne(ctx, bson.M{"_id": evt.EntityID}, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("ApplyCategoryEvent: %w", err)
+	}
+	return nil
+}
+
+// TopProducts sums up the daily sales of the window per product or category
+// and ranks them by the metric, ties broken by ID.
+func (r *Repository) TopProducts(ctx context.Context, q domain.TopQuery) ([]domain.TopEntry, error) {
+	log.Printf("[Mongo] TopProducts by %s of %s in %s from %s to %s", q.Metric, q.Dimension, q.Currency, q.From, q.To)
+
+	col, key, names := r.productSales, "product_id", productsCollection
+	if q.Dimension == domain.DimensionCategory {
+		col, key, names = r.categorySales, "category_id", categoriesCollection
+	}
+
+	var sortField string
+	switch q.Metric {
+	case domain.MetricUnits:
+		sortField = "units"
+	case domain.MetricRevenue:
+		sortField = "revenue"
+	case domain.MetricOrders:
+		sortField = "orders"
+	default:
+		return nil, fmt.Errorf("TopProducts: unknown metric %q", q.Metric)
+	}
+
+	match := bson.M{
+		"currency": q.Currency,
+		"day":      bson.M{"$gte": q.From, "$lt": q.To},
+	}
+	ids := bson.M{}
+	if q.CategoryID != "" && q.Dimension == domain.DimensionProduct {
+		inCategory, err := r.categoryProducts(ctx, q.CategoryID)
+		if err != nil {
+			return nil, fmt.Errorf("TopProducts: %w", err)
+		}
+		ids["$in"] = inCategory
+	}
+	if len(q.Exclude) > 0 {
+		ids["$nin"] = q.Exclude
+	}
+	if len(ids) > 0 {
+		match[key] = ids
+	}
+
+	cur, err := col.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$" + key,
+			"units":   bson.M{"$sum": "$units"},
+			"revenue": bson.M{"$sum": "$revenue"},
+			"orders":  bson.M{"$sum": "$orders"},
+		}}},
+		// removed orders leave their documents at zero
+		{{Key: "$match", Value: bson.M{"orders": bson.M{"$gt": 0}}}},
+		{{Key: "$sort", Value: bson.D{{Key: sortField, Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: q.Limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         names,
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "meta",
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"name":        bson.M{"$first": "$meta.name"},
+			"category_id": bson.M{"$first": "$meta.category_id"},
+		}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("TopProducts: %w", err)
+	}
+	var rows []struct {
+		ID         string `bson:"_id"`
+		Name       string `bson:"name"`
+		CategoryID string `bson:"category_id"`
+		Units      int64  `bson:"units"`
+		Revenue    int64  `bson:"revenue"`
+		Orders     int64  `bson:"orders"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("TopProducts: %w", err)
+	}
+
+	out := make([]domain.TopEntry, len(rows))
+	for i, row := range rows {
+		out[i] = domain.TopEntry{
+			ID:         row.ID,
+			Name:       row.Name,
+			CategoryID: row.CategoryID,
+			Units:      row.Units,
+			Revenue:    row.Revenue,
+			Orders:     row.Orders,
+		}
+	}
+	log.Printf("[Mongo] TopProducts result: %d entries", len(out))
+	return out, nil
+}
@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,76 +11,172 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-const eventsCollection = "statistics_events"
+const (
+	eventsCollection         = "statistics_events"
+	orderStatesCollection    = "order_states"
+	productSalesCollection   = "product_sales"
+	categorySalesCollection  = "category_sales"
+	productsCollection       = "products"
+	categoriesCollection     = "categories"
+	customersCollection      = "customers"
+	productPairsCollection   = "product_pairs"
+	productBasketsCollection = "product_baskets"
+)
 
 var _ domain.StatisticsRepository = (*Repository)(nil)
 
 type Repository struct {
-	col *mongo.Collection
+	col            *mongo.Collection
+	states         *mongo.Collection
+	productSales   *mongo.Collection
+	categorySales  *mongo.Collection
+	products       *mongo.Collection
+	categories     *mongo.Collection
+	users          *mongo.Collection
+	rollupUsers    *mongo.Collection
+	customers      *mongo.Collection
+	productPairs   *mongo.Collection
+	productBaskets *mongo.Collection
+	leases         *mongo.Collection
+	rollups        []rollup
+	db             *mongo.Database
 }
 
-func NewRepository(db *mongo.Database) domain.StatisticsRepository {
+func NewRepository(db *mongo.Database) *Repository {
 	return &Repository{
-		col: db.Collection(eventsCollection),
+		col:            db.Collection(eventsCollection),
+		states:         db.Collection(orderStatesCollection),
+		productSales:   db.Collection(productSalesCollection),
+		categorySales:  db.Collection(categorySalesCollection),
+		products:       db.Collection(productsCollection),
+		categories:     db.Collection(categoriesCollection),
+		users:          db.Collection(usersCollection),
+		rollupUsers:    db.Collection(rollupUsersCollection),
+		customers:      db.Collection(customersCollection),
+		productPairs:   db.Collection(productPairsCollection),
+		productBaskets: db.Collection(productBasketsCollection),
+		leases:         db.Collection(leasesCollection),
+		rollups:        newRollups(db, ""),
+		db:             db,
 	}
 }
 
+// EnsureIndexes creates the unique index on event IDs that drops redelivered
+// events, events stored before they had IDs are left out of it, the index
+// the funnels read the events of a type by, the index on the orders of a
+// user, the unique keys of the daily sales and of the product pairs, the
+// indexes the pairs are read by and the indexes of the rollups.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "event_id", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"event_id": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "event_type", Value: 1}, {Key: "timestamp", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.states.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "placed_at", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.states.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "pairs_counted", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"pairs_counted": true}),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.productPairs.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "related_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "orders", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.products.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "category_id", Value: 1}}},
+		{Keys: bson.D{{Key: "stock", Value: 1}, {Key: "_id", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	for col, key := range map[*mongo.Collection]string{r.productSales: "product_id", r.categorySales: "category_id"} {
+		_, err := col.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "currency", Value: 1}, {Key: "day", Value: 1}, {Key: key, Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return r.ensureRollupIndexes(ctx)
+}
+
 // gRPC methods
 func (r *Repository) CountOrdersByUser(ctx context.Context, userID string) (int32, error) {
 	log.Printf("[Mongo] Counting orders for user_id=%s", userID)
-	filter := bson.M{"user_id": userID, "event_type": "order_created"}
-	count, err := r.col.CountDocuments(ctx, filter)
-	if err != nil {
+	var doc struct {
+		Orders int32 `bson:"orders"`
+	}
+	err := r.users.FindOne(ctx, bson.M{"_id": userID}).Decode(&doc)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
 		return 0, fmt.Errorf("CountOrdersByUser: %w", err)
 	}
-	log.Printf("[Mongo] CountOrdersByUser result: %d", count)
-	return int32(count), nil
+	log.Printf("[Mongo] CountOrdersByUser result: %d", doc.Orders)
+	return doc.Orders, nil
 }
 
 func (r *Repository) CountTotalUsers(ctx context.Context) (int32, error) {
 	log.Println("[Mongo] Counting total users")
 
-	filter := bson.M{"event_type": bson.M{"$in": []string{
-		"order_created", "order_updated", "product_created", "product_updated",
-	}}}
-
-	ids, err := r.col.Distinct(ctx, "user_id", filter)
+	n, err := r.users.CountDocuments(ctx, bson.D{})
 	if err != nil {
 		return 0, fmt.Errorf("CountTotalUsers: %w", err)
 	}
-	log.Printf("[Mongo] Total unique users: %d", len(ids))
-	return int32(len(ids)), nil
+	log.Printf("[Mongo] Total unique users: %d", n)
+	return int32(n), nil
 }
 
 func (r *Repository) CountDailyActiveUsers(ctx context.Context) (int32, error) {
-	since := time.Now().Add(-24 * time.Hour)
-	log.Printf("[Mongo] Counting daily active users since %v", since)
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.M{"timestamp": bson.M{"$gte": since}}}},
-		{{Key: "$group", Value: bson.M{"_id": "$user_id"}}},
-		{{Key: "$count", Value: "activeUsers"}},
+	since := time.Now().UTC().Add(-24 * time.Hour).Truncate(time.Hour)
+	n, err := r.countActiveUsers(ctx, domain.RollupHour, since, time.Time{})
+	if err != nil {
+		return 0, fmt.Errorf("CountDailyActiveUsers: %w", err)
 	}
+	log.Printf("[Mongo] Daily active users: %d", n)
+	return int32(n), nil
+}
 
-	cur, err := r.col.Aggregate(ctx, pipeline)
+func (r *Repository) CountActiveUsers(ctx context.Context, granularity string, from, to time.Time) (int64, error) {
+	ru, err := r.rollup(granularity)
 	if err != nil {
-		return 0, fmt.Errorf("CountDailyActiveUsers.Aggregate: %w", err)
+		return 0, fmt.Errorf("CountActiveUsers: %w", err)
 	}
-	defer cur.Close(ctx)
-
-	var res struct {
-		ActiveUsers int32 `bson:"activeUsers"`
+	// the markers of a bucket expire rollupUsersRetention after it ends
+	if !from.Add(ru.size + rollupUsersRetention).After(time.Now()) {
+		return 0, fmt.Errorf("CountActiveUsers: %w", domain.ErrActiveUsersExpired)
 	}
-	if cur.Next(ctx) {
-		if err := cur.Decode(&res); err != nil {
-			return 0, fmt.Errorf("CountDailyActiveUsers.Decode: %w", err)
-		}
+	n, err := r.countActiveUsers(ctx, granularity, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("CountActiveUsers: %w", err)
 	}
-
-	log.Printf("[Mongo] Daily active users: %d", res.ActiveUsers)
-	return res.ActiveUsers, nil
+	log.Printf("[Mongo] Active users of %s buckets from %s to %s: %d", granularity, from, to, n)
+	return n, nil
 }
 
 // NATS methods
@@ -92,25 +189,61 @@ func (r *Repository) InsertEvent(ctx context.Context, evt domain.Event) error {
 		"timestamp":  evt.Timestamp,
 		"data": evt.Data,
 	}
+	if evt.EventID != "" {
+		doc["event_id"] = evt.EventID
+	}
 
 	_, err := r.col.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateEvent
+	}
 	if err != nil {
 		return fmt.Errorf("mongo insert event: %w", err)
 	}
 	return nil
 }
 
-func (r *Repository) ListEvents(ctx context.Context) ([]domain.Event, error) {
-	cur, err := r.col.Find(ctx, bson.D{}) 
+func (r *Repository) ListEvents(ctx context.Context, f domain.EventFilter, batchSize int, fn func(evts []domain.Event, lastID string) error) error {
+	filter := bson.M{}
+	timestamp := bson.M{}
+	if !f.From.IsZero() {
+		timestamp["$gte"] = f.From
+	}
+	if !f.To.IsZero() {
+		timestamp["$lt"] = f.To
+	}
+	if len(timestamp) > 0 {
+		filter["timestamp"] = timestamp
+	}
+	// ObjectIDs start with the time they were made at, in seconds
+	id := bson.M{}
+	if f.AfterID != "" {
+		after, err := primitive.ObjectIDFromHex(f.AfterID)
+		if err != nil {
+			return fmt.Errorf("ListEvents: after id: %w", err)
+		}
+		id["$gt"] = after
+	}
+	if !f.StoredBefore.IsZero() {
+		id["$lt"] = primitive.NewObjectIDFromTimestamp(f.StoredBefore)
+	}
+	if len(id) > 0 {
+		filter["_id"] = id
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetBatchSize(int32(batchSize))
+	cur, err := r.col.Find(ctx, filter, opts)
 	if err != nil {
-		return nil, fmt.Errorf("mongo find events: %w", err)
+		return fmt.Errorf("mongo find events: %w", err)
 	}
 	defer cur.Close(ctx)
 
-	var out []domain.Event
+	batch := make([]domain.Event, 0, batchSize)
+	var lastID string
 	for cur.Next(ctx) {
 		var doc struct {
 			ID        primitive.ObjectID     `bson:"_id"`
+			EventID   string                 `bson:"event_id,omitempty"`
 			UserID    string                 `bson:"user_id"`
 			EntityKey string                 `bson:"entity_key"`
 			EntityID  string                 `bson:"entity_id"`
@@ -119,10 +252,11 @@ func (r *Repository) ListEvents(ctx context.Context) ([]domain.Event, error) {
 			Data      map[string]interface{} `bson:"data,omitempty"`
 		}
 		if err := cur.Decode(&doc); err != nil {
-			return nil, fmt.Errorf("decode event doc: %w", err)
+			return fmt.Errorf("decode event doc: %w", err)
 		}
 
-		out = append(out, domain.Event{
+		batch = append(batch, domain.Event{
+			EventID:   doc.EventID,
 			UserID:    doc.UserID,
 			EntityKey: doc.EntityKey,
 			EntityID:  doc.EntityID,
@@ -130,9 +264,19 @@ func (r *Repository) ListEvents(ctx context.Context) ([]domain.Event, error) {
 			Timestamp: doc.Timestamp,
 			Data:      doc.Data,
 		})
+		lastID = doc.ID.Hex()
+		if len(batch) == batchSize {
+			if err := fn(batch, lastID); err != nil {
+				return err
+			}
+			batch = make([]domain.Event, 0, batchSize)
+		}
 	}
 	if err := cur.Err(); err != nil {
-		return nil, fmt.Errorf("cursor error: %w", err)
+		return fmt.Errorf("cursor error: %w", err)
 	}
-	return out, nil
+	if len(batch) > 0 {
+		return fn(batch, lastID)
+	}
+	return nil
 }
@@ -0,0 +1,70 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RetentionCohorts truncates placed_at of the orders to their period, groups
+// the periods by user, the earliest being the cohort, and counts the users
+// per cohort and number of periods since it with $dateDiff.
+func (r *Repository) RetentionCohorts(ctx context.Context, q domain.RetentionQuery) ([]domain.RetentionCell, error) {
+	log.Printf("[Mongo] RetentionCohorts %s from %s to %s, %d periods", q.Granularity, q.From, q.To, q.Periods)
+
+	cur, err := r.states.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"created":   true,
+			"deleted":   bson.M{"$ne": true},
+			"user_id":   bson.M{"$nin": bson.A{"", nil}},
+			"placed_at": bson.M{"$exists": true},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": "$user_id",
+			"periods": bson.M{"$addToSet": bson.M{"$dateTrunc": bson.M{
+				"date":        "$placed_at",
+				"unit":        q.Granularity,
+				"startOfWeek": "monday",
+			}}},
+		}}},
+		{{Key: "$set", Value: bson.M{"cohort": bson.M{"$min": "$periods"}}}},
+		{{Key: "$match", Value: bson.M{"cohort": bson.M{"$gte": q.From, "$lt": q.To}}}},
+		{{Key: "$unwind", Value: "$periods"}},
+		{{Key: "$set", Value: bson.M{"offset": bson.M{"$dateDiff": bson.M{
+			"startDate":   "$cohort",
+			"endDate":     "$periods",
+			"unit":        q.Granularity,
+			"startOfWeek": "monday",
+		}}}}},
+		{{Key: "$match", Value: bson.M{"offset": bson.M{"$lte": q.Periods}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"cohort": "$cohort", "offset": "$offset"},
+			"users": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("RetentionCohorts: %w", err)
+	}
+	var rows []struct {
+		ID struct {
+			Cohort time.Time `bson:"cohort"`
+			Offset int       `bson:"offset"`
+		} `bson:"_id"`
+		Users int64 `bson:"users"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("RetentionCohorts: %w", err)
+	}
+
+	cells := make([]domain.RetentionCell, len(rows))
+	for i, row := range rows {
+		cells[i] = domain.RetentionCell{Cohort: row.ID.Cohort.UTC(), Offset: row.ID.Offset, Users: row.Users}
+	}
+	log.Printf("[Mongo] RetentionCohorts result: %d cells", len(cells))
+	return cells, nil
+}
@@ -0,0 +1,295 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// A rebuild writes its rollups and active user markers to collections of
+// its own, rebuild_<id>_rollup_minute and so on, next to the live ones.
+
+// The swap lease is a single document of the leases collection, held by the
+// rebuild whose ID is its holder until it expires.
+const (
+	leasesCollection = "leases"
+	rollupSwapLease  = "rollup_swap"
+)
+
+func rebuildPrefix(id string) string {
+	return "rebuild_" + id + "_"
+}
+
+func (r *Repository) rebuildUsers(id string) *mongo.Collection {
+	return r.db.Collection(rebuildPrefix(id) + rollupUsersCollection)
+}
+
+type bucketKey struct {
+	rollup int
+	start  time.Time
+}
+
+type markerKey struct {
+	bucketKey
+	userID string
+}
+
+// AddToRollupBuild counts a batch of events like UpdateRollups, summing up
+// the batch per bucket first. New users are left to SwapRollupBuild.
+func (r *Repository) AddToRollupBuild(ctx context.Context, id string, evts []domain.Event) error {
+	rollups := newRollups(r.db, rebuildPrefix(id))
+
+	incs := make(map[bucketKey]map[string]int64)
+	var (
+		markers    []mongo.WriteModel
+		markerKeys []bucketKey
+		seen       = make(map[markerKey]bool)
+	)
+	for _, evt := range evts {
+		counters, err := rollupIncrements(evt)
+		if err != nil {
+			return fmt.Errorf("AddToRollupBuild: %w", err)
+		}
+		ts := evt.Timestamp.UTC()
+		for i, ru := range rollups {
+			key := bucketKey{rollup: i, start: ts.Truncate(ru.size)}
+			inc, ok := incs[key]
+			if !ok {
+				inc = make(map[string]int64)
+				incs[key] = inc
+			}
+			for k, v := range counters {
+				inc[k] += v
+			}
+
+			mk := markerKey{key, evt.UserID}
+			if evt.UserID == "" || seen[mk] {
+				continue
+			}
+			seen[mk] = true
+			markers = append(markers, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"granularity": ru.granularity, "start": key.start, "user_id": evt.UserID}).
+				SetUpdate(bson.M{"$setOnInsert": bson.M{"expire_at": key.start.Add(ru.size + rollupUsersRetention)}}).
+				SetUpsert(true))
+			markerKeys = append(markerKeys, key)
+		}
+	}
+
+	if len(markers) > 0 {
+		res, err := r.rebuildUsers(id).BulkWrite(ctx, markers, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return fmt.Errorf("AddToRollupBuild.users: %w", err)
+		}
+		for i := range res.UpsertedIDs {
+			incs[markerKeys[i]]["active_users"]++
+		}
+	}
+
+	models := make([][]mongo.WriteModel, len(rollups))
+	for key, inc := range incs {
+		update := bson.M{}
+		for k, v := range inc {
+			update[k] = v
+		}
+		models[key.rollup] = append(models[key.rollup], mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": key.start}).
+			SetUpdate(bson.M{"$inc": update}).
+			SetUpsert(true))
+	}
+	for i, ru := range rollups {
+		if len(models[i]) == 0 {
+			continue
+		}
+		if _, err := ru.col.BulkWrite(ctx, models[i], options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("AddToRollupBuild.%s: %w", ru.granularity, err)
+		}
+	}
+	return nil
+}
+
+// SwapRollupBuild deletes the live buckets and active user markers from from
+// to to and inserts those of the rebuild in the same range. New users are
+// counted from the users collection by the time they were first seen, the
+// same way UpdateRollups counts them. It is meant for a day at a time, to
+// keep the transaction it runs in small.
+func (r *Repository) SwapRollupBuild(ctx context.Context, id string, from, to time.Time) error {
+	log.Printf("[Mongo] SwapRollupBuild id=%s from %s to %s", id, from, to)
+
+	rebuilt := newRollups(r.db, rebuildPrefix(id))
+	for i, ru := range r.rollups {
+		buckets, err := readBuckets(ctx, rebuilt[i].col, from, to)
+		if err != nil {
+			return fmt.Errorf("SwapRollupBuild.%s: %w", ru.granularity, err)
+		}
+		newUsers, err := r.newUsersByBucket(ctx, ru.granularity, from, to)
+		if err != nil {
+			return fmt.Errorf("SwapRollupBuild.%s new users: %w", ru.granularity, err)
+		}
+		for start, n := range newUsers {
+			b, ok := buckets[start]
+			if !ok {
+				b = bson.M{"_id": start}
+				buckets[start] = b
+			}
+			b["new_users"] = n
+		}
+
+		if _, err := ru.col.DeleteMany(ctx, bson.M{"_id": bson.M{"$gte": from, "$lt": to}}); err != nil {
+			return fmt.Errorf("SwapRollupBuild.%s delete: %w", ru.granularity, err)
+		}
+		if len(buckets) == 0 {
+			continue
+		}
+		docs := make([]interface{}, 0, len(buckets))
+		for _, b := range buckets {
+			docs = append(docs, b)
+		}
+		if _, err := ru.col.InsertMany(ctx, docs); err != nil {
+			return fmt.Errorf("SwapRollupBuild.%s insert: %w", ru.granularity, err)
+		}
+	}
+
+	markers := bson.M{"start": bson.M{"$gte": from, "$lt": to}}
+	if _, err := r.rollupUsers.DeleteMany(ctx, markers); err != nil {
+		return fmt.Errorf("SwapRollupBuild.users delete: %w", err)
+	}
+	// markers the TTL monitor would remove anyway are left behind
+	cur, err := r.rebuildUsers(id).Find(ctx, bson.M{
+		"start":     bson.M{"$gte": from, "$lt": to},
+		"expire_at": bson.M{"$gt": time.Now()},
+	},
+		options.Find().SetProjection(bson.M{"_id": 0}))
+	if err != nil {
+		return fmt.Errorf("SwapRollupBuild.users: %w", err)
+	}
+	var docs []interface{}
+	for cur.Next(ctx) {
+		var m bson.M
+		if err := cur.Decode(&m); err != nil {
+			cur.Close(ctx)
+			return fmt.Errorf("SwapRollupBuild.users: %w", err)
+		}
+		docs = append(docs, m)
+	}
+	cur.Close(ctx)
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("SwapRollupBuild.users: %w", err)
+	}
+	if len(docs) > 0 {
+		if _, err := r.rollupUsers.InsertMany(ctx, docs); err != nil {
+			return fmt.Errorf("SwapRollupBuild.users insert: %w", err)
+		}
+	}
+	return nil
+}
+
+func readBuckets(ctx context.Context, col *mongo.Collection, from, to time.Time) (map[time.Time]bson.M, error) {
+	cur, err := col.Find(ctx, bson.M{"_id": bson.M{"$gte": from, "$lt": to}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	buckets := make(map[time.Time]bson.M)
+	for cur.Next(ctx) {
+		var b bson.M
+		if err := cur.Decode(&b); err != nil {
+			return nil, err
+		}
+		start, ok := b["_id"].(primitive.DateTime)
+		if !ok {
+			return nil, fmt.Errorf("bucket id %v is not a date", b["_id"])
+		}
+		buckets[start.Time().UTC()] = b
+	}
+	return buckets, cur.Err()
+}
+
+// newUsersByBucket counts the users first seen from from to to by the start
+// of the bucket of granularity they were first seen in.
+func (r *Repository) newUsersByBucket(ctx context.Context, granularity string, from, to time.Time) (map[time.Time]int64, error) {
+	cur, err := r.users.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"first_seen": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$dateTrunc": bson.M{"date": "$first_seen", "unit": granularity}},
+			"users": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Start time.Time `bson:"_id"`
+		Users int64     `bson:"users"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	out := make(map[time.Time]int64, len(rows))
+	for _, row := range rows {
+		out[row.Start.UTC()] = row.Users
+	}
+	return out, nil
+}
+
+// DropRollupBuild drops the collections of the rebuild.
+func (r *Repository) DropRollupBuild(ctx context.Context, id string) error {
+	cols := []*mongo.Collection{r.rebuildUsers(id)}
+	for _, ru := range newRollups(r.db, rebuildPrefix(id)) {
+		cols = append(cols, ru.col)
+	}
+	for _, col := range cols {
+		if err := col.Drop(ctx); err != nil {
+			return fmt.Errorf("DropRollupBuild %s: %w", col.Name(), err)
+		}
+	}
+	return nil
+}
+
+// LeaseRollupSwap updates the lease document if id holds it or another
+// rebuild held it and it expired. Otherwise the upsert inserts a second
+// document with the same _id and fails, also when the lease of id expired,
+// since events may have been handled since.
+func (r *Repository) LeaseRollupSwap(ctx context.Context, id string, ttl time.Duration) error {
+	now := time.Now()
+	_, err := r.leases.UpdateOne(ctx, bson.M{
+		"_id": rollupSwapLease,
+		"$or": bson.A{
+			bson.M{"holder": id, "until": bson.M{"$gt": now}},
+			bson.M{"holder": bson.M{"$ne": id}, "until": bson.M{"$lte": now}},
+		},
+	}, bson.M{"$set": bson.M{"holder": id, "until": now.Add(ttl)}}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrRollupsSwapping
+	}
+	if err != nil {
+		return fmt.Errorf("LeaseRollupSwap: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) ReleaseRollupSwap(ctx context.Context, id string) error {
+	if _, err := r.leases.DeleteOne(ctx, bson.M{"_id": rollupSwapLease, "holder": id}); err != nil {
+		return fmt.Errorf("ReleaseRollupSwap: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) RollupSwapLeased(ctx context.Context) (bool, error) {
+	err := r.leases.FindOne(ctx, bson.M{"_id": rollupSwapLease, "until": bson.M{"$gt": time.Now()}}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("RollupSwapLeased: %w", err)
+	}
+	return true, nil
+}
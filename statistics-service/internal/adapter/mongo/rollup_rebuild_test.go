@@ -0,0 +1,98 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/config"
+	cache "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/inmemory"
+	natsadapter "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/nats"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/usecase"
+	natscl "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/nats"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// TestLeaseRollupSwap runs LeaseRollupSwap against a mocked deployment and
+// checks who may take the lease and how a lease held by another rebuild is
+// reported.
+func TestLeaseRollupSwap(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name     string
+		response bson.D
+		wantErr  error
+	}{
+		{
+			name:     "taken or extended",
+			response: mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		},
+		{
+			name:     "held by another rebuild",
+			response: mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "E11000 duplicate key error"}),
+			wantErr:  domain.ErrRollupsSwapping,
+		},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(tt.response)
+			repo := NewRepository(mt.DB)
+
+			err := repo.LeaseRollupSwap(context.Background(), "r1", time.Minute)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LeaseRollupSwap() error = %v, want %v", err, tt.wantErr)
+			}
+
+			evt := mt.GetStartedEvent()
+			if evt == nil || evt.CommandName != "update" {
+				t.Fatalf("command = %v, want update", evt)
+			}
+			update := evt.Command.Lookup("updates").Array().Index(0).Value().Document()
+			if !update.Lookup("upsert").Boolean() {
+				t.Error("update is not an upsert")
+			}
+			or := update.Lookup("q", "$or").Array()
+			own := or.Index(0).Value().Document()
+			if own.Lookup("holder").StringValue() != "r1" || own.Lookup("until", "$gt").Type != bson.TypeDateTime {
+				t.Errorf("first clause = %s, want the unexpired lease of r1", own)
+			}
+			other := or.Index(1).Value().Document()
+			if other.Lookup("holder", "$ne").StringValue() != "r1" || other.Lookup("until", "$lte").Type != bson.TypeDateTime {
+				t.Errorf("second clause = %s, want an expired lease of another rebuild", other)
+			}
+		})
+	}
+}
+
+// TestHandleEventWhileRollupsSwapping checks that events are held back and
+// redelivered while a rebuild holds the swap lease, on any instance.
+func TestHandleEventWhileRollupsSwapping(t *testing.T) {
+	ctx := context.Background()
+	repo := newCountingRepository()
+	uc := usecase.NewStatisticsUsecase(repo, cache.NewInMemoryEventCache(), repo, noActiveUsers{},
+		domain.RFMThresholds{}, domain.WatchConfig{}, 1, "USD")
+	h := natsadapter.NewStatisticsHandler(uc, nil, config.NatsSubjects{OrderCreated: "order.created"})
+	msg := readContract(t, "json", "order.created", "evt-1")
+
+	repo.swapping = true
+	err := h.Replay(ctx, msg)
+	if !errors.Is(err, domain.ErrRollupsSwapping) || !natscl.IsDeferred(err) {
+		t.Fatalf("Replay() while swapping error = %v, want a deferred %v", err, domain.ErrRollupsSwapping)
+	}
+	if len(repo.events) != 0 || len(repo.rollups) != 0 {
+		t.Fatalf("counted while swapping: events %v, rollups %v", repo.events, repo.rollups)
+	}
+
+	repo.swapping = false
+	if err := h.Replay(ctx, msg); err != nil {
+		t.Fatalf("Replay() after the swap: %v", err)
+	}
+	if got := repo.rollups["orders_created"]; got != 1 {
+		t.Errorf("orders_created = %d after the swap, want 1", got)
+	}
+}
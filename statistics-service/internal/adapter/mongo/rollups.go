@@ -0,0 +1,212 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	usersCollection       = "users"
+	rollupUsersCollection = "rollup_users"
+
+	// rollupUsersRetention keeps the active user markers of a bucket longer
+	// than JetStream keeps the events that could still be redelivered into
+	// it.
+	rollupUsersRetention = 8 * 24 * time.Hour
+)
+
+// rollup is a collection of time buckets, one document per bucket with the
+// start of the bucket as _id.
+type rollup struct {
+	granularity string
+	size        time.Duration
+	col         *mongo.Collection
+}
+
+// newRollups returns the rollups, their collection names prefixed with
+// prefix.
+func newRollups(db *mongo.Database, prefix string) []rollup {
+	return []rollup{
+		{domain.RollupMinute, time.Minute, db.Collection(prefix + "rollup_minute")},
+		{domain.RollupHour, time.Hour, db.Collection(prefix + "rollup_hour")},
+		{domain.RollupDay, 24 * time.Hour, db.Collection(prefix + "rollup_day")},
+	}
+}
+
+// rollupCounters are the counters of the buckets by event type.
+var rollupCounters = map[string]string{
+	domain.EventOrderCreated:    "orders_created",
+	domain.EventOrderUpdated:    "orders_updated",
+	domain.EventOrderDeleted:    "orders_deleted",
+	domain.EventPaymentCreated:  "payments",
+	domain.EventProductCreated:  "products_created",
+	domain.EventProductUpdated:  "products_updated",
+	domain.EventProductDeleted:  "products_deleted",
+	domain.EventCategoryCreated: "categories_created",
+	domain.EventCategoryUpdated: "categories_updated",
+	domain.EventCategoryDeleted: "categories_deleted",
+
+	domain.EventCategoryBrowsed:  "category_browses",
+	domain.EventProductViewed:    "product_views",
+	domain.EventOrderPlaced:      "orders_placed",
+	domain.EventPaymentAttempted: "payment_attempts",
+	domain.EventPaymentCompleted: "payments_completed",
+}
+
+// UpdateRollups adds the event to its minute, hour and day buckets: the
+// counter of its type, the value of a placed order and the revenue of a
+// completed payment per currency, and the users active in the bucket and
+// new overall. A user is counted once per bucket by a marker in
+// rollup_users, and once overall by their document in users, which also
+// counts their orders.
+func (r *Repository) UpdateRollups(ctx context.Context, evt domain.Event) error {
+	counters, err := rollupIncrements(evt)
+	if err != nil {
+		return fmt.Errorf("UpdateRollups: %w", err)
+	}
+	inc := bson.M{}
+	for k, v := range counters {
+		inc[k] = v
+	}
+
+	ts := evt.Timestamp.UTC()
+	if evt.UserID != "" {
+		orders := 0
+		if evt.EventType == domain.EventOrderCreated {
+			orders = 1
+		}
+		res, err := r.users.UpdateOne(ctx, bson.M{"_id": evt.UserID}, bson.M{
+			"$setOnInsert": bson.M{"first_seen": ts},
+			"$inc":         bson.M{"orders": orders},
+		}, options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("UpdateRollups.users: %w", err)
+		}
+		if res.UpsertedCount > 0 {
+			inc["new_users"] = 1
+		}
+	}
+
+	for _, ru := range r.rollups {
+		start := ts.Truncate(ru.size)
+		bucketInc := inc
+		if evt.UserID != "" {
+			marker := bson.M{"granularity": ru.granularity, "start": start, "user_id": evt.UserID}
+			res, err := r.rollupUsers.UpdateOne(ctx, marker, bson.M{
+				"$setOnInsert": bson.M{"expire_at": start.Add(ru.size + rollupUsersRetention)},
+			}, options.Update().SetUpsert(true))
+			if err != nil {
+				return fmt.Errorf("UpdateRollups.%s users: %w", ru.granularity, err)
+			}
+			if res.UpsertedCount > 0 {
+				bucketInc = bson.M{"active_users": 1}
+				for k, v := range inc {
+					bucketInc[k] = v
+				}
+			}
+		}
+
+		_, err := ru.col.UpdateOne(ctx, bson.M{"_id": start}, bson.M{"$inc": bucketInc}, options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("UpdateRollups.%s: %w", ru.granularity, err)
+		}
+	}
+	return nil
+}
+
+// rollupIncrements returns what the event adds to its buckets, apart from
+// the users.
+func rollupIncrements(evt domain.Event) (map[string]int64, error) {
+	counter, ok := rollupCounters[evt.EventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %s", evt.EventType)
+	}
+	inc := map[string]int64{counter: 1}
+	switch evt.EventType {
+	case domain.EventOrderCreated:
+		if m, ok := moneyField(evt.Data, "total"); ok {
+			inc["order_value."+m.Currency] = m.Amount
+		}
+	case domain.EventPaymentCreated:
+		status, _ := evt.Data["status"].(string)
+		if m, ok := moneyField(evt.Data, "amount"); ok && status == "Completed" {
+			inc["revenue."+m.Currency] = m.Amount
+		}
+	}
+	return inc, nil
+}
+
+// moneyField reads an amount as decoded by the NATS adapter or read back
+// from statistics_events.
+func moneyField(data map[string]interface{}, key string) (domain.Money, bool) {
+	m, _ := data[key].(map[string]interface{})
+	amount, _ := m["amount"].(int64)
+	currency, _ := m["currency"].(string)
+	return domain.Money{Amount: amount, Currency: currency}, currency != ""
+}
+
+// ensureRollupIndexes makes the active user markers unique and expires
+// them.
+func (r *Repository) ensureRollupIndexes(ctx context.Context) error {
+	_, err := r.rollupUsers.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "granularity", Value: 1}, {Key: "start", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expire_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// rollup returns the rollup of granularity.
+func (r *Repository) rollup(granularity string) (rollup, error) {
+	for _, ru := range r.rollups {
+		if ru.granularity == granularity {
+			return ru, nil
+		}
+	}
+	return rollup{}, fmt.Errorf("unknown rollup %q", granularity)
+}
+
+// countActiveUsers counts the distinct users of the buckets of granularity
+// starting from from to to, or on if to is zero.
+func (r *Repository) countActiveUsers(ctx context.Context, granularity string, from, to time.Time) (int64, error) {
+	log.Printf("[Mongo] Counting active users in %s buckets from %v", granularity, from)
+
+	start := bson.M{"$gte": from}
+	if !to.IsZero() {
+		start["$lt"] = to
+	}
+	cur, err := r.rollupUsers.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"granularity": granularity,
+			"start":       start,
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$user_id"}}},
+		{{Key: "$count", Value: "activeUsers"}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var res struct {
+		ActiveUsers int64 `bson:"activeUsers"`
+	}
+	if cur.Next(ctx) {
+		if err := cur.Decode(&res); err != nil {
+			return 0, err
+		}
+	}
+	return res.ActiveUsers, cur.Err()
+}
@@ -0,0 +1,38 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var _ domain.Transactor = (*Transactor)(nil)
+
+// Transactor runs functions in MongoDB multi-document transactions, which
+// need a replica set.
+type Transactor struct {
+	client *mongo.Client
+}
+
+func NewTransactor(client *mongo.Client) *Transactor {
+	return &Transactor{client: client}
+}
+
+// WithinTransaction commits if fn succeeds and aborts otherwise. Transient
+// errors, such as write conflicts on the rollup buckets every consumer
+// increments, run fn again, so fn must only depend on what it reads inside
+// the transaction.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := t.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
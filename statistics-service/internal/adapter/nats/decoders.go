@@ -0,0 +1,270 @@
+package nats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/config"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/pkg/events"
+	eventspb "github.com/Neroframe/ecommerce-platform/statistics-service/proto/events"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+)
+
+var errMissingID = errors.New("missing entity id")
+
+// decoder reads the events of one subject, in either encoding of package
+// events.
+type decoder struct {
+	eventType  string
+	newPayload func() proto.Message
+}
+
+// newDecoders maps the configured subjects to their decoders. The event type
+// is fixed per decoder rather than taken from the subject: subjects are
+// configurable, while the usecase, the rollups and the stored events key on
+// the type, so an event published on a renamed subject is still counted as
+// what it is. Protobuf events also carry their type, which has to match.
+func newDecoders(subjects config.NatsSubjects) map[string]decoder {
+	return map[string]decoder{
+		subjects.OrderCreated: {events.TypeOrderCreated, func() proto.Message { return &eventspb.OrderCreated{} }},
+		subjects.OrderUpdated: {events.TypeOrderUpdated, func() proto.Message { return &eventspb.OrderUpdated{} }},
+		subjects.OrderDeleted: {events.TypeOrderDeleted, func() proto.Message { return &eventspb.OrderDeleted{} }},
+
+		subjects.PaymentCreated: {events.TypePaymentCreated, func() proto.Message { return &eventspb.PaymentCreated{} }},
+
+		subjects.ProductCreated: {events.TypeProductCreated, func() proto.Message { return &eventspb.ProductCreated{} }},
+		subjects.ProductUpdated: {events.TypeProductUpdated, func() proto.Message { return &eventspb.ProductUpdated{} }},
+		subjects.ProductDeleted: {events.TypeProductDeleted, func() proto.Message { return &eventspb.ProductDeleted{} }},
+
+		subjects.CategoryCreated: {events.TypeCategoryCreated, func() proto.Message { return &eventspb.CategoryCreated{} }},
+		subjects.CategoryUpdated: {events.TypeCategoryUpdated, func() proto.Message { return &eventspb.CategoryUpdated{} }},
+		subjects.CategoryDeleted: {events.TypeCategoryDeleted, func() proto.Message { return &eventspb.CategoryDeleted{} }},
+	}
+}
+
+// clickstreamTypes maps the configured clickstream subjects to their event
+// types.
+func clickstreamTypes(subjects config.NatsSubjects) map[string]string {
+	return map[string]string{
+		subjects.CategoryBrowsed:  domain.EventCategoryBrowsed,
+		subjects.ProductViewed:    domain.EventProductViewed,
+		subjects.OrderPlaced:      domain.EventOrderPlaced,
+		subjects.PaymentAttempted: domain.EventPaymentAttempted,
+		subjects.PaymentCompleted: domain.EventPaymentCompleted,
+	}
+}
+
+// clickstreamEvent is the JSON the API gateway publishes.
+type clickstreamEvent struct {
+	EventID    string            `json:"event_id"`
+	UserID     string            `json:"user_id"`
+	SessionID  string            `json:"session_id"`
+	EntityKey  string            `json:"entity_key"`
+	EntityID   string            `json:"entity_id"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       map[string]string `json:"data"`
+}
+
+// decode picks the decoder by subject and normalizes the event.
+func (h *StatisticsHandler) decode(msg *nats.Msg) (domain.Event, error) {
+	if eventType, ok := h.clickstream[msg.Subject]; ok {
+		return decodeClickstream(msg, eventType)
+	}
+	dec, ok := h.decoders[msg.Subject]
+	if !ok {
+		return domain.Event{}, fmt.Errorf("no decoder for subject %s", msg.Subject)
+	}
+
+	payload := dec.newPayload()
+	meta, err := events.Decode(msg.Header, msg.Data, dec.eventType, payload)
+	if err != nil {
+		return domain.Event{}, fmt.Errorf("decode %s: %w", msg.Subject, err)
+	}
+
+	evt, err := toEvent(payload)
+	if err != nil {
+		return domain.Event{}, fmt.Errorf("decode %s: %w", msg.Subject, err)
+	}
+	if evt.EntityID == "" {
+		return domain.Event{}, fmt.Errorf("decode %s: %w", msg.Subject, errMissingID)
+	}
+	evt.EventID = eventID(msg, meta)
+	evt.EventType = dec.eventType
+	evt.Timestamp = meta.OccurredAt
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+	return evt, nil
+}
+
+// decodeClickstream normalizes a clickstream event. The session goes into
+// the data, the funnels follow it rather than the user when it is set.
+func decodeClickstream(msg *nats.Msg, eventType string) (domain.Event, error) {
+	var ce clickstreamEvent
+	if err := json.Unmarshal(msg.Data, &ce); err != nil {
+		return domain.Event{}, fmt.Errorf("decode %s: %w", msg.Subject, err)
+	}
+	if ce.EntityID == "" {
+		return domain.Event{}, fmt.Errorf("decode %s: %w", msg.Subject, errMissingID)
+	}
+	if ce.UserID == "" && ce.SessionID == "" {
+		return domain.Event{}, fmt.Errorf("decode %s: no user or session", msg.Subject)
+	}
+
+	data := make(map[string]interface{}, len(ce.Data)+1)
+	for k, v := range ce.Data {
+		data[k] = v
+	}
+	if ce.SessionID != "" {
+		data["session_id"] = ce.SessionID
+	}
+	evt := domain.Event{
+		EventID:   eventID(msg, events.Metadata{EventID: ce.EventID}),
+		UserID:    ce.UserID,
+		EntityID:  ce.EntityID,
+		EntityKey: ce.EntityKey,
+		EventType: eventType,
+		Timestamp: ce.OccurredAt,
+		Data:      data,
+	}
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+	return evt, nil
+}
+
+// toEvent maps a payload to a normalized event without its metadata. An
+// order whose total does not fit in an int64 is rejected.
+func toEvent(payload proto.Message) (domain.Event, error) {
+	switch p := payload.(type) {
+	case *eventspb.OrderCreated:
+		// orders are priced in one currency, orders from before items had
+		// prices have none and total zero
+		var (
+			total domain.Money
+			units int64
+		)
+		items := make([]interface{}, 0, len(p.GetItems()))
+		for _, item := range p.GetItems() {
+			price := item.GetUnitPrice()
+			items = append(items, map[string]interface{}{
+				"product_id": item.GetProductId(),
+				"quantity":   item.GetQuantity(),
+				"unit_price": moneyData(price),
+			})
+			if total.Currency == "" {
+				total.Currency = price.GetCurrency()
+			}
+			line, err := domain.Money{Amount: price.GetAmount(), Currency: total.Currency}.Mul(int64(item.GetQuantity()))
+			if err != nil {
+				return domain.Event{}, fmt.Errorf("order %s total: %w", p.GetOrderId(), err)
+			}
+			if total, err = total.Add(line); err != nil {
+				return domain.Event{}, fmt.Errorf("order %s total: %w", p.GetOrderId(), err)
+			}
+			units += int64(item.GetQuantity())
+		}
+		return domain.Event{
+			UserID:    p.GetUserId(),
+			EntityID:  p.GetOrderId(),
+			EntityKey: "order_id",
+			Data: map[string]interface{}{
+				"items": items,
+				"total": map[string]interface{}{"amount": total.Amount, "currency": total.Currency},
+				"units": units,
+			},
+		}, nil
+	case *eventspb.OrderUpdated:
+		return domain.Event{
+			EntityID:  p.GetOrderId(),
+			EntityKey: "order_id",
+			Data:      map[string]interface{}{"status": p.GetStatus()},
+		}, nil
+	case *eventspb.OrderDeleted:
+		return domain.Event{EntityID: p.GetOrderId(), EntityKey: "order_id"}, nil
+
+	case *eventspb.PaymentCreated:
+		return domain.Event{
+			EntityID:  p.GetPaymentId(),
+			EntityKey: "payment_id",
+			Data: map[string]interface{}{
+				"order_id": p.GetOrderId(),
+				"amount":   moneyData(p.GetAmount()),
+				"method":   p.GetMethod(),
+				"status":   p.GetStatus(),
+			},
+		}, nil
+
+	case *eventspb.ProductCreated:
+		// inventory-service creates products at version 1
+		return productEvent(p.GetId(), p.GetName(), p.GetPrice(), p.GetCategoryId(), 1, p.Stock), nil
+	case *eventspb.ProductUpdated:
+		return productEvent(p.GetId(), p.GetName(), p.GetPrice(), p.GetCategoryId(), p.GetVersion(), p.Stock), nil
+	case *eventspb.ProductDeleted:
+		return domain.Event{EntityID: p.GetId(), EntityKey: "product_id"}, nil
+
+	case *eventspb.CategoryCreated:
+		return categoryEvent(p.GetId(), p.GetName(), p.GetParentId()), nil
+	case *eventspb.CategoryUpdated:
+		return categoryEvent(p.GetId(), p.GetName(), p.GetParentId()), nil
+	case *eventspb.CategoryDeleted:
+		return domain.Event{EntityID: p.GetId(), EntityKey: "category_id"}, nil
+	}
+	return domain.Event{}, nil
+}
+
+// productEvent leaves the stock out of the data if the event has none.
+func productEvent(id, name string, price *eventspb.Money, categoryID string, version int64, stock *int32) domain.Event {
+	data := map[string]interface{}{
+		"name":        name,
+		"price":       moneyData(price),
+		"category_id": categoryID,
+		"version":     version,
+	}
+	if stock != nil {
+		data["stock"] = int64(*stock)
+	}
+	return domain.Event{
+		EntityID:  id,
+		EntityKey: "product_id",
+		Data:      data,
+	}
+}
+
+func moneyData(m *eventspb.Money) map[string]interface{} {
+	return map[string]interface{}{
+		"amount":   m.GetAmount(),
+		"currency": m.GetCurrency(),
+	}
+}
+
+func categoryEvent(id, name, parentID string) domain.Event {
+	return domain.Event{
+		EntityID:  id,
+		EntityKey: "category_id",
+		Data: map[string]interface{}{
+			"name":      name,
+			"parent_id": parentID,
+		},
+	}
+}
+
+// eventID returns the ID of the event. JSON events sent before the envelope
+// have no Event-Id header; the outbox sent them with their ID as Nats-Msg-Id,
+// and anything older is identified by its content.
+func eventID(msg *nats.Msg, meta events.Metadata) string {
+	if meta.EventID != "" {
+		return meta.EventID
+	}
+	if id := msg.Header.Get(nats.MsgIdHdr); id != "" {
+		return id
+	}
+	sum := sha256.Sum256(append([]byte(msg.Subject+"\n"), msg.Data...))
+	return hex.EncodeToString(sum[:])
+}
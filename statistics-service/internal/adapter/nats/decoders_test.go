@@ -0,0 +1,190 @@
+package nats
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/config"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	eventspb "github.com/Neroframe/ecommerce-platform/statistics-service/proto/events"
+	"github.com/nats-io/nats.go"
+)
+
+// contractDir holds the messages inventory-service and order-service send,
+// written by their outbox publisher tests from the producer structs.
+const contractDir = "../../../../proto/events/testdata"
+
+// contractMessage is a contract file: a JSON body as is, a protobuf one in
+// base64.
+type contractMessage struct {
+	Subject string            `json:"subject"`
+	Headers map[string]string `json:"headers"`
+	JSON    json.RawMessage   `json:"json"`
+	Data    []byte            `json:"data"`
+}
+
+func readContract(t *testing.T, path string) *nats.Msg {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	var cm contractMessage
+	if err := json.Unmarshal(data, &cm); err != nil {
+		t.Fatalf("unmarshal %s: %v", path, err)
+	}
+
+	msg := &nats.Msg{Subject: cm.Subject, Header: nats.Header{}, Data: cm.Data}
+	for k, v := range cm.Headers {
+		msg.Header[k] = []string{v}
+	}
+	if cm.JSON != nil {
+		msg.Data = cm.JSON
+	}
+	return msg
+}
+
+// TestDecodeProducerContracts decodes what the producers send in both
+// encodings and checks the events the statistics are counted from.
+func TestDecodeProducerContracts(t *testing.T) {
+	subjects := config.NatsSubjects{
+		OrderCreated:    "order.created",
+		OrderUpdated:    "order.updated",
+		OrderDeleted:    "order.deleted",
+		PaymentCreated:  "payment.created",
+		ProductCreated:  "product.created",
+		ProductUpdated:  "product.updated",
+		ProductDeleted:  "product.deleted",
+		CategoryCreated: "category.created",
+		CategoryUpdated: "category.updated",
+		CategoryDeleted: "category.deleted",
+	}
+	h := &StatisticsHandler{decoders: newDecoders(subjects), clickstream: clickstreamTypes(subjects)}
+
+	occurredAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	usd := func(amount int64) map[string]interface{} {
+		return map[string]interface{}{"amount": amount, "currency": "USD"}
+	}
+	want := map[string]domain.Event{
+		"order.created": {
+			UserID:    "u1",
+			EntityID:  "o1",
+			EntityKey: "order_id",
+			Data: map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"product_id": "p1", "quantity": int32(2), "unit_price": usd(499)},
+					map[string]interface{}{"product_id": "p2", "quantity": int32(1), "unit_price": usd(1250)},
+				},
+				"total": usd(2248),
+				"units": int64(3),
+			},
+		},
+		"order.updated": {
+			EntityID:  "o1",
+			EntityKey: "order_id",
+			Data:      map[string]interface{}{"status": "Completed"},
+		},
+		"order.deleted": {EntityID: "o1", EntityKey: "order_id"},
+		"payment.created": {
+			EntityID:  "pay1",
+			EntityKey: "payment_id",
+			Data: map[string]interface{}{
+				"order_id": "o1",
+				"amount":   usd(2248),
+				"method":   "card",
+				"status":   "Completed",
+			},
+		},
+		"product.created": {
+			EntityID:  "p1",
+			EntityKey: "product_id",
+			Data: map[string]interface{}{
+				"name":        "apple",
+				"price":       usd(2599),
+				"category_id": "c1",
+				"version":     int64(1),
+				"stock":       int64(3),
+			},
+		},
+		"product.updated": {
+			EntityID:  "p1",
+			EntityKey: "product_id",
+			Data: map[string]interface{}{
+				"name":        "apple",
+				"price":       usd(2599),
+				"category_id": "c1",
+				"version":     int64(2),
+				"stock":       int64(0),
+			},
+		},
+		"product.deleted": {EntityID: "p1", EntityKey: "product_id"},
+		"category.created": {
+			EntityID:  "c1",
+			EntityKey: "category_id",
+			Data:      map[string]interface{}{"name": "fruit", "parent_id": "c0"},
+		},
+		"category.updated": {
+			EntityID:  "c1",
+			EntityKey: "category_id",
+			Data:      map[string]interface{}{"name": "fresh fruit", "parent_id": "c0"},
+		},
+		"category.deleted": {EntityID: "c1", EntityKey: "category_id"},
+	}
+
+	for _, enc := range []string{"json", "protobuf"} {
+		for eventType, expected := range want {
+			t.Run(enc+"/"+eventType, func(t *testing.T) {
+				msg := readContract(t, filepath.Join(contractDir, enc, eventType+".json"))
+
+				got, err := h.decode(msg)
+				if err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if !got.Timestamp.Equal(occurredAt) {
+					t.Errorf("Timestamp = %s, want %s", got.Timestamp, occurredAt)
+				}
+				got.Timestamp = time.Time{}
+
+				expected.EventID = "6650f0c0a1b2c3d4e5f60718"
+				expected.EventType = eventType
+				if !reflect.DeepEqual(got, expected) {
+					t.Errorf("decode() =\n%+v\nwant\n%+v", got, expected)
+				}
+			})
+		}
+	}
+}
+
+func TestToEventRejectsOverflowingOrderTotal(t *testing.T) {
+	item := func(amount int64, quantity int32) *eventspb.OrderItem {
+		return &eventspb.OrderItem{ProductId: "p1", Quantity: quantity, UnitPrice: &eventspb.Money{Amount: amount, Currency: "USD"}}
+	}
+
+	tests := []struct {
+		name    string
+		items   []*eventspb.OrderItem
+		wantErr bool
+	}{
+		{name: "largest total", items: []*eventspb.OrderItem{item(math.MaxInt64/2, 2), item(1, 1)}},
+		{name: "line overflows", items: []*eventspb.OrderItem{item(math.MaxInt64/2+1, 2)}, wantErr: true},
+		{name: "sum overflows", items: []*eventspb.OrderItem{item(math.MaxInt64, 1), item(1, 1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := toEvent(&eventspb.OrderCreated{OrderId: "o1", UserId: "u1", Items: tt.items})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toEvent() error = %v, want error %t", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, domain.ErrInvalidMoney) {
+				t.Errorf("toEvent() error = %v, want %v", err, domain.ErrInvalidMoney)
+			}
+		})
+	}
+}
@@ -2,33 +2,41 @@ package nats
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 
+	"github.com/Neroframe/ecommerce-platform/statistics-service/config"
 	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
 	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/usecase"
+	natscl "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/nats"
 	"github.com/nats-io/nats.go"
 )
 
 type StatisticsHandler struct {
-	uc *usecase.StatisticsUsecase
-	nc *nats.Conn
+	uc          *usecase.StatisticsUsecase
+	nc          *nats.Conn
+	decoders    map[string]decoder
+	clickstream map[string]string
 }
 
-func NewStatisticsHandler(uc *usecase.StatisticsUsecase, nc *nats.Conn) *StatisticsHandler {
-	return &StatisticsHandler{uc: uc, nc: nc}
+func NewStatisticsHandler(uc *usecase.StatisticsUsecase, nc *nats.Conn, subjects config.NatsSubjects) *StatisticsHandler {
+	return &StatisticsHandler{
+		uc:          uc,
+		nc:          nc,
+		decoders:    newDecoders(subjects),
+		clickstream: clickstreamTypes(subjects),
+	}
 }
 
 func (h *StatisticsHandler) Handle(ctx context.Context, msg *nats.Msg) error {
-	// decode
-	var evt domain.Event
-	if err := json.Unmarshal(msg.Data, &evt); err != nil {
-		return fmt.Errorf("unmarshal event: %w", err)
+	evt, err := h.handle(ctx, msg)
+	if err != nil {
+		return err
 	}
-	if err := h.uc.HandleEvent(ctx, evt); err != nil {
-		return fmt.Errorf("handle event: %w", err)
+	// the gateway does not wait for its clickstream
+	if domain.IsClickstream(evt.EventType) {
+		return nil
 	}
 
 	// acknowledgement
@@ -36,7 +44,7 @@ func (h *StatisticsHandler) Handle(ctx context.Context, msg *nats.Msg) error {
 		ackSub string
 		ackMsg string
 	)
-	if strings.HasPrefix(evt.EventType, "order.") {
+	if evt.EntityKey == "order_id" {
 		ackSub = "statistics.order.received"
 		ackMsg = fmt.Sprintf("order with id %s is received", evt.EntityID)
 	} else {
@@ -53,3 +61,25 @@ func (h *StatisticsHandler) Handle(ctx context.Context, msg *nats.Msg) error {
 
 	return nil
 }
+
+// Replay handles a message read back from its stream. Nobody waits for an
+// acknowledgement of it.
+func (h *StatisticsHandler) Replay(ctx context.Context, msg *nats.Msg) error {
+	_, err := h.handle(ctx, msg)
+	return err
+}
+
+func (h *StatisticsHandler) handle(ctx context.Context, msg *nats.Msg) (domain.Event, error) {
+	// decode
+	evt, err := h.decode(msg)
+	if err != nil {
+		return evt, natscl.Permanent(err)
+	}
+	if err := h.uc.HandleEvent(ctx, evt); err != nil {
+		if errors.Is(err, domain.ErrRollupsSwapping) {
+			return evt, natscl.Deferred(err)
+		}
+		return evt, fmt.Errorf("handle event: %w", err)
+	}
+	return evt, nil
+}
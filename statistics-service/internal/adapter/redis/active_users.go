@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/pkg/redis"
+)
+
+var _ domain.ActiveUserSketches = (*ActiveUsers)(nil)
+
+const activeUsersKeyPrefix = "statistics:active_users:%s"
+
+// ActiveUsers keeps a HyperLogLog of the users active on every UTC day.
+// Counting several days merges their sketches, so a user active on more
+// than one of them is counted once.
+type ActiveUsers struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewActiveUsers(client *redis.Client, ttl time.Duration) *ActiveUsers {
+	return &ActiveUsers{client: client, ttl: ttl}
+}
+
+func (a *ActiveUsers) Add(ctx context.Context, evts []domain.Event) error {
+	users := make(map[string][]interface{})
+	for _, evt := range evts {
+		if evt.UserID == "" {
+			continue
+		}
+		key := a.key(evt.Timestamp)
+		users[key] = append(users[key], evt.UserID)
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	pipe := a.client.Unwrap().Pipeline()
+	for key, ids := range users {
+		pipe.PFAdd(ctx, key, ids...)
+		pipe.Expire(ctx, key, a.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis PFAdd error: %w", err)
+	}
+	return nil
+}
+
+func (a *ActiveUsers) Count(ctx context.Context, from, to time.Time) (int64, error) {
+	var keys []string
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.Add(24 * time.Hour) {
+		keys = append(keys, a.key(day))
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	n, err := a.client.Unwrap().PFCount(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis PFCount error: %w", err)
+	}
+	log.Printf("[Redis] PFCount of %d days from %s: %d", len(keys), keys[0], n)
+	return n, nil
+}
+
+func (a *ActiveUsers) key(t time.Time) string {
+	return fmt.Sprintf(activeUsersKeyPrefix, t.UTC().Format(time.DateOnly))
+}
@@ -13,17 +13,20 @@ import (
 	cache "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/inmemory"
 	mongoadapter "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/mongo"
 	natsadapter "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/nats"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/redis"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
 	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/usecase"
 	mongocon "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/mongo"
 	natsconn "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/nats"
 	natsconsumer "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/nats/consumer"
+	redisconn "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/redis"
 )
 
 const serviceName = "statistics-service"
 
 type App struct {
 	grpcServer   *grpcadapter.API
-	natsConsumer *natsconsumer.PubSub
+	natsConsumer *natsconsumer.JetStream
 }
 
 func New(ctx context.Context, cfg *config.Config) (*App, error) {
@@ -37,9 +40,26 @@ func New(ctx context.Context, cfg *config.Config) (*App, error) {
 
 	// Repository, inmemory cache & Usecase
 	repo := mongoadapter.NewRepository(mdb.Conn)
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("statistics indexes: %w", err)
+	}
 	evtCache := cache.NewInMemoryEventCache()
 
-	uc := usecase.NewStatisticsUsecase(repo, evtCache)
+	transactor := mongoadapter.NewTransactor(mdb.Client)
+
+	rfm, err := rfmThresholds(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Redis client & daily active user sketches
+	redisClient, err := redisconn.NewClient(ctx, (redisconn.Config)(cfg.Redis))
+	if err != nil {
+		return nil, fmt.Errorf("redisconn.NewClient: %w", err)
+	}
+	activeUsers := redis.NewActiveUsers(redisClient, cfg.ActiveUsersTTL)
+
+	uc := usecase.NewStatisticsUsecase(repo, evtCache, transactor, activeUsers, rfm, (domain.WatchConfig)(cfg.Watch), cfg.RelatedMinOrders, cfg.DefaultCurrency)
 
 	// gRPC API
 	grpcAPI := grpcadapter.New(cfg.Server.GRPCServer, uc)
@@ -51,32 +71,85 @@ func New(ctx context.Context, cfg *config.Config) (*App, error) {
 	}
 	log.Printf("NATS status: %s", nc.Conn.Status())
 
-	// NATS conn & Handler
-	pubsub := natsconsumer.NewPubSub(nc)
-	handler := natsadapter.NewStatisticsHandler(uc, nc.Conn)
-
-	subjects := []string{
-		cfg.Nats.NatsSubjects.OrderCreated,
-		cfg.Nats.NatsSubjects.OrderUpdated,
-		cfg.Nats.NatsSubjects.OrderDeleted,
-		cfg.Nats.NatsSubjects.ProductCreated,
-		cfg.Nats.NatsSubjects.ProductUpdated,
-		cfg.Nats.NatsSubjects.ProductDeleted,
+	// JetStream streams & durable consumers
+	jsCfg := cfg.Nats.JetStream
+	subjects := cfg.Nats.NatsSubjects
+	streams := consumerStreams(cfg)
+
+	handler := natsadapter.NewStatisticsHandler(uc, nc.Conn, subjects)
+	consumer := natsconsumer.NewJetStream(nc, natsconsumer.JetStreamConfig{
+		Durable:           jsCfg.Durable,
+		AckWait:           jsCfg.AckWait,
+		MaxDeliver:        jsCfg.MaxDeliver,
+		Backoff:           jsCfg.Backoff,
+		DeadLetterSubject: jsCfg.DeadLetterSubject,
+	})
+
+	for _, stream := range streams {
+		if err := nc.EnsureStream(ctx, stream.Stream, stream.Subjects, jsCfg.StreamMaxAge); err != nil {
+			return nil, fmt.Errorf("nats stream: %w", err)
+		}
+		stream.Handler = handler.Handle
+		consumer.Subscribe(stream)
 	}
-
-	for _, subj := range subjects {
-		pubsub.Subscribe(natsconsumer.PubSubSubscriptionConfig{
-			Subject: subj,
-			Handler: handler.Handle,
-		})
+	// dead letters are kept until somebody looks at them
+	if err := nc.EnsureStream(ctx, jsCfg.DeadLetterStream, []string{jsCfg.DeadLetterSubject + ".>"}, 0); err != nil {
+		return nil, fmt.Errorf("nats dead letter stream: %w", err)
 	}
 
 	return &App{
 		grpcServer:   grpcAPI,
-		natsConsumer: pubsub,
+		natsConsumer: consumer,
 	}, nil
 }
 
+func rfmThresholds(cfg *config.Config) (domain.RFMThresholds, error) {
+	rfm := domain.RFMThresholds{
+		Recent:         cfg.RFM.Recent,
+		Lapsed:         cfg.RFM.Lapsed,
+		FrequentOrders: cfg.RFM.FrequentOrders,
+		HighValue:      cfg.RFM.HighValue,
+		Currency:       cfg.DefaultCurrency,
+	}
+	if err := rfm.Validate(); err != nil {
+		return rfm, fmt.Errorf("config: %w", err)
+	}
+	return rfm, nil
+}
+
+// consumerStreams returns the streams the service consumes and their
+// subjects, the categories and products before the orders they are needed by
+// and the clickstream of the gateway last.
+func consumerStreams(cfg *config.Config) []natsconsumer.JetStreamConsumerConfig {
+	jsCfg := cfg.Nats.JetStream
+	subjects := cfg.Nats.NatsSubjects
+	return []natsconsumer.JetStreamConsumerConfig{
+		{
+			Stream:   jsCfg.CategoryStream,
+			Subjects: []string{subjects.CategoryCreated, subjects.CategoryUpdated, subjects.CategoryDeleted},
+		},
+		{
+			Stream:   jsCfg.ProductStream,
+			Subjects: []string{subjects.ProductCreated, subjects.ProductUpdated, subjects.ProductDeleted},
+		},
+		{
+			Stream:   jsCfg.OrderStream,
+			Subjects: []string{subjects.OrderCreated, subjects.OrderUpdated, subjects.OrderDeleted},
+		},
+		{
+			Stream:   jsCfg.PaymentStream,
+			Subjects: []string{subjects.PaymentCreated},
+		},
+		{
+			Stream: jsCfg.ClickstreamStream,
+			Subjects: []string{
+				subjects.CategoryBrowsed, subjects.ProductViewed, subjects.OrderPlaced,
+				subjects.PaymentAttempted, subjects.PaymentCompleted,
+			},
+		},
+	}
+}
+
 // starts the gRPC server and NATS consumer - blocking until an error or OS signal
 func (a *App) Run() error {
 	errCh := make(chan error, 1)
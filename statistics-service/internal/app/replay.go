@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/config"
+	cache "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/inmemory"
+	mongoadapter "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/mongo"
+	natsadapter "github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/nats"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/adapter/redis"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/usecase"
+	mongocon "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/mongo"
+	natsconn "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/nats"
+	natsconsumer "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/nats/consumer"
+	redisconn "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/redis"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Replay builds the statistics in database, which must be empty, from the
+// messages retained by the given streams, all of them if none are given. A
+// stream is read up to its last message at the start of the replay, so the
+// service should be stopped meanwhile and pointed at database afterwards;
+// its durable consumers then go on from where they stopped.
+func Replay(ctx context.Context, cfg *config.Config, database string, streams []string) error {
+	all := consumerStreams(cfg)
+	for _, name := range streams {
+		if !slices.ContainsFunc(all, func(s natsconsumer.JetStreamConsumerConfig) bool { return s.Stream == name }) {
+			return fmt.Errorf("unknown stream %s", name)
+		}
+	}
+
+	rfm, err := rfmThresholds(cfg)
+	if err != nil {
+		return err
+	}
+
+	mongoCfg := cfg.Mongo
+	mongoCfg.Database = database
+	mdb, err := mongocon.NewDB(ctx, mongoCfg)
+	if err != nil {
+		return fmt.Errorf("mongo connect: %w", err)
+	}
+	defer mdb.Client.Disconnect(context.WithoutCancel(ctx))
+
+	cols, err := mdb.Conn.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return fmt.Errorf("mongo list collections: %w", err)
+	}
+	if len(cols) > 0 {
+		return fmt.Errorf("database %s is not empty", database)
+	}
+
+	repo := mongoadapter.NewRepository(mdb.Conn)
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("statistics indexes: %w", err)
+	}
+	// the sketches are shared, adding a user again changes nothing
+	redisClient, err := redisconn.NewClient(ctx, (redisconn.Config)(cfg.Redis))
+	if err != nil {
+		return fmt.Errorf("redisconn.NewClient: %w", err)
+	}
+	defer redisClient.Close()
+	activeUsers := redis.NewActiveUsers(redisClient, cfg.ActiveUsersTTL)
+
+	uc := usecase.NewStatisticsUsecase(repo, cache.NewInMemoryEventCache(), mongoadapter.NewTransactor(mdb.Client), activeUsers, rfm, (domain.WatchConfig)(cfg.Watch), cfg.RelatedMinOrders, cfg.DefaultCurrency)
+
+	nc, err := natsconn.NewClient(ctx, cfg.Nats.Hosts, cfg.Nats.NKey, cfg.Nats.IsTest)
+	if err != nil {
+		return fmt.Errorf("nats connect: %w", err)
+	}
+	defer nc.CloseConn()
+
+	handler := natsadapter.NewStatisticsHandler(uc, nc.Conn, cfg.Nats.NatsSubjects)
+	for _, stream := range all {
+		if len(streams) > 0 && !slices.Contains(streams, stream.Stream) {
+			continue
+		}
+		if err := replayStream(ctx, nc, stream, handler); err != nil {
+			return fmt.Errorf("replay %s: %w", stream.Stream, err)
+		}
+	}
+	log.Printf("Replay into %s done", database)
+	return nil
+}
+
+// replayStream reads the stream with an ordered consumer up to its current
+// last message. Messages that do not decode are skipped, as the service
+// dead-letters them.
+func replayStream(ctx context.Context, nc *natsconn.Client, cfg natsconsumer.JetStreamConsumerConfig, handler *natsadapter.StatisticsHandler) error {
+	stream, err := nc.JS.Stream(ctx, cfg.Stream)
+	if err != nil {
+		return fmt.Errorf("js.Stream: %w", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("stream.Info: %w", err)
+	}
+	last := info.State.LastSeq
+	if info.State.Msgs == 0 {
+		log.Printf("Replay %s: no messages", cfg.Stream)
+		return nil
+	}
+
+	cons, err := stream.OrderedConsumer(ctx, jetstream.OrderedConsumerConfig{
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return fmt.Errorf("stream.OrderedConsumer: %w", err)
+	}
+
+	var handled, skipped int
+	for {
+		batch, err := cons.Fetch(natsconn.MaxPending, jetstream.FetchMaxWait(natsconn.MaxWaitFetch))
+		if err != nil {
+			return fmt.Errorf("fetch: %w", err)
+		}
+		var (
+			seq  uint64
+			done bool
+		)
+		for msg := range batch.Messages() {
+			meta, err := msg.Metadata()
+			if err != nil {
+				return fmt.Errorf("message metadata: %w", err)
+			}
+			if meta.Sequence.Stream > last {
+				done = true
+				break
+			}
+			seq = meta.Sequence.Stream
+
+			err = handler.Replay(ctx, &nats.Msg{
+				Subject: msg.Subject(),
+				Header:  msg.Headers(),
+				Data:    msg.Data(),
+			})
+			switch {
+			case natsconn.IsPermanent(err):
+				log.Printf("Replay %s: skipping message %d: %v", cfg.Stream, seq, err)
+				skipped++
+			case err != nil:
+				return fmt.Errorf("message %d: %w", seq, err)
+			default:
+				handled++
+			}
+			if seq == last {
+				done = true
+				break
+			}
+		}
+		if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) {
+			return fmt.Errorf("fetch: %w", err)
+		}
+		log.Printf("Replay %s: %d messages handled, %d skipped, at %d of %d", cfg.Stream, handled, skipped, seq, last)
+		// an empty batch means the messages up to last that are left were
+		// removed or do not match the subjects
+		if done || seq == 0 {
+			return nil
+		}
+	}
+}
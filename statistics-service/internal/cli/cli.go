@@ -0,0 +1,122 @@
+// Package cli implements the admin subcommands of the service binary.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/config"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/app"
+	statisticspb "github.com/Neroframe/ecommerce-platform/statistics-service/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const usage = `usage:
+  main                                          run the service
+  main rebuild [-addr host:port] -from T -to T  rebuild the rollups of a time range
+  main replay -db DATABASE [-streams S1,S2]     replay JetStream into an empty database`
+
+// Run runs the subcommand named by args[0].
+func Run(ctx context.Context, args []string) error {
+	switch args[0] {
+	case "rebuild":
+		return rebuild(ctx, args[1:])
+	case "replay":
+		return replay(ctx, args[1:])
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+}
+
+// rebuild asks a running service to rebuild its rollups and prints the
+// progress.
+func rebuild(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rebuild", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:50051", "gRPC address of the statistics service")
+	fromArg := fs.String("from", "", "start of the range, RFC3339 or YYYY-MM-DD")
+	toArg := fs.String("to", "", "end of the range, RFC3339 or YYYY-MM-DD")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	from, err := parseTime(*fromArg)
+	if err != nil {
+		return fmt.Errorf("-from: %w", err)
+	}
+	to, err := parseTime(*toArg)
+	if err != nil {
+		return fmt.Errorf("-to: %w", err)
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("grpc connect: %w", err)
+	}
+	defer conn.Close()
+
+	stream, err := statisticspb.NewStatisticsServiceClient(conn).RebuildRollups(ctx, &statisticspb.RebuildRollupsRequest{
+		From: timestamppb.New(from),
+		To:   timestamppb.New(to),
+	})
+	if err != nil {
+		return fmt.Errorf("RebuildRollups: %w", err)
+	}
+	for {
+		p, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("RebuildRollups: %w", err)
+		}
+		last := "-"
+		if p.LastEventTime != nil {
+			last = p.LastEventTime.AsTime().Format(time.RFC3339)
+		}
+		log.Printf("rebuild %s: %s, %d events, last at %s", p.RebuildId, p.Phase, p.EventsProcessed, last)
+	}
+}
+
+// replay replays the streams into a fresh database with the configuration
+// of the service.
+func replay(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	db := fs.String("db", "", "empty database to replay into")
+	streams := fs.String("streams", "", "comma separated streams to replay, all by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *db == "" {
+		return errors.New("-db is required")
+	}
+
+	cfg, err := config.New()
+	if err != nil {
+		return fmt.Errorf("config load error: %w", err)
+	}
+	if *db == cfg.Mongo.Database {
+		return fmt.Errorf("-db must not be the database of the service, %s", *db)
+	}
+	var names []string
+	if *streams != "" {
+		names = strings.Split(*streams, ",")
+	}
+	return app.Replay(ctx, cfg, *db, names)
+}
+
+// parseTime reads an RFC3339 time or a date, the start of the day in UTC.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("required")
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCustomerNotFound is returned for a user without order or payment events.
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// ErrInvalidSegmentQuery is returned for an unknown segment or a limit out of
+// range.
+var ErrInvalidSegmentQuery = errors.New("invalid segment query")
+
+// Customer sums up the orders of a user and the payments for them. Orders,
+// FirstOrderAt, LastOrderAt and OrderValue leave deleted orders out; Paid is
+// every completed payment, the lifetime value of the customer.
+type Customer struct {
+	UserID       string
+	Orders       int64
+	FirstOrderAt time.Time
+	LastOrderAt  time.Time
+	OrderValue   []Money
+	Paid         []Money
+}
+
+// PaidIn returns what the customer paid in currency.
+func (c Customer) PaidIn(currency string) int64 {
+	for _, m := range c.Paid {
+		if m.Currency == currency {
+			return m.Amount
+		}
+	}
+	return 0
+}
+
+// RFM segments, from the recency of the last order, the number of orders and
+// the value paid.
+const (
+	SegmentChampions      = "champions"       // recent, frequent and high value
+	SegmentLoyal          = "loyal"           // frequent, not lapsed
+	SegmentNew            = "new"             // recent first order
+	SegmentPromising      = "promising"       // recent, a few orders
+	SegmentNeedsAttention = "needs_attention" // neither recent nor lapsed, a few orders
+	SegmentAtRisk         = "at_risk"         // lapsed, frequent or high value
+	SegmentHibernating    = "hibernating"     // lapsed, a few low value orders
+)
+
+// Segments lists the RFM segments.
+var Segments = []string{
+	SegmentChampions, SegmentLoyal, SegmentNew, SegmentPromising,
+	SegmentNeedsAttention, SegmentAtRisk, SegmentHibernating,
+}
+
+// RFMThresholds split customers into segments. A last order within Recent is
+// recent, one older than Lapsed is lapsed; FrequentOrders orders make a
+// customer frequent and paying HighValue in Currency a high value one.
+type RFMThresholds struct {
+	Recent         time.Duration
+	Lapsed         time.Duration
+	FrequentOrders int64
+	HighValue      int64 // minor units
+	Currency       string
+}
+
+func (t RFMThresholds) Validate() error {
+	if t.Recent <= 0 || t.Lapsed <= t.Recent {
+		return fmt.Errorf("rfm: lapsed %s must be after recent %s", t.Lapsed, t.Recent)
+	}
+	if t.FrequentOrders < 2 {
+		return fmt.Errorf("rfm: frequent orders must be at least 2, not %d", t.FrequentOrders)
+	}
+	return nil
+}
+
+// Segment returns the segment of the customer at now, empty if they have no
+// orders.
+func (t RFMThresholds) Segment(c Customer, now time.Time) string {
+	if c.Orders == 0 {
+		return ""
+	}
+	frequent := c.Orders >= t.FrequentOrders
+	highValue := c.PaidIn(t.Currency) >= t.HighValue
+	since := now.Sub(c.LastOrderAt)
+	switch {
+	case since <= t.Recent:
+		switch {
+		case frequent && highValue:
+			return SegmentChampions
+		case frequent:
+			return SegmentLoyal
+		case c.Orders == 1:
+			return SegmentNew
+		default:
+			return SegmentPromising
+		}
+	case since <= t.Lapsed:
+		if frequent {
+			return SegmentLoyal
+		}
+		return SegmentNeedsAttention
+	default:
+		if frequent || highValue {
+			return SegmentAtRisk
+		}
+		return SegmentHibernating
+	}
+}
+
+// SegmentQuery pages through the customers of Segment by user ID, starting
+// after the user ID After.
+type SegmentQuery struct {
+	Segment string
+	Limit   int
+	After   string
+}
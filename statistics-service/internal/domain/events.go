@@ -2,7 +2,33 @@ package domain
 
 import "time"
 
+// Event types. They are the default NATS subjects of the events.
+const (
+	EventOrderCreated = "order.created"
+	EventOrderUpdated = "order.updated"
+	EventOrderDeleted = "order.deleted"
+
+	EventPaymentCreated = "payment.created"
+
+	EventProductCreated = "product.created"
+	EventProductUpdated = "product.updated"
+	EventProductDeleted = "product.deleted"
+
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+
+	// clickstream events of the API gateway, the steps of the funnels
+	EventCategoryBrowsed  = "clickstream.category.browsed"
+	EventProductViewed    = "clickstream.product.viewed"
+	EventOrderPlaced      = "clickstream.order.created"
+	EventPaymentAttempted = "clickstream.payment.attempted"
+	EventPaymentCompleted = "clickstream.payment.completed"
+)
+
+// Event is the normalized form every ingested message is stored in.
 type Event struct {
+	EventID   string                 `json:"event_id,omitempty"` // the same for every delivery of the event
 	UserID    string                 `json:"user_id"`
 	EntityID  string                 `json:"entity_id"`
 	EntityKey string                 `json:"entity_key"` // e.g. "order_id", "product_id"
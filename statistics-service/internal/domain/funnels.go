@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidFunnelQuery is returned for an empty or too long time range, or
+// unknown or repeated steps.
+var ErrInvalidFunnelQuery = errors.New("invalid funnel query")
+
+// Funnel steps and the clickstream events they are taken from.
+const (
+	StepCategoryBrowsed  = "category_browsed"
+	StepProductViewed    = "product_viewed"
+	StepOrderCreated     = "order_created"
+	StepPaymentAttempted = "payment_attempted"
+	StepPaymentCompleted = "payment_completed"
+)
+
+// FunnelStepEvents maps the steps to their event types.
+var FunnelStepEvents = map[string]string{
+	StepCategoryBrowsed:  EventCategoryBrowsed,
+	StepProductViewed:    EventProductViewed,
+	StepOrderCreated:     EventOrderPlaced,
+	StepPaymentAttempted: EventPaymentAttempted,
+	StepPaymentCompleted: EventPaymentCompleted,
+}
+
+// DefaultFunnel is the funnel of a query without steps. Browsing a category
+// is left out as many visitors come straight to a product.
+var DefaultFunnel = []string{StepProductViewed, StepOrderCreated, StepPaymentAttempted, StepPaymentCompleted}
+
+// IsClickstream tells whether the event type is one of the clickstream.
+func IsClickstream(eventType string) bool {
+	switch eventType {
+	case EventCategoryBrowsed, EventProductViewed, EventOrderPlaced, EventPaymentAttempted, EventPaymentCompleted:
+		return true
+	}
+	return false
+}
+
+// FunnelQuery follows the visitors through Steps, in order, from From
+// (inclusive) to To (exclusive). A visitor is the session of clickstream
+// events that have one and the user otherwise.
+type FunnelQuery struct {
+	From  time.Time
+	To    time.Time
+	Steps []string
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// WatchConfig sets how live statistics are pushed. Interval is the least
+// time between two pushes to a watcher, LowStock the stock at or below
+// which a product is listed as low on stock.
+type WatchConfig struct {
+	Interval time.Duration
+	LowStock int64
+}
+
+// LiveStatistics are the counters of the live dashboards at a time. Orders
+// are those created in the Minutes whole minutes before it, revenue and
+// active users those of its UTC day.
+type LiveStatistics struct {
+	Orders           int64
+	Minutes          int
+	RevenueToday     []Money
+	ActiveUsersToday int64
+	LowStock         []LowStockProduct
+}
+
+// LowStockProduct is a product with Stock units left as of its latest
+// event.
+type LowStockProduct struct {
+	ProductID string
+	Name      string
+	Stock     int64
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "errors"
+
+// ErrInvalidRelatedQuery is returned for a query without a product or with a
+// limit out of range.
+var ErrInvalidRelatedQuery = errors.New("invalid related products query")
+
+// RelatedQuery selects up to Limit products bought together with ProductID
+// in at least MinOrders orders, most often first.
+type RelatedQuery struct {
+	ProductID string
+	Limit     int
+	MinOrders int64
+}
+
+// ProductPairs is what is known about the products bought together with a
+// product. Orders and Baskets are the orders with the product and all
+// orders counted, which support and confidence are shares of.
+type ProductPairs struct {
+	CategoryID string
+	Orders     int64
+	Baskets    int64
+	Related    []RelatedProduct
+}
+
+// RelatedProduct is a product bought together with another one in Orders
+// orders. Name and CategoryID are taken from the latest product events.
+type RelatedProduct struct {
+	ProductID  string
+	Name       string
+	CategoryID string
+	Orders     int64
+}
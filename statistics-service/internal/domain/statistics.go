@@ -2,25 +2,358 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	statisticspb "github.com/Neroframe/ecommerce-platform/statistics-service/proto"
 )
 
+// ErrDuplicateEvent is returned when an event with the same ID was stored
+// before, i.e. the message was redelivered.
+var ErrDuplicateEvent = errors.New("duplicate event")
+
+// ErrInvalidSalesQuery is returned for an empty time range or an unknown
+// granularity.
+var ErrInvalidSalesQuery = errors.New("invalid sales query")
+
+// ErrInvalidTopQuery is returned for an empty time range, an unknown metric
+// or dimension, or a limit out of range.
+var ErrInvalidTopQuery = errors.New("invalid top products query")
+
+// ErrInvalidTimeZone is returned for a time zone that is not an IANA name.
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+
+// ErrInvalidHeatmapQuery is returned for an empty time range.
+var ErrInvalidHeatmapQuery = errors.New("invalid heatmap query")
+
+// ErrInvalidRebuild is returned for an empty rebuild range or one that is
+// too long.
+var ErrInvalidRebuild = errors.New("invalid rollup rebuild")
+
+// ErrRollupsSwapping is returned while a rollup rebuild swaps its rollups in:
+// events are held back until it is done, and only one rebuild swaps at a
+// time.
+var ErrRollupsSwapping = errors.New("rollups are being swapped")
+
+// ErrInvalidActiveUsersQuery is returned for an empty or too long time range,
+// an unknown count mode, or an exact count of days no longer kept.
+var ErrInvalidActiveUsersQuery = errors.New("invalid active users query")
+
+// ErrInvalidRetentionQuery is returned for an empty time range, a granularity
+// other than week or month, or a number of periods out of range.
+var ErrInvalidRetentionQuery = errors.New("invalid retention query")
+
+// ErrActiveUsersExpired is returned when the users active in a bucket are no
+// longer kept to be counted exactly.
+var ErrActiveUsersExpired = errors.New("active users of the range expired")
+
+// OrderState is what is known about an order from its events, whatever order
+// they arrived in. Status is the one of the newest update, and a deleted
+// order stays deleted.
+type OrderState struct {
+	OrderID  string
+	UserID   string
+	Status   string
+	StatusAt time.Time
+	Created  bool
+	Deleted  bool
+
+	// set by the create event
+	PlacedAt time.Time
+	Total    Money
+	Units    int64
+	Items    []OrderLine
+
+	// SalesCounted tells whether the items are in the product and category
+	// sales.
+	SalesCounted bool
+	// PairsCounted tells whether the products are in the pairs of products
+	// bought together. PairsLimit is the most distinct products whose pairs
+	// were counted at the time, 0 for orders counted before it was kept.
+	PairsCounted bool
+	PairsLimit   int
+}
+
+// OrderLine is an item of an order. CategoryID is the category of the
+// product when its sale was counted.
+type OrderLine struct {
+	ProductID  string
+	CategoryID string
+	Quantity   int64
+	UnitPrice  int64 // minor units of the order currency
+}
+
+// Sales periods.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week" // weeks start on Monday
+	GranularityMonth = "month"
+)
+
+// SalesQuery selects the sales in one currency between From (inclusive) and
+// To (exclusive), grouped into periods of Granularity in UTC.
+type SalesQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	Currency    string
+}
+
+// SalesPeriod sums up one period. Revenue is what was paid in the period,
+// OrderValue what the orders placed in it are worth at their line prices.
+// Deleted orders are left out.
+type SalesPeriod struct {
+	Start      time.Time
+	Revenue    int64 // minor units
+	Orders     int64
+	OrderValue int64 // minor units
+	Units      int64
+}
+
+// Rollups, the time buckets every event is counted in.
+const (
+	RollupMinute = "minute"
+	RollupHour   = "hour"
+	RollupDay    = "day"
+)
+
+// EventFilter selects stored events by their Timestamp, From inclusive and
+// To exclusive, and by the order they were stored in: after the event stored
+// as AfterID and before the time StoredBefore. Zero fields do not filter.
+type EventFilter struct {
+	From         time.Time
+	To           time.Time
+	AfterID      string
+	StoredBefore time.Time
+}
+
+// Phases of a rollup rebuild.
+const (
+	RebuildScan    = "scan"
+	RebuildCatchUp = "catch_up"
+	RebuildSwap    = "swap"
+	RebuildDone    = "done"
+)
+
+// RebuildProgress is reported after every batch of events and phase change
+// of a rollup rebuild.
+type RebuildProgress struct {
+	ID            string
+	Phase         string
+	Events        int64
+	LastEventTime time.Time
+}
+
+// Rankings of the best selling products and categories.
+const (
+	MetricUnits   = "units"
+	MetricRevenue = "revenue"
+	MetricOrders  = "orders"
+
+	DimensionProduct  = "product"
+	DimensionCategory = "category"
+)
+
+// TopQuery selects the Limit best selling products or categories by Metric
+// from the daily sales between From (inclusive) and To (exclusive), both at
+// midnight UTC.
+type TopQuery struct {
+	From      time.Time
+	To        time.Time
+	Metric    string
+	Dimension string
+	Currency  string
+	Limit     int
+
+	// CategoryID, if set, ranks the products of the category only, by
+	// their current category.
+	CategoryID string
+	// Exclude leaves products or categories out of the ranking.
+	Exclude []string
+}
+
+// TopEntry is the sales of a product or category. Name and CategoryID are
+// taken from the latest product and category events.
+type TopEntry struct {
+	ID         string
+	Name       string
+	CategoryID string // products only
+	Units      int64
+	Revenue    int64 // minor units
+	Orders     int64
+}
+
+// HeatmapQuery selects the orders of UserID, or of all users if empty,
+// placed between From (inclusive) and To (exclusive). A zero From or To
+// leaves the range open.
+type HeatmapQuery struct {
+	UserID   string
+	TimeZone string // IANA name
+	From     time.Time
+	To       time.Time
+}
+
+// RetentionQuery selects the cohorts of users who first ordered from From
+// (inclusive) to To (exclusive), by periods of Granularity in UTC, and
+// follows them for Periods periods.
+type RetentionQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	Periods     int
+}
+
+// RetentionCell counts the Users of the cohort starting at Cohort who
+// ordered Offset periods later; offset 0 is the size of the cohort.
+type RetentionCell struct {
+	Cohort time.Time
+	Offset int
+	Users  int64
+}
+
+// How distinct users are counted.
+const (
+	CountAuto        = "auto"
+	CountExact       = "exact"
+	CountApproximate = "approximate"
+)
+
+// ActiveUsersQuery counts the distinct users active from From (inclusive) to
+// To (exclusive).
+type ActiveUsersQuery struct {
+	From time.Time
+	To   time.Time
+	Mode string
+}
+
+// HeatmapCell counts the orders placed on an ISO weekday, 1 is Monday, at
+// an hour of the day in the time zone of the query.
+type HeatmapCell struct {
+	Weekday int
+	Hour    int
+	Orders  int64
+}
+
+// Transactor runs fn in a database transaction. Repositories called with the
+// context passed to fn take part in the transaction.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// ActiveUserSketches keeps an approximate set of the users active on every
+// UTC day. Adding a user twice changes nothing.
+type ActiveUserSketches interface {
+	// Add adds the users of the events to the days of the events.
+	Add(ctx context.Context, evts []Event) error
+	// Count estimates the distinct users of the days from from to to.
+	Count(ctx context.Context, from, to time.Time) (int64, error)
+}
+
 type StatisticsRepository interface {
 	// gRPC
 	CountOrdersByUser(ctx context.Context, userID string) (int32, error)
 	CountTotalUsers(ctx context.Context) (int32, error)
 	CountDailyActiveUsers(ctx context.Context) (int32, error)
+	// CountActiveUsers counts the distinct users of the buckets of
+	// granularity starting from from to to. It returns
+	// ErrActiveUsersExpired if some of them are no longer kept.
+	CountActiveUsers(ctx context.Context, granularity string, from, to time.Time) (int64, error)
+	// SalesByPeriod returns the periods with sales, oldest first.
+	SalesByPeriod(ctx context.Context, q SalesQuery) ([]SalesPeriod, error)
+	// TopProducts returns the best selling products or categories, best
+	// first.
+	TopProducts(ctx context.Context, q TopQuery) ([]TopEntry, error)
+	// OrderHeatmap returns the cells with orders. Deleted orders are left
+	// out.
+	OrderHeatmap(ctx context.Context, q HeatmapQuery) ([]HeatmapCell, error)
+	// RetentionCohorts returns the cells with users. The first order of a
+	// user is looked for among all their orders, not only those of the
+	// range.
+	RetentionCohorts(ctx context.Context, q RetentionQuery) ([]RetentionCell, error)
+	// GetCustomer returns ErrCustomerNotFound for an unknown user.
+	GetCustomer(ctx context.Context, userID string) (*Customer, error)
+	// ListSegment returns the customers of q.Segment at now by user ID.
+	ListSegment(ctx context.Context, q SegmentQuery, t RFMThresholds, now time.Time) ([]Customer, error)
+	// ProductPairs returns the products most often bought together with
+	// q.ProductID, along with its current category.
+	ProductPairs(ctx context.Context, q RelatedQuery) (*ProductPairs, error)
+	// LiveStatistics reads the counters of the live dashboards at now, the
+	// orders of the minutes whole minutes before it and at most limit
+	// products with a stock of threshold or less, fewest first.
+	LiveStatistics(ctx context.Context, now time.Time, minutes int, threshold int64, limit int) (*LiveStatistics, error)
+	// ConversionFunnel returns the number of visitors who got to each step
+	// of q.Steps.
+	ConversionFunnel(ctx context.Context, q FunnelQuery) ([]int64, error)
 
 	// NATS
+	// InsertEvent returns ErrDuplicateEvent if evt.EventID is stored already.
 	InsertEvent(ctx context.Context, evt Event) error
+	// ApplyOrderEvent folds an order event into the state of its order and
+	// returns the new state. Applying an event twice changes nothing.
+	ApplyOrderEvent(ctx context.Context, evt Event) (*OrderState, error)
+	// ApplyPaymentEvent records the payment in the state of its order, which
+	// has no user yet if the order was not created.
+	ApplyPaymentEvent(ctx context.Context, evt Event) (*OrderState, error)
+	// RefreshCustomer sums up the orders and payments of the user again.
+	RefreshCustomer(ctx context.Context, userID string) error
+	// CountProductSales adds the items of the order to the daily product and
+	// category sales of the day it was placed, or takes them out again if
+	// remove is set, and records that in the order state.
+	CountProductSales(ctx context.Context, state *OrderState, remove bool) error
+	// CountProductPairs adds one to the orders of every pair of distinct
+	// products of the order and of each product, or takes it out again if
+	// remove is set, and records that in the order state.
+	CountProductPairs(ctx context.Context, state *OrderState, remove bool) error
+	// ApplyProductEvent and ApplyCategoryEvent keep the names and categories
+	// the rankings are shown with.
+	ApplyProductEvent(ctx context.Context, evt Event) error
+	ApplyCategoryEvent(ctx context.Context, evt Event) error
+	// UpdateRollups counts the event in its minute, hour and day buckets.
+	UpdateRollups(ctx context.Context, evt Event) error
+
+	// Rebuilds
+	// ListEvents calls fn with the stored events of f in batches, in the
+	// order they were stored, along with the storage ID of the last one.
+	ListEvents(ctx context.Context, f EventFilter, batchSize int, fn func(evts []Event, lastID string) error) error
+	// AddToRollupBuild counts events in the rollups of the rebuild id, which
+	// are kept apart from the live ones.
+	AddToRollupBuild(ctx context.Context, id string, evts []Event) error
+	// SwapRollupBuild replaces the live buckets from from to to by those of
+	// the rebuild. Run it in a transaction, a day at a time.
+	SwapRollupBuild(ctx context.Context, id string, from, to time.Time) error
+	// DropRollupBuild removes the rollups of the rebuild.
+	DropRollupBuild(ctx context.Context, id string) error
+	// LeaseRollupSwap takes the swap lease for the rebuild id until ttl from
+	// now, or extends it. It returns ErrRollupsSwapping if another rebuild
+	// holds the lease or the lease of id ran out.
+	LeaseRollupSwap(ctx context.Context, id string, ttl time.Duration) error
+	// ReleaseRollupSwap gives up the lease of the rebuild id.
+	ReleaseRollupSwap(ctx context.Context, id string) error
+	// RollupSwapLeased reports whether a rebuild holds the swap lease.
+	RollupSwapLeased(ctx context.Context) (bool, error)
 }
 
 type StatisticsUsecase interface {
 	// gRPC read methods
-	GetUserOrdersStatistics(ctx context.Context, userID string) (*statisticspb.UserOrderStatisticsResponse, error)
+	GetUserOrdersStatistics(ctx context.Context, userID, timeZone string) (*statisticspb.UserOrderStatisticsResponse, error)
 	GetUserStatistics(ctx context.Context) (*statisticspb.UserStatisticsResponse, error)
+	GetSalesStatistics(ctx context.Context, q SalesQuery) (*statisticspb.SalesStatisticsResponse, error)
+	GetTopProducts(ctx context.Context, q TopQuery) (*statisticspb.TopProductsResponse, error)
+	GetOrderHeatmap(ctx context.Context, q HeatmapQuery) (*statisticspb.OrderHeatmapResponse, error)
+	GetActiveUsers(ctx context.Context, q ActiveUsersQuery) (*statisticspb.ActiveUsersResponse, error)
+	GetRetentionCohorts(ctx context.Context, q RetentionQuery) (*statisticspb.RetentionCohortsResponse, error)
+	GetCustomerProfile(ctx context.Context, userID string) (*statisticspb.CustomerProfileResponse, error)
+	ListSegmentMembers(ctx context.Context, q SegmentQuery) (*statisticspb.SegmentMembersResponse, error)
+	GetConversionFunnel(ctx context.Context, q FunnelQuery) (*statisticspb.ConversionFunnelResponse, error)
+	GetRelatedProducts(ctx context.Context, q RelatedQuery) (*statisticspb.RelatedProductsResponse, error)
+
+	// WatchStatistics calls send with the live statistics whenever they
+	// change, until ctx is done or send fails.
+	WatchStatistics(ctx context.Context, minInterval time.Duration, send func(*statisticspb.LiveStatistics) error) error
 
 	// NATS event handler
 	HandleEvent(ctx context.Context, evt Event) error
+
+	// admin
+	RebuildRollups(ctx context.Context, from, to time.Time, progress func(RebuildProgress) error) error
 }
@@ -2,31 +2,195 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
 	statisticspb "github.com/Neroframe/ecommerce-platform/statistics-service/proto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var _ domain.StatisticsUsecase = (*StatisticsUsecase)(nil)
 
+// defaultSalesRange is the range of a sales, top products or active users
+// query without From.
+const defaultSalesRange = 30 * 24 * time.Hour
+
+const (
+	defaultTopLimit = 10
+	maxTopLimit     = 100
+)
+
+const (
+	// exactActiveUsersDays is the longest range active users are counted
+	// exactly in by default.
+	exactActiveUsersDays = 7
+	maxActiveUsersRange  = 366 * 24 * time.Hour
+)
+
+const (
+	defaultRetentionPeriods = 12
+	maxRetentionPeriods     = 52
+	maxRetentionCohorts     = 104
+)
+
+const (
+	defaultSegmentLimit = 100
+	maxSegmentLimit     = 1000
+)
+
+const (
+	defaultRelatedLimit = 10
+	maxRelatedLimit     = 50
+)
+
+const (
+	// liveOrderMinutes is how many whole minutes orders per minute are
+	// averaged over.
+	liveOrderMinutes = 5
+	maxLowStock      = 20
+	// liveRefresh is the longest a watcher goes without a fresh read, for
+	// counters that change with time alone and events handled by other
+	// instances of the service.
+	liveRefresh = time.Minute
+)
+
+const (
+	defaultFunnelRange = 30 * 24 * time.Hour
+	maxFunnelRange     = 90 * 24 * time.Hour
+)
+
+const (
+	rebuildBatchSize = 1000
+	maxRebuildRange  = 31 * 24 * time.Hour
+	// rebuildSettle is how long events can take to commit: the ID of an
+	// event is made when it is inserted, and MongoDB aborts transactions
+	// after a minute. Events stored longer ago are all there to be scanned.
+	rebuildSettle = 2 * time.Minute
+	// swapLeaseTTL is how long the swap lease is taken for at a time. It is
+	// extended before the catch up and by every day swapped.
+	swapLeaseTTL = time.Minute
+)
+
 type StatisticsUsecase struct {
-	repo  domain.StatisticsRepository
-	cache domain.EventCache
+	repo             domain.StatisticsRepository
+	cache            domain.EventCache
+	tx               domain.Transactor
+	activeUsers      domain.ActiveUserSketches
+	rfm              domain.RFMThresholds
+	watch            domain.WatchConfig
+	relatedMinOrders int64
+	defaultCurrency  string
+
+	// changed is closed and replaced when an event was handled, waking up
+	// the watchers.
+	changedMu sync.Mutex
+	changed   chan struct{}
 }
 
-func NewStatisticsUsecase(repo domain.StatisticsRepository, cache domain.EventCache) *StatisticsUsecase {
-	return &StatisticsUsecase{repo: repo, cache: cache}
+func NewStatisticsUsecase(repo domain.StatisticsRepository, cache domain.EventCache, tx domain.Transactor, activeUsers domain.ActiveUserSketches, rfm domain.RFMThresholds, watch domain.WatchConfig, relatedMinOrders int64, defaultCurrency string) *StatisticsUsecase {
+	return &StatisticsUsecase{
+		repo:             repo,
+		cache:            cache,
+		tx:               tx,
+		activeUsers:      activeUsers,
+		rfm:              rfm,
+		watch:            watch,
+		relatedMinOrders: relatedMinOrders,
+		defaultCurrency:  defaultCurrency,
+		changed:          make(chan struct{}),
+	}
 }
 
-func (u *StatisticsUsecase) GetUserOrdersStatistics(ctx context.Context, userID string) (*statisticspb.UserOrderStatisticsResponse, error) {
+// GetUserOrdersStatistics reports the peak order hour in timeZone, UTC if
+// empty; ties go to the earliest hour.
+func (u *StatisticsUsecase) GetUserOrdersStatistics(ctx context.Context, userID, timeZone string) (*statisticspb.UserOrderStatisticsResponse, error) {
+	timeZone, err := checkTimeZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
 	total, err := u.repo.CountOrdersByUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	return &statisticspb.UserOrderStatisticsResponse{TotalOrders: total}, nil
+	cells, err := u.repo.OrderHeatmap(ctx, domain.HeatmapQuery{UserID: userID, TimeZone: timeZone})
+	if err != nil {
+		return nil, fmt.Errorf("repo.OrderHeatmap: %w", err)
+	}
+
+	var byHour [24]int64
+	for _, c := range cells {
+		if c.Hour >= 0 && c.Hour < 24 {
+			byHour[c.Hour] += c.Orders
+		}
+	}
+	resp := &statisticspb.UserOrderStatisticsResponse{TotalOrders: total}
+	peak := 0
+	for h := range byHour {
+		if byHour[h] > byHour[peak] {
+			peak = h
+		}
+	}
+	if byHour[peak] > 0 {
+		resp.PeakOrderHour = fmt.Sprintf("%02d:00", peak)
+	}
+	return resp, nil
 }
 
+// GetOrderHeatmap returns all 168 cells of the week, empty ones included.
+func (u *StatisticsUsecase) GetOrderHeatmap(ctx context.Context, q domain.HeatmapQuery) (*statisticspb.OrderHeatmapResponse, error) {
+	timeZone, err := checkTimeZone(q.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	q.TimeZone = timeZone
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidHeatmapQuery)
+	}
+
+	cells, err := u.repo.OrderHeatmap(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("repo.OrderHeatmap: %w", err)
+	}
+
+	resp := &statisticspb.OrderHeatmapResponse{
+		TimeZone: timeZone,
+		Cells:    make([]*statisticspb.HeatmapCell, 7*24),
+	}
+	for i := range resp.Cells {
+		resp.Cells[i] = &statisticspb.HeatmapCell{Weekday: int32(i/24 + 1), Hour: int32(i % 24)}
+	}
+	for _, c := range cells {
+		if c.Weekday < 1 || c.Weekday > 7 || c.Hour < 0 || c.Hour > 23 {
+			continue
+		}
+		resp.Cells[(c.Weekday-1)*24+c.Hour].OrderCount = c.Orders
+		resp.TotalOrders += c.Orders
+	}
+	return resp, nil
+}
+
+// checkTimeZone returns the IANA name of timeZone, UTC if empty.
+func checkTimeZone(timeZone string) (string, error) {
+	if timeZone == "" {
+		return "UTC", nil
+	}
+	// Local is the zone of this process, unknown to MongoDB
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil || timeZone == "Local" {
+		return "", fmt.Errorf("%w %q", domain.ErrInvalidTimeZone, timeZone)
+	}
+	return loc.String(), nil
+}
+
+// GetUserStatistics counts the daily active users over the last 24 hours,
+// the weekly and monthly ones over the last 7 and 30 UTC days.
 func (u *StatisticsUsecase) GetUserStatistics(ctx context.Context) (*statisticspb.UserStatisticsResponse, error) {
 	totalUsers, err := u.repo.CountTotalUsers(ctx)
 	if err != nil {
@@ -36,30 +200,854 @@ func (u *StatisticsUsecase) GetUserStatistics(ctx context.Context) (*statisticsp
 	if err != nil {
 		return nil, err
 	}
+
+	tomorrow := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	weeklyActive, _, err := u.countActiveUsers(ctx, domain.ActiveUsersQuery{
+		From: tomorrow.Add(-7 * 24 * time.Hour),
+		To:   tomorrow,
+		Mode: domain.CountAuto,
+	})
+	if err != nil {
+		return nil, err
+	}
+	monthlyActive, _, err := u.countActiveUsers(ctx, domain.ActiveUsersQuery{
+		From: tomorrow.Add(-30 * 24 * time.Hour),
+		To:   tomorrow,
+		Mode: domain.CountAuto,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &statisticspb.UserStatisticsResponse{
-		TotalUsers:       totalUsers,
-		DailyActiveUsers: dailyActive,
+		TotalUsers:         totalUsers,
+		DailyActiveUsers:   dailyActive,
+		WeeklyActiveUsers:  int32(weeklyActive),
+		MonthlyActiveUsers: int32(monthlyActive),
 	}, nil
 }
 
-// All data (orders & intventory items) must be stored in db and cache, but if you try to retrieve data, then it should be fetched from cache.
+// GetActiveUsers counts the distinct users active in whole UTC days,
+// defaulting to the last 30 days.
+func (u *StatisticsUsecase) GetActiveUsers(ctx context.Context, q domain.ActiveUsersQuery) (*statisticspb.ActiveUsersResponse, error) {
+	q.From, q.To = wholeDays(q.From, q.To)
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidActiveUsersQuery)
+	}
+	if q.To.Sub(q.From) > maxActiveUsersRange {
+		return nil, fmt.Errorf("%w: at most %d days at once", domain.ErrInvalidActiveUsersQuery, int(maxActiveUsersRange/(24*time.Hour)))
+	}
+	switch q.Mode {
+	case "":
+		q.Mode = domain.CountAuto
+	case domain.CountAuto, domain.CountExact, domain.CountApproximate:
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", domain.ErrInvalidActiveUsersQuery, q.Mode)
+	}
+
+	n, exact, err := u.countActiveUsers(ctx, q)
+	if errors.Is(err, domain.ErrActiveUsersExpired) {
+		return nil, fmt.Errorf("%w: exact counts are only kept for the last week", domain.ErrInvalidActiveUsersQuery)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &statisticspb.ActiveUsersResponse{
+		ActiveUsers: n,
+		Exact:       exact,
+		From:        timestamppb.New(q.From),
+		To:          timestamppb.New(q.To),
+	}, nil
+}
+
+// countActiveUsers counts the users of whole UTC days from the day markers of
+// the rollups or estimates them from the sketches, and tells whether the
+// count is exact. Ranges of up to exactActiveUsersDays are counted exactly
+// in auto mode while their markers are kept.
+func (u *StatisticsUsecase) countActiveUsers(ctx context.Context, q domain.ActiveUsersQuery) (int64, bool, error) {
+	exact := q.Mode == domain.CountExact ||
+		q.Mode == domain.CountAuto && q.To.Sub(q.From) <= exactActiveUsersDays*24*time.Hour
+	if exact {
+		n, err := u.repo.CountActiveUsers(ctx, domain.RollupDay, q.From, q.To)
+		switch {
+		case err == nil:
+			return n, true, nil
+		case q.Mode == domain.CountExact || !errors.Is(err, domain.ErrActiveUsersExpired):
+			return 0, false, fmt.Errorf("repo.CountActiveUsers: %w", err)
+		}
+	}
+
+	n, err := u.activeUsers.Count(ctx, q.From, q.To)
+	if err != nil {
+		return 0, false, fmt.Errorf("activeUsers.Count: %w", err)
+	}
+	return n, false, nil
+}
+
+// GetSalesStatistics defaults to the last 30 days in the default currency.
+func (u *StatisticsUsecase) GetSalesStatistics(ctx context.Context, q domain.SalesQuery) (*statisticspb.SalesStatisticsResponse, error) {
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultSalesRange)
+	}
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidSalesQuery)
+	}
+	switch q.Granularity {
+	case "":
+		q.Granularity = domain.GranularityDay
+	case domain.GranularityDay, domain.GranularityWeek, domain.GranularityMonth:
+	default:
+		return nil, fmt.Errorf("%w: unknown granularity %q", domain.ErrInvalidSalesQuery, q.Granularity)
+	}
+	if q.Currency == "" {
+		q.Currency = u.defaultCurrency
+	}
+	q.From, q.To = q.From.UTC(), q.To.UTC()
+
+	periods, err := u.repo.SalesByPeriod(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("repo.SalesByPeriod: %w", err)
+	}
+
+	resp := &statisticspb.SalesStatisticsResponse{CurrencyCode: q.Currency}
+	var total domain.SalesPeriod
+	for _, p := range periods {
+		stats := salesStatistics(p, q.Currency)
+		stats.PeriodStart = timestamppb.New(p.Start)
+		resp.Periods = append(resp.Periods, stats)
+
+		total.Revenue += p.Revenue
+		total.Orders += p.Orders
+		total.OrderValue += p.OrderValue
+		total.Units += p.Units
+	}
+	resp.Total = salesStatistics(total, q.Currency)
+	return resp, nil
+}
+
+func salesStatistics(p domain.SalesPeriod, currency string) *statisticspb.SalesStatistics {
+	var average int64
+	if p.Orders > 0 {
+		// rounded half away from zero
+		average = (p.OrderValue + p.Orders/2) / p.Orders
+	}
+	return &statisticspb.SalesStatistics{
+		Revenue:           toMoneyPB(domain.Money{Amount: p.Revenue, Currency: currency}),
+		OrderCount:        p.Orders,
+		OrderValue:        toMoneyPB(domain.Money{Amount: p.OrderValue, Currency: currency}),
+		AverageOrderValue: toMoneyPB(domain.Money{Amount: average, Currency: currency}),
+		UnitsSold:         p.Units,
+	}
+}
+
+// GetTopProducts ranks the products or categories over whole UTC days,
+// defaulting to the last 30 days by units sold in the default currency.
+func (u *StatisticsUsecase) GetTopProducts(ctx context.Context, q domain.TopQuery) (*statisticspb.TopProductsResponse, error) {
+	q.From, q.To = wholeDays(q.From, q.To)
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidTopQuery)
+	}
+	switch q.Metric {
+	case "":
+		q.Metric = domain.MetricUnits
+	case domain.MetricUnits, domain.MetricRevenue, domain.MetricOrders:
+	default:
+		return nil, fmt.Errorf("%w: unknown metric %q", domain.ErrInvalidTopQuery, q.Metric)
+	}
+	switch q.Dimension {
+	case "":
+		q.Dimension = domain.DimensionProduct
+	case domain.DimensionProduct, domain.DimensionCategory:
+	default:
+		return nil, fmt.Errorf("%w: unknown dimension %q", domain.ErrInvalidTopQuery, q.Dimension)
+	}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultTopLimit
+	case q.Limit < 0 || q.Limit > maxTopLimit:
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidTopQuery, maxTopLimit)
+	}
+	if q.Currency == "" {
+		q.Currency = u.defaultCurrency
+	}
+
+	entries, err := u.repo.TopProducts(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("repo.TopProducts: %w", err)
+	}
+
+	resp := &statisticspb.TopProductsResponse{
+		CurrencyCode: q.Currency,
+		From:         timestamppb.New(q.From),
+		To:           timestamppb.New(q.To),
+		Entries:      make([]*statisticspb.TopProductsEntry, len(entries)),
+	}
+	for i, e := range entries {
+		resp.Entries[i] = &statisticspb.TopProductsEntry{
+			Rank:       int32(i + 1),
+			Id:         e.ID,
+			Name:       e.Name,
+			CategoryId: e.CategoryID,
+			UnitsSold:  e.Units,
+			Revenue:    toMoneyPB(domain.Money{Amount: e.Revenue, Currency: q.Currency}),
+			OrderCount: e.Orders,
+		}
+	}
+	return resp, nil
+}
+
+// GetRetentionCohorts returns a cohort for every period from From to To,
+// widened to whole periods, and the users of each who ordered again in the
+// periods after it that have begun. It defaults to the cohorts of the last
+// 12 weeks.
+func (u *StatisticsUsecase) GetRetentionCohorts(ctx context.Context, q domain.RetentionQuery) (*statisticspb.RetentionCohortsResponse, error) {
+	resp := &statisticspb.RetentionCohortsResponse{}
+	switch q.Granularity {
+	case "", domain.GranularityWeek:
+		q.Granularity = domain.GranularityWeek
+		resp.Granularity = statisticspb.Granularity_GRANULARITY_WEEK
+	case domain.GranularityMonth:
+		resp.Granularity = statisticspb.Granularity_GRANULARITY_MONTH
+	default:
+		return nil, fmt.Errorf("%w: granularity must be week or month", domain.ErrInvalidRetentionQuery)
+	}
+	switch {
+	case q.Periods == 0:
+		q.Periods = defaultRetentionPeriods
+	case q.Periods < 0 || q.Periods > maxRetentionPeriods:
+		return nil, fmt.Errorf("%w: periods must be between 1 and %d", domain.ErrInvalidRetentionQuery, maxRetentionPeriods)
+	}
+	resp.Periods = int32(q.Periods)
+
+	now := time.Now().UTC()
+	if q.To.IsZero() {
+		q.To = now
+	}
+	if q.From.IsZero() {
+		q.From = addPeriods(q.To, q.Granularity, -defaultRetentionPeriods)
+	}
+	q.From = periodStart(q.From, q.Granularity)
+	if start := periodStart(q.To, q.Granularity); !start.Equal(q.To) {
+		q.To = addPeriods(start, q.Granularity, 1)
+	}
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidRetentionQuery)
+	}
+	if addPeriods(q.From, q.Granularity, maxRetentionCohorts).Before(q.To) {
+		return nil, fmt.Errorf("%w: at most %d cohorts at once", domain.ErrInvalidRetentionQuery, maxRetentionCohorts)
+	}
+
+	cells, err := u.repo.RetentionCohorts(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("repo.RetentionCohorts: %w", err)
+	}
+	users := make(map[time.Time][]int64)
+	for _, c := range cells {
+		if c.Offset < 0 || c.Offset > q.Periods {
+			continue
+		}
+		row, ok := users[c.Cohort]
+		if !ok {
+			row = make([]int64, q.Periods+1)
+			users[c.Cohort] = row
+		}
+		row[c.Offset] = c.Users
+	}
+
+	for start := q.From; start.Before(q.To); start = addPeriods(start, q.Granularity, 1) {
+		row := users[start]
+		if row == nil {
+			row = make([]int64, q.Periods+1)
+		}
+		cohort := &statisticspb.RetentionCohort{PeriodStart: timestamppb.New(start), Size: row[0]}
+		for k := 1; k <= q.Periods && !addPeriods(start, q.Granularity, k).After(now); k++ {
+			var share float64
+			if row[0] > 0 {
+				share = float64(row[k]) / float64(row[0])
+			}
+			cohort.Retained = append(cohort.Retained, row[k])
+			cohort.Retention = append(cohort.Retention, share)
+		}
+		resp.Cohorts = append(resp.Cohorts, cohort)
+	}
+	return resp, nil
+}
+
+var funnelSteps = map[string]statisticspb.FunnelStep{
+	domain.StepCategoryBrowsed:  statisticspb.FunnelStep_FUNNEL_STEP_CATEGORY_BROWSED,
+	domain.StepProductViewed:    statisticspb.FunnelStep_FUNNEL_STEP_PRODUCT_VIEWED,
+	domain.StepOrderCreated:     statisticspb.FunnelStep_FUNNEL_STEP_ORDER_CREATED,
+	domain.StepPaymentAttempted: statisticspb.FunnelStep_FUNNEL_STEP_PAYMENT_ATTEMPTED,
+	domain.StepPaymentCompleted: statisticspb.FunnelStep_FUNNEL_STEP_PAYMENT_COMPLETED,
+}
+
+// GetConversionFunnel counts the visitors who took each step after the ones
+// before it, and the share of them who went on from the previous step. It
+// defaults to the last 30 days and domain.DefaultFunnel.
+func (u *StatisticsUsecase) GetConversionFunnel(ctx context.Context, q domain.FunnelQuery) (*statisticspb.ConversionFunnelResponse, error) {
+	if len(q.Steps) == 0 {
+		q.Steps = domain.DefaultFunnel
+	}
+	seen := make(map[string]bool, len(q.Steps))
+	for _, step := range q.Steps {
+		if _, ok := domain.FunnelStepEvents[step]; !ok {
+			return nil, fmt.Errorf("%w: unknown step %q", domain.ErrInvalidFunnelQuery, step)
+		}
+		if seen[step] {
+			return nil, fmt.Errorf("%w: step %s is repeated", domain.ErrInvalidFunnelQuery, step)
+		}
+		seen[step] = true
+	}
+	if q.To.IsZero() {
+		q.To = time.Now().UTC()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultFunnelRange)
+	}
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", domain.ErrInvalidFunnelQuery)
+	}
+	if q.To.Sub(q.From) > maxFunnelRange {
+		return nil, fmt.Errorf("%w: range must be at most %s", domain.ErrInvalidFunnelQuery, maxFunnelRange)
+	}
+
+	visitors, err := u.repo.ConversionFunnel(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("repo.ConversionFunnel: %w", err)
+	}
+
+	resp := &statisticspb.ConversionFunnelResponse{
+		From: timestamppb.New(q.From),
+		To:   timestamppb.New(q.To),
+	}
+	for i, step := range q.Steps {
+		stats := &statisticspb.FunnelStepStatistics{Step: funnelSteps[step], Visitors: visitors[i], Conversion: 1}
+		if i > 0 {
+			stats.Conversion = ratio(visitors[i], visitors[i-1])
+		}
+		stats.DropOff = 1 - stats.Conversion
+		stats.Overall = ratio(visitors[i], visitors[0])
+		resp.Steps = append(resp.Steps, stats)
+	}
+	return resp, nil
+}
+
+// ratio returns n / of, 0 if of is 0.
+func ratio(n, of int64) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of)
+}
+
+// GetRelatedProducts returns the products most often bought together with
+// the product, with their support, the share of all orders that have both,
+// and confidence, the share of the orders of the product that have the
+// other one too. When too few products have enough orders in common, the
+// best sellers of the last 30 days in the category of the product fill up
+// the list.
+func (u *StatisticsUsecase) GetRelatedProducts(ctx context.Context, q domain.RelatedQuery) (*statisticspb.RelatedProductsResponse, error) {
+	if q.ProductID == "" {
+		return nil, fmt.Errorf("%w: product_id is required", domain.ErrInvalidRelatedQuery)
+	}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultRelatedLimit
+	case q.Limit < 0 || q.Limit > maxRelatedLimit:
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidRelatedQuery, maxRelatedLimit)
+	}
+	q.MinOrders = u.relatedMinOrders
+
+	pairs, err := u.repo.ProductPairs(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("repo.ProductPairs: %w", err)
+	}
+	resp := &statisticspb.RelatedProductsResponse{ProductId: q.ProductID, Orders: pairs.Orders}
+	exclude := []string{q.ProductID}
+	for _, p := range pairs.Related {
+		resp.Products = append(resp.Products, &statisticspb.RelatedProduct{
+			ProductId:  p.ProductID,
+			Name:       p.Name,
+			CategoryId: p.CategoryID,
+			Orders:     p.Orders,
+			Support:    ratio(p.Orders, pairs.Baskets),
+			Confidence: ratio(p.Orders, pairs.Orders),
+			Source:     statisticspb.RelatedSource_RELATED_SOURCE_BOUGHT_TOGETHER,
+		})
+		exclude = append(exclude, p.ProductID)
+	}
+	if len(resp.Products) >= q.Limit || pairs.CategoryID == "" {
+		return resp, nil
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	top, err := u.repo.TopProducts(ctx, domain.TopQuery{
+		From:       to.Add(-defaultSalesRange),
+		To:         to,
+		Metric:     domain.MetricUnits,
+		Dimension:  domain.DimensionProduct,
+		Currency:   u.defaultCurrency,
+		Limit:      q.Limit - len(resp.Products),
+		CategoryID: pairs.CategoryID,
+		Exclude:    exclude,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repo.TopProducts: %w", err)
+	}
+	for _, e := range top {
+		resp.Products = append(resp.Products, &statisticspb.RelatedProduct{
+			ProductId:  e.ID,
+			Name:       e.Name,
+			CategoryId: e.CategoryID,
+			Source:     statisticspb.RelatedSource_RELATED_SOURCE_CATEGORY_TOP_SELLER,
+		})
+	}
+	return resp, nil
+}
+
+var customerSegments = map[string]statisticspb.CustomerSegment{
+	"":                           statisticspb.CustomerSegment_CUSTOMER_SEGMENT_UNSPECIFIED,
+	domain.SegmentChampions:      statisticspb.CustomerSegment_CUSTOMER_SEGMENT_CHAMPIONS,
+	domain.SegmentLoyal:          statisticspb.CustomerSegment_CUSTOMER_SEGMENT_LOYAL,
+	domain.SegmentNew:            statisticspb.CustomerSegment_CUSTOMER_SEGMENT_NEW,
+	domain.SegmentPromising:      statisticspb.CustomerSegment_CUSTOMER_SEGMENT_PROMISING,
+	domain.SegmentNeedsAttention: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_NEEDS_ATTENTION,
+	domain.SegmentAtRisk:         statisticspb.CustomerSegment_CUSTOMER_SEGMENT_AT_RISK,
+	domain.SegmentHibernating:    statisticspb.CustomerSegment_CUSTOMER_SEGMENT_HIBERNATING,
+}
+
+// GetCustomerProfile returns the sums of the customer and their segment now.
+func (u *StatisticsUsecase) GetCustomerProfile(ctx context.Context, userID string) (*statisticspb.CustomerProfileResponse, error) {
+	c, err := u.repo.GetCustomer(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo.GetCustomer: %w", err)
+	}
+	return &statisticspb.CustomerProfileResponse{Profile: u.customerProfile(*c, time.Now())}, nil
+}
+
+// ListSegmentMembers pages through the customers in a segment now, 100 at a
+// time by default.
+func (u *StatisticsUsecase) ListSegmentMembers(ctx context.Context, q domain.SegmentQuery) (*statisticspb.SegmentMembersResponse, error) {
+	segment, ok := customerSegments[q.Segment]
+	if !ok || q.Segment == "" {
+		return nil, fmt.Errorf("%w: unknown segment %q", domain.ErrInvalidSegmentQuery, q.Segment)
+	}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultSegmentLimit
+	case q.Limit < 0 || q.Limit > maxSegmentLimit:
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidSegmentQuery, maxSegmentLimit)
+	}
+
+	now := time.Now()
+	customers, err := u.repo.ListSegment(ctx, q, u.rfm, now)
+	if err != nil {
+		return nil, fmt.Errorf("repo.ListSegment: %w", err)
+	}
+	resp := &statisticspb.SegmentMembersResponse{Segment: segment}
+	for _, c := range customers {
+		resp.Customers = append(resp.Customers, u.customerProfile(c, now))
+	}
+	if len(customers) == q.Limit {
+		resp.NextPageToken = customers[len(customers)-1].UserID
+	}
+	return resp, nil
+}
+
+func (u *StatisticsUsecase) customerProfile(c domain.Customer, now time.Time) *statisticspb.CustomerProfile {
+	p := &statisticspb.CustomerProfile{
+		UserId:     c.UserID,
+		Segment:    customerSegments[u.rfm.Segment(c, now)],
+		OrderCount: c.Orders,
+	}
+	if c.Orders > 0 {
+		p.FirstOrderAt = timestamppb.New(c.FirstOrderAt)
+		p.LastOrderAt = timestamppb.New(c.LastOrderAt)
+		p.RecencyDays = int32(now.Sub(c.LastOrderAt) / (24 * time.Hour))
+	}
+	for _, m := range c.OrderValue {
+		p.OrderValue = append(p.OrderValue, toMoneyPB(m))
+	}
+	for _, m := range c.Paid {
+		p.LifetimeValue = append(p.LifetimeValue, toMoneyPB(m))
+	}
+	return p
+}
+
+// wholeDays widens from and to to whole UTC days, rounding to up to the
+// next day. A zero to is now and a zero from defaultSalesRange before to.
+func wholeDays(from, to time.Time) (time.Time, time.Time) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultSalesRange)
+	}
+	from = from.UTC().Truncate(24 * time.Hour)
+	day := to.UTC().Truncate(24 * time.Hour)
+	if !day.Equal(to) {
+		day = day.Add(24 * time.Hour)
+	}
+	return from, day
+}
+
+// periodStart returns the start of the week, on Monday, or month of t in UTC.
+func periodStart(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	if granularity == domain.GranularityMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+func addPeriods(t time.Time, granularity string, n int) time.Time {
+	if granularity == domain.GranularityMonth {
+		return t.AddDate(0, n, 0)
+	}
+	return t.AddDate(0, 0, 7*n)
+}
+
+func toMoneyPB(m domain.Money) *statisticspb.Money {
+	units, nanos := m.Units()
+	return &statisticspb.Money{CurrencyCode: m.Currency, Units: units, Nanos: nanos}
+}
+
+// HandleEvent stores the event and folds it into the order states, product
+// sales and pairs, product and category names, customers and rollups in one
+// transaction, which is run again on write conflicts between consumers. A
+// redelivered event is dropped by its ID, and order events go through the
+// order state so that a late update cannot bring a deleted order back. While
+// a rebuild swaps rollups in, it returns ErrRollupsSwapping.
 func (u *StatisticsUsecase) HandleEvent(ctx context.Context, evt domain.Event) error {
-	// Store in Mongo
-	if err := u.repo.InsertEvent(ctx, evt); err != nil {
-		return fmt.Errorf("repo.InsertEvent: %w", err)
+	deleted := false
+	err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// checked in the transaction, so that every attempt which has not
+		// seen the lease started before it was taken
+		swapping, err := u.repo.RollupSwapLeased(ctx)
+		if err != nil {
+			return fmt.Errorf("repo.RollupSwapLeased: %w", err)
+		}
+		if swapping {
+			return domain.ErrRollupsSwapping
+		}
+
+		// Store in Mongo
+		if err := u.repo.InsertEvent(ctx, evt); err != nil {
+			return fmt.Errorf("repo.InsertEvent: %w", err)
+		}
+
+		switch evt.EventType {
+		case domain.EventOrderCreated, domain.EventOrderUpdated, domain.EventOrderDeleted:
+			state, err := u.repo.ApplyOrderEvent(ctx, evt)
+			if err != nil {
+				return fmt.Errorf("repo.ApplyOrderEvent: %w", err)
+			}
+			deleted = state.Deleted
+			if err := u.countProductSales(ctx, state); err != nil {
+				return err
+			}
+			if err := u.countProductPairs(ctx, state); err != nil {
+				return err
+			}
+			if err := u.refreshCustomer(ctx, state); err != nil {
+				return err
+			}
+
+		case domain.EventPaymentCreated:
+			state, err := u.repo.ApplyPaymentEvent(ctx, evt)
+			if err != nil {
+				return fmt.Errorf("repo.ApplyPaymentEvent: %w", err)
+			}
+			if err := u.refreshCustomer(ctx, state); err != nil {
+				return err
+			}
+
+		case domain.EventProductCreated, domain.EventProductUpdated, domain.EventProductDeleted:
+			if err := u.repo.ApplyProductEvent(ctx, evt); err != nil {
+				return fmt.Errorf("repo.ApplyProductEvent: %w", err)
+			}
+
+		case domain.EventCategoryCreated, domain.EventCategoryUpdated, domain.EventCategoryDeleted:
+			if err := u.repo.ApplyCategoryEvent(ctx, evt); err != nil {
+				return fmt.Errorf("repo.ApplyCategoryEvent: %w", err)
+			}
+		}
+
+		if err := u.repo.UpdateRollups(ctx, evt); err != nil {
+			return fmt.Errorf("repo.UpdateRollups: %w", err)
+		}
+		return nil
+	})
+	duplicate := errors.Is(err, domain.ErrDuplicateEvent)
+	if err != nil && !duplicate {
+		return err
+	}
+	// also for a duplicate, whose first delivery may have failed here
+	if err := u.activeUsers.Add(ctx, []domain.Event{evt}); err != nil {
+		return fmt.Errorf("activeUsers.Add: %w", err)
+	}
+	if duplicate {
+		log.Printf("[Statistics] Skip duplicate event id=%s type=%s", evt.EventID, evt.EventType)
+		return nil
+	}
+	u.notifyChange()
+
+	if deleted {
+		u.cache.Delete(evt.EntityID)
+		return nil
 	}
 
 	// Store inmemory cache
 	switch evt.EventType {
-	case "order.created", "order.updated", "product.created", "product.updated":
+	case domain.EventOrderCreated, domain.EventOrderUpdated,
+		domain.EventProductCreated, domain.EventProductUpdated,
+		domain.EventCategoryCreated, domain.EventCategoryUpdated:
 		u.cache.Set(&evt)
 
-	case "order.deleted", "product.deleted":
+	case domain.EventOrderDeleted, domain.EventProductDeleted, domain.EventCategoryDeleted:
 		u.cache.Delete(evt.EntityID)
 
+	case domain.EventPaymentCreated:
+
+	case domain.EventCategoryBrowsed, domain.EventProductViewed, domain.EventOrderPlaced,
+		domain.EventPaymentAttempted, domain.EventPaymentCompleted:
+
 	default:
-		fmt.Printf("unknown event type: %s", evt.EventType)
+		log.Printf("[Statistics] Unknown event type: %s", evt.EventType)
+	}
+
+	return nil
+}
+
+// WatchStatistics sends the live statistics at once and again after events
+// were handled, waiting minInterval, but at least the configured interval,
+// between two reads. Events handled meanwhile are sent together, and a read
+// that changed nothing is not sent.
+func (u *StatisticsUsecase) WatchStatistics(ctx context.Context, minInterval time.Duration, send func(*statisticspb.LiveStatistics) error) error {
+	interval := max(minInterval, u.watch.Interval)
+	var last *statisticspb.LiveStatistics
+	for {
+		// taken before the read so that no change is missed
+		changed := u.changes()
+		read := time.Now()
+		live, err := u.liveStatistics(ctx, read)
+		if err != nil {
+			return err
+		}
+		if !proto.Equal(live, last) {
+			last = live
+			out := proto.Clone(live).(*statisticspb.LiveStatistics)
+			out.At = timestamppb.New(read)
+			if err := send(out); err != nil {
+				return err
+			}
+		}
+
+		refresh := time.NewTimer(liveRefresh)
+		select {
+		case <-ctx.Done():
+			refresh.Stop()
+			return ctx.Err()
+		case <-changed:
+			refresh.Stop()
+		case <-refresh.C:
+		}
+		throttle := time.NewTimer(time.Until(read.Add(interval)))
+		select {
+		case <-ctx.Done():
+			throttle.Stop()
+			return ctx.Err()
+		case <-throttle.C:
+		}
+	}
+}
+
+// liveStatistics reads the live statistics at now, without the time.
+func (u *StatisticsUsecase) liveStatistics(ctx context.Context, now time.Time) (*statisticspb.LiveStatistics, error) {
+	stats, err := u.repo.LiveStatistics(ctx, now, liveOrderMinutes, u.watch.LowStock, maxLowStock)
+	if err != nil {
+		return nil, fmt.Errorf("repo.LiveStatistics: %w", err)
+	}
+	live := &statisticspb.LiveStatistics{
+		OrdersPerMinute:  float64(stats.Orders) / float64(stats.Minutes),
+		ActiveUsersToday: stats.ActiveUsersToday,
+	}
+	for _, m := range stats.RevenueToday {
+		live.RevenueToday = append(live.RevenueToday, toMoneyPB(m))
+	}
+	for _, p := range stats.LowStock {
+		live.LowStockProducts = append(live.LowStockProducts, &statisticspb.LowStockProduct{
+			ProductId: p.ProductID,
+			Name:      p.Name,
+			Stock:     int32(p.Stock),
+		})
+	}
+	return live, nil
+}
+
+// changes returns a channel that is closed once the next event was handled.
+func (u *StatisticsUsecase) changes() <-chan struct{} {
+	u.changedMu.Lock()
+	defer u.changedMu.Unlock()
+	return u.changed
+}
+
+func (u *StatisticsUsecase) notifyChange() {
+	u.changedMu.Lock()
+	defer u.changedMu.Unlock()
+	close(u.changed)
+	u.changed = make(chan struct{})
+}
+
+// RebuildRollups recomputes the rollups of whole UTC days from the stored
+// events into rollups of its own and swaps them in a day at a time once it
+// has caught up. Events are handled meanwhile. For the swap it takes a lease
+// that holds events back on every instance, waits for those being handled to
+// commit, counts the last of them and swaps, so every event of the range is
+// counted once, either from the scan or from a catch up.
+func (u *StatisticsUsecase) RebuildRollups(ctx context.Context, from, to time.Time, progress func(domain.RebuildProgress) error) error {
+	from, to = wholeDays(from, to)
+	if !from.Before(to) {
+		return fmt.Errorf("%w: from must be before to", domain.ErrInvalidRebuild)
+	}
+	if to.Sub(from) > maxRebuildRange {
+		return fmt.Errorf("%w: at most %d days at once", domain.ErrInvalidRebuild, int(maxRebuildRange/(24*time.Hour)))
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("rebuild id: %w", err)
+	}
+	p := domain.RebuildProgress{ID: hex.EncodeToString(id), Phase: domain.RebuildScan}
+	log.Printf("[Statistics] Rebuild %s of rollups from %s to %s", p.ID, from, to)
+	defer func() {
+		if err := u.repo.DropRollupBuild(context.WithoutCancel(ctx), p.ID); err != nil {
+			log.Printf("[Statistics] Rebuild %s cleanup: %v", p.ID, err)
+		}
+	}()
+	if err := progress(p); err != nil {
+		return err
+	}
+
+	filter := domain.EventFilter{From: from, To: to, StoredBefore: time.Now().Add(-rebuildSettle)}
+	add := func(evts []domain.Event, lastID string) error {
+		if err := u.repo.AddToRollupBuild(ctx, p.ID, evts); err != nil {
+			return fmt.Errorf("repo.AddToRollupBuild: %w", err)
+		}
+		// events from before the sketches existed are added to them as well
+		if err := u.activeUsers.Add(ctx, evts); err != nil {
+			return fmt.Errorf("activeUsers.Add: %w", err)
+		}
+		filter.AfterID = lastID
+		p.Events += int64(len(evts))
+		p.LastEventTime = evts[len(evts)-1].Timestamp
+		return progress(p)
+	}
+	if err := u.repo.ListEvents(ctx, filter, rebuildBatchSize, add); err != nil {
+		return fmt.Errorf("scan: %w", err)
 	}
 
+	// events stored during the scan, up to those that may not have
+	// committed yet
+	p.Phase = domain.RebuildCatchUp
+	if err := progress(p); err != nil {
+		return err
+	}
+	filter.StoredBefore = time.Now().Add(-rebuildSettle)
+	if err := u.repo.ListEvents(ctx, filter, rebuildBatchSize, add); err != nil {
+		return fmt.Errorf("catch up: %w", err)
+	}
+
+	p.Phase = domain.RebuildSwap
+	if err := progress(p); err != nil {
+		return err
+	}
+	if err := u.repo.LeaseRollupSwap(ctx, p.ID, rebuildSettle+swapLeaseTTL); err != nil {
+		return fmt.Errorf("lease: %w", err)
+	}
+	defer func() {
+		if err := u.repo.ReleaseRollupSwap(context.WithoutCancel(ctx), p.ID); err != nil {
+			log.Printf("[Statistics] Rebuild %s release: %v", p.ID, err)
+		}
+	}()
+	// transactions that did not see the lease commit or abort meanwhile
+	settle := time.NewTimer(rebuildSettle)
+	select {
+	case <-ctx.Done():
+		settle.Stop()
+		return ctx.Err()
+	case <-settle.C:
+	}
+	if err := u.repo.LeaseRollupSwap(ctx, p.ID, swapLeaseTTL); err != nil {
+		return fmt.Errorf("lease: %w", err)
+	}
+	filter.StoredBefore = time.Time{}
+	if err := u.repo.ListEvents(ctx, filter, rebuildBatchSize, add); err != nil {
+		return fmt.Errorf("catch up: %w", err)
+	}
+
+	for day := from; day.Before(to); day = day.Add(24 * time.Hour) {
+		err := u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			// fails the swap if the lease ran out and events came in
+			if err := u.repo.LeaseRollupSwap(ctx, p.ID, swapLeaseTTL); err != nil {
+				return err
+			}
+			return u.repo.SwapRollupBuild(ctx, p.ID, day, day.Add(24*time.Hour))
+		})
+		if err != nil {
+			return fmt.Errorf("swap %s: %w", day.Format(time.DateOnly), err)
+		}
+	}
+
+	log.Printf("[Statistics] Rebuild %s done, %d events", p.ID, p.Events)
+	p.Phase = domain.RebuildDone
+	return progress(p)
+}
+
+// refreshCustomer sums up the customer of the order again. An order whose
+// create event is not in yet has no user, its customer is refreshed once it
+// comes in.
+func (u *StatisticsUsecase) refreshCustomer(ctx context.Context, state *domain.OrderState) error {
+	if state.UserID == "" {
+		return nil
+	}
+	if err := u.repo.RefreshCustomer(ctx, state.UserID); err != nil {
+		return fmt.Errorf("repo.RefreshCustomer: %w", err)
+	}
+	return nil
+}
+
+// countProductSales counts a placed order in the product sales once its
+// create event is in, and takes it out again once it is deleted. Orders
+// from before items had prices have no currency and are left out.
+func (u *StatisticsUsecase) countProductSales(ctx context.Context, state *domain.OrderState) error {
+	switch {
+	case state.Created && !state.Deleted && !state.SalesCounted && state.Total.Currency != "":
+		if err := u.repo.CountProductSales(ctx, state, false); err != nil {
+			return fmt.Errorf("repo.CountProductSales: %w", err)
+		}
+	case state.Deleted && state.SalesCounted:
+		if err := u.repo.CountProductSales(ctx, state, true); err != nil {
+			return fmt.Errorf("repo.CountProductSales: %w", err)
+		}
+	}
+	return nil
+}
+
+func (u *StatisticsUsecase) countProductPairs(ctx context.Context, state *domain.OrderState) error {
+	switch {
+	case state.Created && !state.Deleted && !state.PairsCounted:
+		if err := u.repo.CountProductPairs(ctx, state, false); err != nil {
+			return fmt.Errorf("repo.CountProductPairs: %w", err)
+		}
+	case state.Deleted && state.PairsCounted:
+		if err := u.repo.CountProductPairs(ctx, state, true); err != nil {
+			return fmt.Errorf("repo.CountProductPairs: %w", err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/ecommerce-platform/statistics-service/internal/domain"
+	statisticspb "github.com/Neroframe/ecommerce-platform/statistics-service/proto"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCustomerProfileSegment(t *testing.T) {
+	now := date(2025, 6, 1)
+	u := &StatisticsUsecase{rfm: domain.RFMThresholds{
+		Recent:         30 * 24 * time.Hour,
+		Lapsed:         90 * 24 * time.Hour,
+		FrequentOrders: 5,
+		HighValue:      50000,
+		Currency:       "USD",
+	}}
+	recent := now.Add(-30 * 24 * time.Hour)
+	lapsed := now.Add(-90 * 24 * time.Hour)
+	usd := func(amount int64) []domain.Money { return []domain.Money{{Amount: amount, Currency: "USD"}} }
+
+	tests := []struct {
+		name   string
+		orders int64
+		last   time.Time
+		paid   []domain.Money
+		want   statisticspb.CustomerSegment
+	}{
+		{name: "no orders", want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_UNSPECIFIED},
+		{name: "champion at the recent boundary", orders: 5, last: recent, paid: usd(50000), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_CHAMPIONS},
+		{name: "high value in another currency", orders: 5, last: recent, paid: []domain.Money{{Amount: 50000, Currency: "EUR"}}, want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_LOYAL},
+		{name: "just below high value", orders: 5, last: recent, paid: usd(49999), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_LOYAL},
+		{name: "new", orders: 1, last: recent, paid: usd(50000), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_NEW},
+		{name: "just below frequent", orders: 4, last: recent, paid: usd(50000), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_PROMISING},
+		{name: "loyal past the recent boundary", orders: 5, last: recent.Add(-time.Second), paid: usd(50000), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_LOYAL},
+		{name: "needs attention past the recent boundary", orders: 1, last: recent.Add(-time.Second), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_NEEDS_ATTENTION},
+		{name: "needs attention at the lapsed boundary", orders: 4, last: lapsed, paid: usd(50000), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_NEEDS_ATTENTION},
+		{name: "at risk past the lapsed boundary, high value", orders: 1, last: lapsed.Add(-time.Second), paid: usd(50000), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_AT_RISK},
+		{name: "at risk past the lapsed boundary, frequent", orders: 5, last: lapsed.Add(-time.Second), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_AT_RISK},
+		{name: "hibernating", orders: 4, last: lapsed.Add(-time.Second), paid: usd(49999), want: statisticspb.CustomerSegment_CUSTOMER_SEGMENT_HIBERNATING},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := domain.Customer{UserID: "u1", Orders: tt.orders, FirstOrderAt: tt.last, LastOrderAt: tt.last, Paid: tt.paid}
+			p := u.customerProfile(c, now)
+			if p.GetSegment() != tt.want {
+				t.Errorf("Segment = %s, want %s", p.GetSegment(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomerProfileRecencyDays(t *testing.T) {
+	now := date(2025, 6, 1)
+	u := &StatisticsUsecase{rfm: domain.RFMThresholds{Recent: time.Hour, Lapsed: 2 * time.Hour, FrequentOrders: 2}}
+
+	tests := []struct {
+		last time.Time
+		want int32
+	}{
+		{last: now, want: 0},
+		{last: now.Add(-24*time.Hour + time.Second), want: 0},
+		{last: now.Add(-24 * time.Hour), want: 1},
+		{last: date(2024, 12, 31), want: 152},
+	}
+	for _, tt := range tests {
+		p := u.customerProfile(domain.Customer{Orders: 1, LastOrderAt: tt.last}, now)
+		if p.GetRecencyDays() != tt.want {
+			t.Errorf("RecencyDays for a last order at %s = %d, want %d", tt.last, p.GetRecencyDays(), tt.want)
+		}
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	tests := []struct {
+		t           time.Time
+		granularity string
+		want        time.Time
+	}{
+		{date(2025, 1, 1), domain.GranularityWeek, date(2024, 12, 30)},
+		{date(2024, 12, 29), domain.GranularityWeek, date(2024, 12, 23)},
+		{date(2024, 12, 30).Add(23 * time.Hour), domain.GranularityWeek, date(2024, 12, 30)},
+		{time.Date(2025, 1, 5, 23, 30, 0, 0, time.FixedZone("UTC-1", -3600)), domain.GranularityWeek, date(2025, 1, 6)},
+		{date(2025, 1, 31), domain.GranularityMonth, date(2025, 1, 1)},
+		{date(2024, 12, 31).Add(23 * time.Hour), domain.GranularityMonth, date(2024, 12, 1)},
+	}
+	for _, tt := range tests {
+		if got := periodStart(tt.t, tt.granularity); !got.Equal(tt.want) {
+			t.Errorf("periodStart(%s, %s) = %s, want %s", tt.t, tt.granularity, got, tt.want)
+		}
+	}
+}
+
+// retentionRepository returns cells for RetentionCohorts and keeps the query
+// it was asked.
+type retentionRepository struct {
+	domain.StatisticsRepository
+	cells []domain.RetentionCell
+	query domain.RetentionQuery
+}
+
+func (r *retentionRepository) RetentionCohorts(_ context.Context, q domain.RetentionQuery) ([]domain.RetentionCell, error) {
+	r.query = q
+	return r.cells, nil
+}
+
+func TestGetRetentionCohorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    domain.RetentionQuery
+		cells    []domain.RetentionCell
+		wantFrom time.Time
+		wantTo   time.Time
+		want     map[time.Time][]int64 // size, then retained per period
+	}{
+		{
+			name:     "weeks across the new year",
+			query:    domain.RetentionQuery{Granularity: domain.GranularityWeek, From: date(2024, 12, 25), To: date(2025, 1, 8), Periods: 2},
+			wantFrom: date(2024, 12, 23),
+			wantTo:   date(2025, 1, 13),
+			cells: []domain.RetentionCell{
+				{Cohort: date(2024, 12, 23), Offset: 0, Users: 10},
+				{Cohort: date(2024, 12, 23), Offset: 1, Users: 4},
+				{Cohort: date(2024, 12, 23), Offset: 2, Users: 2},
+				{Cohort: date(2024, 12, 23), Offset: 3, Users: 1},
+				{Cohort: date(2025, 1, 6), Offset: 0, Users: 5},
+				{Cohort: date(2025, 1, 6), Offset: 2, Users: 1},
+			},
+			want: map[time.Time][]int64{
+				date(2024, 12, 23): {10, 4, 2},
+				date(2024, 12, 30): {0, 0, 0},
+				date(2025, 1, 6):   {5, 0, 1},
+			},
+		},
+		{
+			name:     "months across the new year",
+			query:    domain.RetentionQuery{Granularity: domain.GranularityMonth, From: date(2024, 11, 15), To: date(2025, 2, 1), Periods: 3},
+			wantFrom: date(2024, 11, 1),
+			wantTo:   date(2025, 2, 1),
+			cells: []domain.RetentionCell{
+				{Cohort: date(2024, 12, 1), Offset: 0, Users: 8},
+				{Cohort: date(2024, 12, 1), Offset: 1, Users: 6},
+				{Cohort: date(2024, 12, 1), Offset: 3, Users: 2},
+				{Cohort: date(2025, 1, 1), Offset: 0, Users: 3},
+			},
+			want: map[time.Time][]int64{
+				date(2024, 11, 1): {0, 0, 0, 0},
+				date(2024, 12, 1): {8, 6, 0, 2},
+				date(2025, 1, 1):  {3, 0, 0, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &retentionRepository{cells: tt.cells}
+			u := &StatisticsUsecase{repo: repo}
+
+			resp, err := u.GetRetentionCohorts(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("GetRetentionCohorts: %v", err)
+			}
+			if !repo.query.From.Equal(tt.wantFrom) || !repo.query.To.Equal(tt.wantTo) {
+				t.Errorf("queried from %s to %s, want from %s to %s", repo.query.From, repo.query.To, tt.wantFrom, tt.wantTo)
+			}
+
+			got := make(map[time.Time][]int64)
+			for _, c := range resp.GetCohorts() {
+				got[c.GetPeriodStart().AsTime()] = append([]int64{c.GetSize()}, c.GetRetained()...)
+				for k, share := range c.GetRetention() {
+					want := 0.0
+					if c.GetSize() > 0 {
+						want = float64(c.GetRetained()[k]) / float64(c.GetSize())
+					}
+					if share != want {
+						t.Errorf("cohort %s retention[%d] = %v, want %v", c.GetPeriodStart().AsTime(), k, share, want)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cohorts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetRetentionCohortsCurrentPeriod checks that periods which have not
+// begun are left out of the current cohort.
+func TestGetRetentionCohortsCurrentPeriod(t *testing.T) {
+	for _, granularity := range []string{domain.GranularityWeek, domain.GranularityMonth} {
+		t.Run(granularity, func(t *testing.T) {
+			u := &StatisticsUsecase{repo: &retentionRepository{}}
+
+			resp, err := u.GetRetentionCohorts(context.Background(), domain.RetentionQuery{Granularity: granularity, Periods: 4})
+			if err != nil {
+				t.Fatalf("GetRetentionCohorts: %v", err)
+			}
+			cohorts := resp.GetCohorts()
+			if len(cohorts) != defaultRetentionPeriods+1 {
+				t.Fatalf("%d cohorts, want %d", len(cohorts), defaultRetentionPeriods+1)
+			}
+			for i, c := range cohorts {
+				// cohort i has begun len(cohorts)-1-i periods after its own
+				want := min(len(cohorts)-1-i, 4)
+				if len(c.GetRetained()) != want {
+					t.Errorf("cohort %s has %d periods, want %d", c.GetPeriodStart().AsTime(), len(c.GetRetained()), want)
+				}
+			}
+		})
+	}
+}
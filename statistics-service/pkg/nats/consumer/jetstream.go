@@ -0,0 +1,207 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	natscl "github.com/Neroframe/ecommerce-platform/statistics-service/pkg/nats"
+	"github.com/Neroframe/ecommerce-platform/statistics-service/pkg/safe"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Headers added to dead-lettered messages.
+const (
+	HeaderDeadLetterSubject    = "Dead-Letter-Subject"
+	HeaderDeadLetterError      = "Dead-Letter-Error"
+	HeaderDeadLetterDeliveries = "Dead-Letter-Deliveries"
+)
+
+// JetStream pulls messages from durable consumers, one per stream, and acks
+// them once the handler succeeded. A failed message is redelivered after the
+// next Backoff delay; after MaxDeliver attempts, or right away when the
+// handler returns a natscl.Permanent error, it is published to
+// DeadLetterSubject.<subject> and terminated. A natscl.Deferred error is
+// redelivered however often it was delivered.
+type JetStream struct {
+	cfg       JetStreamConfig
+	consumers []JetStreamConsumerConfig
+	client    *natscl.Client
+	wg        *sync.WaitGroup
+	stop      chan struct{}
+}
+
+type JetStreamConfig struct {
+	Durable           string
+	AckWait           time.Duration
+	MaxDeliver        int
+	Backoff           []time.Duration
+	DeadLetterSubject string
+}
+
+// JetStreamConsumerConfig is a stream to consume in full. Subjects are the
+// subjects of the stream; consumers are not filtered by them, because
+// several filter subjects need NATS 2.10.
+type JetStreamConsumerConfig struct {
+	Stream   string
+	Subjects []string
+	Handler  natscl.MsgHandler
+}
+
+func NewJetStream(client *natscl.Client, cfg JetStreamConfig) *JetStream {
+	return &JetStream{
+		cfg:    cfg,
+		client: client,
+		wg:     &sync.WaitGroup{},
+		stop:   make(chan struct{}),
+	}
+}
+
+func (c *JetStream) Subscribe(cfg ...JetStreamConsumerConfig) {
+	c.consumers = append(c.consumers, cfg...)
+}
+
+// Start creates or updates the durable consumers and starts pulling messages
+// from them.
+func (c *JetStream) Start(ctx context.Context, errCh chan<- error) {
+	for i := range c.consumers {
+		cfg := c.consumers[i]
+
+		cons, err := c.client.JS.CreateOrUpdateConsumer(ctx, cfg.Stream, jetstream.ConsumerConfig{
+			Durable:       c.cfg.Durable,
+			DeliverPolicy: jetstream.DeliverAllPolicy,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       c.cfg.AckWait,
+			// attempts are counted here so that the last one can be
+			// dead-lettered, the server never gives up on a message
+			MaxDeliver:    -1,
+			MaxAckPending: natscl.MaxPending,
+		})
+		if err != nil {
+			errCh <- fmt.Errorf("failed to create JetStream consumer for stream %v: %w", cfg.Stream, err)
+			return
+		}
+
+		c.wg.Add(1)
+		go safe.Do(ctx, func() {
+			defer c.wg.Done()
+			c.consume(ctx, cons, cfg)
+		})
+	}
+}
+
+// Stop waits for the messages being handled, the rest is redelivered later.
+func (c *JetStream) Stop() {
+	close(c.stop)
+	c.wg.Wait()
+}
+
+func (c *JetStream) consume(ctx context.Context, cons jetstream.Consumer, cfg JetStreamConsumerConfig) {
+	log.Println("consuming JetStream stream started", "stream:", cfg.Stream, "subjects:", cfg.Subjects)
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		batch, err := cons.Fetch(natscl.MaxPending, jetstream.FetchMaxWait(natscl.MaxWaitFetch))
+		if err != nil {
+			log.Println("failed to fetch messages", "stream:", cfg.Stream, "error:", err)
+			time.Sleep(natscl.MaxWaitFetch)
+			continue
+		}
+
+		for msg := range batch.Messages() {
+			c.handle(ctx, msg, cfg.Handler)
+		}
+		if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) {
+			log.Println("fetch error", "stream:", cfg.Stream, "error:", err)
+		}
+	}
+}
+
+func (c *JetStream) handle(ctx context.Context, msg jetstream.Msg, handler natscl.MsgHandler) {
+	meta, err := msg.Metadata()
+	if err != nil {
+		log.Println("failed to read message metadata", "subject:", msg.Subject(), "error:", err)
+		_ = msg.Nak()
+		return
+	}
+
+	hctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), nats.DefaultTimeout)
+	defer cancel()
+
+	err = handler(hctx, &nats.Msg{
+		Subject: msg.Subject(),
+		Header:  msg.Headers(),
+		Data:    msg.Data(),
+	})
+	if err == nil {
+		if err := msg.Ack(); err != nil {
+			log.Println("failed to ack message", "subject:", msg.Subject(), "error:", err)
+		}
+		return
+	}
+
+	deliveries := int(meta.NumDelivered)
+	if natscl.IsDeferred(err) || (!natscl.IsPermanent(err) && deliveries < c.cfg.MaxDeliver) {
+		delay := c.backoff(deliveries)
+		log.Println("failed to handle message, redelivering",
+			"subject:", msg.Subject(),
+			"delivery:", deliveries,
+			"delay:", delay,
+			"error:", err,
+		)
+		_ = msg.NakWithDelay(delay)
+		return
+	}
+
+	if dlqErr := c.deadLetter(context.WithoutCancel(ctx), msg, deliveries, err); dlqErr != nil {
+		log.Println("failed to dead-letter message", "subject:", msg.Subject(), "error:", dlqErr)
+		_ = msg.NakWithDelay(c.backoff(deliveries))
+		return
+	}
+	_ = msg.TermWithReason(err.Error())
+}
+
+// backoff returns the delay before the next delivery after the given number
+// of deliveries. The last delay is repeated once Backoff is exhausted.
+func (c *JetStream) backoff(deliveries int) time.Duration {
+	if len(c.cfg.Backoff) == 0 {
+		return c.cfg.AckWait
+	}
+	i := min(max(deliveries-1, 0), len(c.cfg.Backoff)-1)
+	return c.cfg.Backoff[i]
+}
+
+func (c *JetStream) deadLetter(ctx context.Context, msg jetstream.Msg, deliveries int, cause error) error {
+	header := nats.Header{}
+	for k, v := range msg.Headers() {
+		header[k] = v
+	}
+	header.Set(HeaderDeadLetterSubject, msg.Subject())
+	header.Set(HeaderDeadLetterError, cause.Error())
+	header.Set(HeaderDeadLetterDeliveries, strconv.Itoa(deliveries))
+
+	subject := c.cfg.DeadLetterSubject + "." + msg.Subject()
+	if _, err := c.client.JS.PublishMsg(ctx, &nats.Msg{Subject: subject, Header: header, Data: msg.Data()}); err != nil {
+		return fmt.Errorf("js.PublishMsg %s: %w", subject, err)
+	}
+
+	log.Println("message dead-lettered",
+		"subject:", msg.Subject(),
+		"dead letter subject:", subject,
+		"deliveries:", deliveries,
+		"error:", cause,
+	)
+	return nil
+}
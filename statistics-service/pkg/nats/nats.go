@@ -2,15 +2,60 @@ package nats
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
 type MsgHandler func(ctx context.Context, msg *nats.Msg) error
 
+// permanentError marks a handler error that redelivery cannot fix, e.g. a
+// message that does not decode.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that a JetStream consumer dead-letters the message
+// right away instead of redelivering it.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var perr permanentError
+	return errors.As(err, &perr)
+}
+
+// deferredError marks a handler error that only says the message cannot be
+// handled yet.
+type deferredError struct {
+	err error
+}
+
+func (e deferredError) Error() string { return e.err.Error() }
+func (e deferredError) Unwrap() error { return e.err }
+
+// Deferred wraps err so that a JetStream consumer redelivers the message
+// after its backoff however often it was delivered, rather than
+// dead-lettering it after the last attempt.
+func Deferred(err error) error {
+	return deferredError{err: err}
+}
+
+func IsDeferred(err error) bool {
+	var derr deferredError
+	return errors.As(err, &derr)
+}
+
 type Client struct {
 	Conn *nats.Conn
+	JS   jetstream.JetStream
 }
 
 func NewClient(ctx context.Context, host []string, nkey string, isTest bool) (*Client, error) {
@@ -24,7 +69,30 @@ func NewClient(ctx context.Context, host []string, nkey string, isTest bool) (*C
 		return nil, fmt.Errorf("opts.Connect: %w", err)
 	}
 
-	return &Client{Conn: natsConn}, nil
+	js, err := jetstream.New(natsConn)
+	if err != nil {
+		return nil, fmt.Errorf("jetstream.New: %w", err)
+	}
+
+	return &Client{Conn: natsConn, JS: js}, nil
+}
+
+// EnsureStream creates the stream or updates its subjects and retention. Core
+// NATS publishes on those subjects are stored by the stream as well, so
+// publishers do not have to know about JetStream.
+func (c Client) EnsureStream(ctx context.Context, name string, subjects []string, maxAge time.Duration) error {
+	_, err := c.JS.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       name,
+		Subjects:   subjects,
+		Retention:  jetstream.LimitsPolicy,
+		Storage:    jetstream.FileStorage,
+		MaxAge:     maxAge,
+		Duplicates: 2 * time.Minute,
+	})
+	if err != nil {
+		return fmt.Errorf("js.CreateOrUpdateStream %s: %w", name, err)
+	}
+	return nil
 }
 
 func (c Client) Subscribe(subject string, handler MsgHandler) (*nats.Subscription, error) {
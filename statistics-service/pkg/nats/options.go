@@ -12,8 +12,8 @@ import (
 
 // Nats jetstream settings.
 const (
-	maxWaitFetch = 3 * time.Second
-	maxPending   = 256
+	MaxWaitFetch = 3 * time.Second
+	MaxPending   = 256
 	maxReconnect = -1
 )
 
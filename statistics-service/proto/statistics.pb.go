@@ -9,6 +9,8 @@ package statisticspb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,10 +23,443 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// CountMode selects how distinct users are counted. Exact counts are only
+// kept for the last week.
+type CountMode int32
+
+const (
+	CountMode_COUNT_MODE_AUTO        CountMode = 0 // exact for ranges of up to 7 days still kept, approximate otherwise
+	CountMode_COUNT_MODE_EXACT       CountMode = 1
+	CountMode_COUNT_MODE_APPROXIMATE CountMode = 2 // HyperLogLog, about 1% off
+)
+
+// Enum value maps for CountMode.
+var (
+	CountMode_name = map[int32]string{
+		0: "COUNT_MODE_AUTO",
+		1: "COUNT_MODE_EXACT",
+		2: "COUNT_MODE_APPROXIMATE",
+	}
+	CountMode_value = map[string]int32{
+		"COUNT_MODE_AUTO":        0,
+		"COUNT_MODE_EXACT":       1,
+		"COUNT_MODE_APPROXIMATE": 2,
+	}
+)
+
+func (x CountMode) Enum() *CountMode {
+	p := new(CountMode)
+	*p = x
+	return p
+}
+
+func (x CountMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CountMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_statistics_proto_enumTypes[0].Descriptor()
+}
+
+func (CountMode) Type() protoreflect.EnumType {
+	return &file_proto_statistics_proto_enumTypes[0]
+}
+
+func (x CountMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CountMode.Descriptor instead.
+func (CountMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{0}
+}
+
+type Granularity int32
+
+const (
+	Granularity_GRANULARITY_UNSPECIFIED Granularity = 0 // same as GRANULARITY_DAY
+	Granularity_GRANULARITY_DAY         Granularity = 1
+	Granularity_GRANULARITY_WEEK        Granularity = 2 // weeks start on Monday
+	Granularity_GRANULARITY_MONTH       Granularity = 3
+)
+
+// Enum value maps for Granularity.
+var (
+	Granularity_name = map[int32]string{
+		0: "GRANULARITY_UNSPECIFIED",
+		1: "GRANULARITY_DAY",
+		2: "GRANULARITY_WEEK",
+		3: "GRANULARITY_MONTH",
+	}
+	Granularity_value = map[string]int32{
+		"GRANULARITY_UNSPECIFIED": 0,
+		"GRANULARITY_DAY":         1,
+		"GRANULARITY_WEEK":        2,
+		"GRANULARITY_MONTH":       3,
+	}
+)
+
+func (x Granularity) Enum() *Granularity {
+	p := new(Granularity)
+	*p = x
+	return p
+}
+
+func (x Granularity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Granularity) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_statistics_proto_enumTypes[1].Descriptor()
+}
+
+func (Granularity) Type() protoreflect.EnumType {
+	return &file_proto_statistics_proto_enumTypes[1]
+}
+
+func (x Granularity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Granularity.Descriptor instead.
+func (Granularity) EnumDescriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{1}
+}
+
+type RankingMetric int32
+
+const (
+	RankingMetric_RANKING_METRIC_UNSPECIFIED RankingMetric = 0 // same as RANKING_METRIC_UNITS_SOLD
+	RankingMetric_RANKING_METRIC_UNITS_SOLD  RankingMetric = 1
+	RankingMetric_RANKING_METRIC_REVENUE     RankingMetric = 2
+	RankingMetric_RANKING_METRIC_ORDER_COUNT RankingMetric = 3
+)
+
+// Enum value maps for RankingMetric.
+var (
+	RankingMetric_name = map[int32]string{
+		0: "RANKING_METRIC_UNSPECIFIED",
+		1: "RANKING_METRIC_UNITS_SOLD",
+		2: "RANKING_METRIC_REVENUE",
+		3: "RANKING_METRIC_ORDER_COUNT",
+	}
+	RankingMetric_value = map[string]int32{
+		"RANKING_METRIC_UNSPECIFIED": 0,
+		"RANKING_METRIC_UNITS_SOLD":  1,
+		"RANKING_METRIC_REVENUE":     2,
+		"RANKING_METRIC_ORDER_COUNT": 3,
+	}
+)
+
+func (x RankingMetric) Enum() *RankingMetric {
+	p := new(RankingMetric)
+	*p = x
+	return p
+}
+
+func (x RankingMetric) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RankingMetric) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_statistics_proto_enumTypes[2].Descriptor()
+}
+
+func (RankingMetric) Type() protoreflect.EnumType {
+	return &file_proto_statistics_proto_enumTypes[2]
+}
+
+func (x RankingMetric) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RankingMetric.Descriptor instead.
+func (RankingMetric) EnumDescriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{2}
+}
+
+type RankingDimension int32
+
+const (
+	RankingDimension_RANKING_DIMENSION_UNSPECIFIED RankingDimension = 0 // same as RANKING_DIMENSION_PRODUCT
+	RankingDimension_RANKING_DIMENSION_PRODUCT     RankingDimension = 1
+	RankingDimension_RANKING_DIMENSION_CATEGORY    RankingDimension = 2
+)
+
+// Enum value maps for RankingDimension.
+var (
+	RankingDimension_name = map[int32]string{
+		0: "RANKING_DIMENSION_UNSPECIFIED",
+		1: "RANKING_DIMENSION_PRODUCT",
+		2: "RANKING_DIMENSION_CATEGORY",
+	}
+	RankingDimension_value = map[string]int32{
+		"RANKING_DIMENSION_UNSPECIFIED": 0,
+		"RANKING_DIMENSION_PRODUCT":     1,
+		"RANKING_DIMENSION_CATEGORY":    2,
+	}
+)
+
+func (x RankingDimension) Enum() *RankingDimension {
+	p := new(RankingDimension)
+	*p = x
+	return p
+}
+
+func (x RankingDimension) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RankingDimension) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_statistics_proto_enumTypes[3].Descriptor()
+}
+
+func (RankingDimension) Type() protoreflect.EnumType {
+	return &file_proto_statistics_proto_enumTypes[3]
+}
+
+func (x RankingDimension) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RankingDimension.Descriptor instead.
+func (RankingDimension) EnumDescriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{3}
+}
+
+// RFM segments of customers, see the README for the thresholds.
+type CustomerSegment int32
+
+const (
+	CustomerSegment_CUSTOMER_SEGMENT_UNSPECIFIED     CustomerSegment = 0 // no orders
+	CustomerSegment_CUSTOMER_SEGMENT_CHAMPIONS       CustomerSegment = 1
+	CustomerSegment_CUSTOMER_SEGMENT_LOYAL           CustomerSegment = 2
+	CustomerSegment_CUSTOMER_SEGMENT_NEW             CustomerSegment = 3
+	CustomerSegment_CUSTOMER_SEGMENT_PROMISING       CustomerSegment = 4
+	CustomerSegment_CUSTOMER_SEGMENT_NEEDS_ATTENTION CustomerSegment = 5
+	CustomerSegment_CUSTOMER_SEGMENT_AT_RISK         CustomerSegment = 6
+	CustomerSegment_CUSTOMER_SEGMENT_HIBERNATING     CustomerSegment = 7
+)
+
+// Enum value maps for CustomerSegment.
+var (
+	CustomerSegment_name = map[int32]string{
+		0: "CUSTOMER_SEGMENT_UNSPECIFIED",
+		1: "CUSTOMER_SEGMENT_CHAMPIONS",
+		2: "CUSTOMER_SEGMENT_LOYAL",
+		3: "CUSTOMER_SEGMENT_NEW",
+		4: "CUSTOMER_SEGMENT_PROMISING",
+		5: "CUSTOMER_SEGMENT_NEEDS_ATTENTION",
+		6: "CUSTOMER_SEGMENT_AT_RISK",
+		7: "CUSTOMER_SEGMENT_HIBERNATING",
+	}
+	CustomerSegment_value = map[string]int32{
+		"CUSTOMER_SEGMENT_UNSPECIFIED":     0,
+		"CUSTOMER_SEGMENT_CHAMPIONS":       1,
+		"CUSTOMER_SEGMENT_LOYAL":           2,
+		"CUSTOMER_SEGMENT_NEW":             3,
+		"CUSTOMER_SEGMENT_PROMISING":       4,
+		"CUSTOMER_SEGMENT_NEEDS_ATTENTION": 5,
+		"CUSTOMER_SEGMENT_AT_RISK":         6,
+		"CUSTOMER_SEGMENT_HIBERNATING":     7,
+	}
+)
+
+func (x CustomerSegment) Enum() *CustomerSegment {
+	p := new(CustomerSegment)
+	*p = x
+	return p
+}
+
+func (x CustomerSegment) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CustomerSegment) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_statistics_proto_enumTypes[4].Descriptor()
+}
+
+func (CustomerSegment) Type() protoreflect.EnumType {
+	return &file_proto_statistics_proto_enumTypes[4]
+}
+
+func (x CustomerSegment) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CustomerSegment.Descriptor instead.
+func (CustomerSegment) EnumDescriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{4}
+}
+
+type RelatedSource int32
+
+const (
+	RelatedSource_RELATED_SOURCE_UNSPECIFIED         RelatedSource = 0
+	RelatedSource_RELATED_SOURCE_BOUGHT_TOGETHER     RelatedSource = 1 // in at least RELATED_MIN_ORDERS orders with the product
+	RelatedSource_RELATED_SOURCE_CATEGORY_TOP_SELLER RelatedSource = 2 // fills up the list from the best sellers of the category
+)
+
+// Enum value maps for RelatedSource.
+var (
+	RelatedSource_name = map[int32]string{
+		0: "RELATED_SOURCE_UNSPECIFIED",
+		1: "RELATED_SOURCE_BOUGHT_TOGETHER",
+		2: "RELATED_SOURCE_CATEGORY_TOP_SELLER",
+	}
+	RelatedSource_value = map[string]int32{
+		"RELATED_SOURCE_UNSPECIFIED":         0,
+		"RELATED_SOURCE_BOUGHT_TOGETHER":     1,
+		"RELATED_SOURCE_CATEGORY_TOP_SELLER": 2,
+	}
+)
+
+func (x RelatedSource) Enum() *RelatedSource {
+	p := new(RelatedSource)
+	*p = x
+	return p
+}
+
+func (x RelatedSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RelatedSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_statistics_proto_enumTypes[5].Descriptor()
+}
+
+func (RelatedSource) Type() protoreflect.EnumType {
+	return &file_proto_statistics_proto_enumTypes[5]
+}
+
+func (x RelatedSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RelatedSource.Descriptor instead.
+func (RelatedSource) EnumDescriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{5}
+}
+
+// Steps of a conversion funnel, from the clickstream of the API gateway.
+type FunnelStep int32
+
+const (
+	FunnelStep_FUNNEL_STEP_UNSPECIFIED       FunnelStep = 0
+	FunnelStep_FUNNEL_STEP_CATEGORY_BROWSED  FunnelStep = 1
+	FunnelStep_FUNNEL_STEP_PRODUCT_VIEWED    FunnelStep = 2
+	FunnelStep_FUNNEL_STEP_ORDER_CREATED     FunnelStep = 3
+	FunnelStep_FUNNEL_STEP_PAYMENT_ATTEMPTED FunnelStep = 4
+	FunnelStep_FUNNEL_STEP_PAYMENT_COMPLETED FunnelStep = 5
+)
+
+// Enum value maps for FunnelStep.
+var (
+	FunnelStep_name = map[int32]string{
+		0: "FUNNEL_STEP_UNSPECIFIED",
+		1: "FUNNEL_STEP_CATEGORY_BROWSED",
+		2: "FUNNEL_STEP_PRODUCT_VIEWED",
+		3: "FUNNEL_STEP_ORDER_CREATED",
+		4: "FUNNEL_STEP_PAYMENT_ATTEMPTED",
+		5: "FUNNEL_STEP_PAYMENT_COMPLETED",
+	}
+	FunnelStep_value = map[string]int32{
+		"FUNNEL_STEP_UNSPECIFIED":       0,
+		"FUNNEL_STEP_CATEGORY_BROWSED":  1,
+		"FUNNEL_STEP_PRODUCT_VIEWED":    2,
+		"FUNNEL_STEP_ORDER_CREATED":     3,
+		"FUNNEL_STEP_PAYMENT_ATTEMPTED": 4,
+		"FUNNEL_STEP_PAYMENT_COMPLETED": 5,
+	}
+)
+
+func (x FunnelStep) Enum() *FunnelStep {
+	p := new(FunnelStep)
+	*p = x
+	return p
+}
+
+func (x FunnelStep) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FunnelStep) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_statistics_proto_enumTypes[6].Descriptor()
+}
+
+func (FunnelStep) Type() protoreflect.EnumType {
+	return &file_proto_statistics_proto_enumTypes[6]
+}
+
+func (x FunnelStep) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FunnelStep.Descriptor instead.
+func (FunnelStep) EnumDescriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{6}
+}
+
+type RebuildPhase int32
+
+const (
+	RebuildPhase_REBUILD_PHASE_UNSPECIFIED RebuildPhase = 0
+	RebuildPhase_REBUILD_PHASE_SCAN        RebuildPhase = 1 // counting the stored events
+	RebuildPhase_REBUILD_PHASE_CATCH_UP    RebuildPhase = 2 // counting the events stored meanwhile
+	RebuildPhase_REBUILD_PHASE_SWAP        RebuildPhase = 3 // replacing the live buckets a day at a time, event handling is paused for each day
+	RebuildPhase_REBUILD_PHASE_DONE        RebuildPhase = 4
+)
+
+// Enum value maps for RebuildPhase.
+var (
+	RebuildPhase_name = map[int32]string{
+		0: "REBUILD_PHASE_UNSPECIFIED",
+		1: "REBUILD_PHASE_SCAN",
+		2: "REBUILD_PHASE_CATCH_UP",
+		3: "REBUILD_PHASE_SWAP",
+		4: "REBUILD_PHASE_DONE",
+	}
+	RebuildPhase_value = map[string]int32{
+		"REBUILD_PHASE_UNSPECIFIED": 0,
+		"REBUILD_PHASE_SCAN":        1,
+		"REBUILD_PHASE_CATCH_UP":    2,
+		"REBUILD_PHASE_SWAP":        3,
+		"REBUILD_PHASE_DONE":        4,
+	}
+)
+
+func (x RebuildPhase) Enum() *RebuildPhase {
+	p := new(RebuildPhase)
+	*p = x
+	return p
+}
+
+func (x RebuildPhase) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RebuildPhase) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_statistics_proto_enumTypes[7].Descriptor()
+}
+
+func (RebuildPhase) Type() protoreflect.EnumType {
+	return &file_proto_statistics_proto_enumTypes[7]
+}
+
+func (x RebuildPhase) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RebuildPhase.Descriptor instead.
+func (RebuildPhase) EnumDescriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{7}
+}
+
 // Specific user
 type UserOrderStatisticsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TimeZone      string                 `protobuf:"bytes,2,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"` // IANA name, e.g. "Europe/Berlin"; UTC if empty
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -66,9 +501,17 @@ func (x *UserOrderStatisticsRequest) GetUserId() string {
 	return ""
 }
 
+func (x *UserOrderStatisticsRequest) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
 type UserOrderStatisticsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TotalOrders   int32                  `protobuf:"varint,1,opt,name=total_orders,json=totalOrders,proto3" json:"total_orders,omitempty"` // string peak_order_hour = 2; // e.g. 14 at 2AM
+	TotalOrders   int32                  `protobuf:"varint,1,opt,name=total_orders,json=totalOrders,proto3" json:"total_orders,omitempty"`
+	PeakOrderHour string                 `protobuf:"bytes,2,opt,name=peak_order_hour,json=peakOrderHour,proto3" json:"peak_order_hour,omitempty"` // hour of day with the most orders in time_zone, e.g. "14:00"; empty without orders
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -110,6 +553,13 @@ func (x *UserOrderStatisticsResponse) GetTotalOrders() int32 {
 	return 0
 }
 
+func (x *UserOrderStatisticsResponse) GetPeakOrderHour() string {
+	if x != nil {
+		return x.PeakOrderHour
+	}
+	return ""
+}
+
 // All users
 type UserStatisticsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -148,11 +598,13 @@ func (*UserStatisticsRequest) Descriptor() ([]byte, []int) {
 }
 
 type UserStatisticsResponse struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	TotalUsers       int32                  `protobuf:"varint,1,opt,name=total_users,json=totalUsers,proto3" json:"total_users,omitempty"`
-	DailyActiveUsers int32                  `protobuf:"varint,2,opt,name=daily_active_users,json=dailyActiveUsers,proto3" json:"daily_active_users,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TotalUsers         int32                  `protobuf:"varint,1,opt,name=total_users,json=totalUsers,proto3" json:"total_users,omitempty"`
+	DailyActiveUsers   int32                  `protobuf:"varint,2,opt,name=daily_active_users,json=dailyActiveUsers,proto3" json:"daily_active_users,omitempty"`
+	WeeklyActiveUsers  int32                  `protobuf:"varint,3,opt,name=weekly_active_users,json=weeklyActiveUsers,proto3" json:"weekly_active_users,omitempty"`    // last 7 UTC days, today included
+	MonthlyActiveUsers int32                  `protobuf:"varint,4,opt,name=monthly_active_users,json=monthlyActiveUsers,proto3" json:"monthly_active_users,omitempty"` // last 30 UTC days, today included, approximate
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *UserStatisticsResponse) Reset() {
@@ -199,24 +651,2325 @@ func (x *UserStatisticsResponse) GetDailyActiveUsers() int32 {
 	return 0
 }
 
-var File_proto_statistics_proto protoreflect.FileDescriptor
+func (x *UserStatisticsResponse) GetWeeklyActiveUsers() int32 {
+	if x != nil {
+		return x.WeeklyActiveUsers
+	}
+	return 0
+}
 
-const file_proto_statistics_proto_rawDesc = "" +
-	"\n" +
-	"\x16proto/statistics.proto\x12\n" +
-	"statistics\"5\n" +
-	"\x1aUserOrderStatisticsRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\"@\n" +
-	"\x1bUserOrderStatisticsResponse\x12!\n" +
-	"\ftotal_orders\x18\x01 \x01(\x05R\vtotalOrders\"\x17\n" +
-	"\x15UserStatisticsRequest\"g\n" +
-	"\x16UserStatisticsResponse\x12\x1f\n" +
-	"\vtotal_users\x18\x01 \x01(\x05R\n" +
-	"totalUsers\x12,\n" +
-	"\x12daily_active_users\x18\x02 \x01(\x05R\x10dailyActiveUsers2\xdb\x01\n" +
+func (x *UserStatisticsResponse) GetMonthlyActiveUsers() int32 {
+	if x != nil {
+		return x.MonthlyActiveUsers
+	}
+	return 0
+}
+
+// Distinct users active in whole UTC days; from and to are widened to
+// midnight, to is excluded. Defaults to the last 30 days.
+type ActiveUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Mode          CountMode              `protobuf:"varint,3,opt,name=mode,proto3,enum=statistics.CountMode" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActiveUsersRequest) Reset() {
+	*x = ActiveUsersRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActiveUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActiveUsersRequest) ProtoMessage() {}
+
+func (x *ActiveUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActiveUsersRequest.ProtoReflect.Descriptor instead.
+func (*ActiveUsersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ActiveUsersRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ActiveUsersRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ActiveUsersRequest) GetMode() CountMode {
+	if x != nil {
+		return x.Mode
+	}
+	return CountMode_COUNT_MODE_AUTO
+}
+
+type ActiveUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActiveUsers   int64                  `protobuf:"varint,1,opt,name=active_users,json=activeUsers,proto3" json:"active_users,omitempty"`
+	Exact         bool                   `protobuf:"varint,2,opt,name=exact,proto3" json:"exact,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActiveUsersResponse) Reset() {
+	*x = ActiveUsersResponse{}
+	mi := &file_proto_statistics_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActiveUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActiveUsersResponse) ProtoMessage() {}
+
+func (x *ActiveUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActiveUsersResponse.ProtoReflect.Descriptor instead.
+func (*ActiveUsersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ActiveUsersResponse) GetActiveUsers() int64 {
+	if x != nil {
+		return x.ActiveUsers
+	}
+	return 0
+}
+
+func (x *ActiveUsersResponse) GetExact() bool {
+	if x != nil {
+		return x.Exact
+	}
+	return false
+}
+
+func (x *ActiveUsersResponse) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ActiveUsersResponse) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+// Money mirrors google.type.Money: units is the whole part of the amount and
+// nanos the fractional part in billionths, both with the same sign.
+type Money struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CurrencyCode  string                 `protobuf:"bytes,1,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"` // ISO 4217, e.g. "USD"
+	Units         int64                  `protobuf:"varint,2,opt,name=units,proto3" json:"units,omitempty"`
+	Nanos         int32                  `protobuf:"varint,3,opt,name=nanos,proto3" json:"nanos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_proto_statistics_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Money) GetCurrencyCode() string {
+	if x != nil {
+		return x.CurrencyCode
+	}
+	return ""
+}
+
+func (x *Money) GetUnits() int64 {
+	if x != nil {
+		return x.Units
+	}
+	return 0
+}
+
+func (x *Money) GetNanos() int32 {
+	if x != nil {
+		return x.Nanos
+	}
+	return 0
+}
+
+// Sales in one currency over [from, to), in UTC
+type SalesStatisticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Granularity   Granularity            `protobuf:"varint,3,opt,name=granularity,proto3,enum=statistics.Granularity" json:"granularity,omitempty"`
+	CurrencyCode  string                 `protobuf:"bytes,4,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"` // empty for the default currency
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SalesStatisticsRequest) Reset() {
+	*x = SalesStatisticsRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SalesStatisticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SalesStatisticsRequest) ProtoMessage() {}
+
+func (x *SalesStatisticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SalesStatisticsRequest.ProtoReflect.Descriptor instead.
+func (*SalesStatisticsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SalesStatisticsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *SalesStatisticsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *SalesStatisticsRequest) GetGranularity() Granularity {
+	if x != nil {
+		return x.Granularity
+	}
+	return Granularity_GRANULARITY_UNSPECIFIED
+}
+
+func (x *SalesStatisticsRequest) GetCurrencyCode() string {
+	if x != nil {
+		return x.CurrencyCode
+	}
+	return ""
+}
+
+type SalesStatistics struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	PeriodStart       *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"`                     // unset for the total
+	Revenue           *Money                 `protobuf:"bytes,2,opt,name=revenue,proto3" json:"revenue,omitempty"`                                                // payments received
+	OrderCount        int64                  `protobuf:"varint,3,opt,name=order_count,json=orderCount,proto3" json:"order_count,omitempty"`                       // orders placed, deleted ones excluded
+	OrderValue        *Money                 `protobuf:"bytes,4,opt,name=order_value,json=orderValue,proto3" json:"order_value,omitempty"`                        // sum of the line totals of those orders
+	AverageOrderValue *Money                 `protobuf:"bytes,5,opt,name=average_order_value,json=averageOrderValue,proto3" json:"average_order_value,omitempty"` // order_value / order_count
+	UnitsSold         int64                  `protobuf:"varint,6,opt,name=units_sold,json=unitsSold,proto3" json:"units_sold,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SalesStatistics) Reset() {
+	*x = SalesStatistics{}
+	mi := &file_proto_statistics_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SalesStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SalesStatistics) ProtoMessage() {}
+
+func (x *SalesStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SalesStatistics.ProtoReflect.Descriptor instead.
+func (*SalesStatistics) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SalesStatistics) GetPeriodStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return nil
+}
+
+func (x *SalesStatistics) GetRevenue() *Money {
+	if x != nil {
+		return x.Revenue
+	}
+	return nil
+}
+
+func (x *SalesStatistics) GetOrderCount() int64 {
+	if x != nil {
+		return x.OrderCount
+	}
+	return 0
+}
+
+func (x *SalesStatistics) GetOrderValue() *Money {
+	if x != nil {
+		return x.OrderValue
+	}
+	return nil
+}
+
+func (x *SalesStatistics) GetAverageOrderValue() *Money {
+	if x != nil {
+		return x.AverageOrderValue
+	}
+	return nil
+}
+
+func (x *SalesStatistics) GetUnitsSold() int64 {
+	if x != nil {
+		return x.UnitsSold
+	}
+	return 0
+}
+
+type SalesStatisticsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CurrencyCode  string                 `protobuf:"bytes,1,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
+	Total         *SalesStatistics       `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`
+	Periods       []*SalesStatistics     `protobuf:"bytes,3,rep,name=periods,proto3" json:"periods,omitempty"` // oldest first, periods without sales are left out
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SalesStatisticsResponse) Reset() {
+	*x = SalesStatisticsResponse{}
+	mi := &file_proto_statistics_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SalesStatisticsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SalesStatisticsResponse) ProtoMessage() {}
+
+func (x *SalesStatisticsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SalesStatisticsResponse.ProtoReflect.Descriptor instead.
+func (*SalesStatisticsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SalesStatisticsResponse) GetCurrencyCode() string {
+	if x != nil {
+		return x.CurrencyCode
+	}
+	return ""
+}
+
+func (x *SalesStatisticsResponse) GetTotal() *SalesStatistics {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+func (x *SalesStatisticsResponse) GetPeriods() []*SalesStatistics {
+	if x != nil {
+		return x.Periods
+	}
+	return nil
+}
+
+// Best selling products or categories over [from, to) in one currency. The
+// sales are kept per UTC day, so from is rounded down and to up to whole days.
+type TopProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Metric        RankingMetric          `protobuf:"varint,3,opt,name=metric,proto3,enum=statistics.RankingMetric" json:"metric,omitempty"`
+	Dimension     RankingDimension       `protobuf:"varint,4,opt,name=dimension,proto3,enum=statistics.RankingDimension" json:"dimension,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                                  // 10 if unset, at most 100
+	CurrencyCode  string                 `protobuf:"bytes,6,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"` // empty for the default currency
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopProductsRequest) Reset() {
+	*x = TopProductsRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopProductsRequest) ProtoMessage() {}
+
+func (x *TopProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopProductsRequest.ProtoReflect.Descriptor instead.
+func (*TopProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TopProductsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *TopProductsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *TopProductsRequest) GetMetric() RankingMetric {
+	if x != nil {
+		return x.Metric
+	}
+	return RankingMetric_RANKING_METRIC_UNSPECIFIED
+}
+
+func (x *TopProductsRequest) GetDimension() RankingDimension {
+	if x != nil {
+		return x.Dimension
+	}
+	return RankingDimension_RANKING_DIMENSION_UNSPECIFIED
+}
+
+func (x *TopProductsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *TopProductsRequest) GetCurrencyCode() string {
+	if x != nil {
+		return x.CurrencyCode
+	}
+	return ""
+}
+
+type TopProductsEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rank          int32                  `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"` // from 1
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`      // product or category ID
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	CategoryId    string                 `protobuf:"bytes,4,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"` // products only
+	UnitsSold     int64                  `protobuf:"varint,5,opt,name=units_sold,json=unitsSold,proto3" json:"units_sold,omitempty"`
+	Revenue       *Money                 `protobuf:"bytes,6,opt,name=revenue,proto3" json:"revenue,omitempty"`                          // line totals at the prices the orders were placed at
+	OrderCount    int64                  `protobuf:"varint,7,opt,name=order_count,json=orderCount,proto3" json:"order_count,omitempty"` // orders with at least one of the product or category
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopProductsEntry) Reset() {
+	*x = TopProductsEntry{}
+	mi := &file_proto_statistics_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopProductsEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopProductsEntry) ProtoMessage() {}
+
+func (x *TopProductsEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopProductsEntry.ProtoReflect.Descriptor instead.
+func (*TopProductsEntry) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TopProductsEntry) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *TopProductsEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TopProductsEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TopProductsEntry) GetCategoryId() string {
+	if x != nil {
+		return x.CategoryId
+	}
+	return ""
+}
+
+func (x *TopProductsEntry) GetUnitsSold() int64 {
+	if x != nil {
+		return x.UnitsSold
+	}
+	return 0
+}
+
+func (x *TopProductsEntry) GetRevenue() *Money {
+	if x != nil {
+		return x.Revenue
+	}
+	return nil
+}
+
+func (x *TopProductsEntry) GetOrderCount() int64 {
+	if x != nil {
+		return x.OrderCount
+	}
+	return 0
+}
+
+type TopProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CurrencyCode  string                 `protobuf:"bytes,1,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"` // the window used, in whole days
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Entries       []*TopProductsEntry    `protobuf:"bytes,4,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopProductsResponse) Reset() {
+	*x = TopProductsResponse{}
+	mi := &file_proto_statistics_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopProductsResponse) ProtoMessage() {}
+
+func (x *TopProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopProductsResponse.ProtoReflect.Descriptor instead.
+func (*TopProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TopProductsResponse) GetCurrencyCode() string {
+	if x != nil {
+		return x.CurrencyCode
+	}
+	return ""
+}
+
+func (x *TopProductsResponse) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *TopProductsResponse) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *TopProductsResponse) GetEntries() []*TopProductsEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// Orders placed in [from, to) by weekday and hour of day in time_zone. An
+// unset from or to leaves the range open.
+type OrderHeatmapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`       // empty for all users
+	TimeZone      string                 `protobuf:"bytes,2,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"` // IANA name, e.g. "Europe/Berlin"; UTC if empty
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderHeatmapRequest) Reset() {
+	*x = OrderHeatmapRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderHeatmapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderHeatmapRequest) ProtoMessage() {}
+
+func (x *OrderHeatmapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderHeatmapRequest.ProtoReflect.Descriptor instead.
+func (*OrderHeatmapRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *OrderHeatmapRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *OrderHeatmapRequest) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *OrderHeatmapRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *OrderHeatmapRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type HeatmapCell struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Weekday       int32                  `protobuf:"varint,1,opt,name=weekday,proto3" json:"weekday,omitempty"` // ISO 8601, 1 is Monday and 7 Sunday
+	Hour          int32                  `protobuf:"varint,2,opt,name=hour,proto3" json:"hour,omitempty"`       // 0 to 23
+	OrderCount    int64                  `protobuf:"varint,3,opt,name=order_count,json=orderCount,proto3" json:"order_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeatmapCell) Reset() {
+	*x = HeatmapCell{}
+	mi := &file_proto_statistics_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeatmapCell) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeatmapCell) ProtoMessage() {}
+
+func (x *HeatmapCell) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeatmapCell.ProtoReflect.Descriptor instead.
+func (*HeatmapCell) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HeatmapCell) GetWeekday() int32 {
+	if x != nil {
+		return x.Weekday
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetHour() int32 {
+	if x != nil {
+		return x.Hour
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetOrderCount() int64 {
+	if x != nil {
+		return x.OrderCount
+	}
+	return 0
+}
+
+type OrderHeatmapResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TimeZone      string                 `protobuf:"bytes,1,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	TotalOrders   int64                  `protobuf:"varint,2,opt,name=total_orders,json=totalOrders,proto3" json:"total_orders,omitempty"`
+	Cells         []*HeatmapCell         `protobuf:"bytes,3,rep,name=cells,proto3" json:"cells,omitempty"` // all 168 cells, Monday 0:00 first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderHeatmapResponse) Reset() {
+	*x = OrderHeatmapResponse{}
+	mi := &file_proto_statistics_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderHeatmapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderHeatmapResponse) ProtoMessage() {}
+
+func (x *OrderHeatmapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderHeatmapResponse.ProtoReflect.Descriptor instead.
+func (*OrderHeatmapResponse) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *OrderHeatmapResponse) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *OrderHeatmapResponse) GetTotalOrders() int64 {
+	if x != nil {
+		return x.TotalOrders
+	}
+	return 0
+}
+
+func (x *OrderHeatmapResponse) GetCells() []*HeatmapCell {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+// Users grouped into cohorts by the week or month of their first order, and
+// how many of them ordered again in each of the following periods, in UTC.
+// Cohorts start from from to to, the last 12 periods by default. Deleted
+// orders are left out.
+type RetentionCohortsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Granularity   Granularity            `protobuf:"varint,3,opt,name=granularity,proto3,enum=statistics.Granularity" json:"granularity,omitempty"` // week (default) or month
+	Periods       int32                  `protobuf:"varint,4,opt,name=periods,proto3" json:"periods,omitempty"`                                     // periods after the first one, 12 by default, at most 52
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetentionCohortsRequest) Reset() {
+	*x = RetentionCohortsRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetentionCohortsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetentionCohortsRequest) ProtoMessage() {}
+
+func (x *RetentionCohortsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetentionCohortsRequest.ProtoReflect.Descriptor instead.
+func (*RetentionCohortsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RetentionCohortsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *RetentionCohortsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *RetentionCohortsRequest) GetGranularity() Granularity {
+	if x != nil {
+		return x.Granularity
+	}
+	return Granularity_GRANULARITY_UNSPECIFIED
+}
+
+func (x *RetentionCohortsRequest) GetPeriods() int32 {
+	if x != nil {
+		return x.Periods
+	}
+	return 0
+}
+
+type RetentionCohort struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeriodStart   *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`                   // users who first ordered in the period
+	Retained      []int64                `protobuf:"varint,3,rep,packed,name=retained,proto3" json:"retained,omitempty"`    // users who ordered in period N+1, N+2, ... up to the current one
+	Retention     []float64              `protobuf:"fixed64,4,rep,packed,name=retention,proto3" json:"retention,omitempty"` // retained / size, 0 for an empty cohort
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetentionCohort) Reset() {
+	*x = RetentionCohort{}
+	mi := &file_proto_statistics_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetentionCohort) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetentionCohort) ProtoMessage() {}
+
+func (x *RetentionCohort) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetentionCohort.ProtoReflect.Descriptor instead.
+func (*RetentionCohort) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RetentionCohort) GetPeriodStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return nil
+}
+
+func (x *RetentionCohort) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *RetentionCohort) GetRetained() []int64 {
+	if x != nil {
+		return x.Retained
+	}
+	return nil
+}
+
+func (x *RetentionCohort) GetRetention() []float64 {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+type RetentionCohortsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Granularity   Granularity            `protobuf:"varint,1,opt,name=granularity,proto3,enum=statistics.Granularity" json:"granularity,omitempty"`
+	Periods       int32                  `protobuf:"varint,2,opt,name=periods,proto3" json:"periods,omitempty"`
+	Cohorts       []*RetentionCohort     `protobuf:"bytes,3,rep,name=cohorts,proto3" json:"cohorts,omitempty"` // oldest first, empty ones included
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetentionCohortsResponse) Reset() {
+	*x = RetentionCohortsResponse{}
+	mi := &file_proto_statistics_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetentionCohortsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetentionCohortsResponse) ProtoMessage() {}
+
+func (x *RetentionCohortsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetentionCohortsResponse.ProtoReflect.Descriptor instead.
+func (*RetentionCohortsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RetentionCohortsResponse) GetGranularity() Granularity {
+	if x != nil {
+		return x.Granularity
+	}
+	return Granularity_GRANULARITY_UNSPECIFIED
+}
+
+func (x *RetentionCohortsResponse) GetPeriods() int32 {
+	if x != nil {
+		return x.Periods
+	}
+	return 0
+}
+
+func (x *RetentionCohortsResponse) GetCohorts() []*RetentionCohort {
+	if x != nil {
+		return x.Cohorts
+	}
+	return nil
+}
+
+// Orders, order value and dates leave deleted orders out; lifetime_value is
+// every completed payment, per currency.
+type CustomerProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Segment       CustomerSegment        `protobuf:"varint,2,opt,name=segment,proto3,enum=statistics.CustomerSegment" json:"segment,omitempty"`
+	OrderCount    int64                  `protobuf:"varint,3,opt,name=order_count,json=orderCount,proto3" json:"order_count,omitempty"`
+	FirstOrderAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=first_order_at,json=firstOrderAt,proto3" json:"first_order_at,omitempty"`
+	LastOrderAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_order_at,json=lastOrderAt,proto3" json:"last_order_at,omitempty"`
+	RecencyDays   int32                  `protobuf:"varint,6,opt,name=recency_days,json=recencyDays,proto3" json:"recency_days,omitempty"` // whole days since the last order
+	OrderValue    []*Money               `protobuf:"bytes,7,rep,name=order_value,json=orderValue,proto3" json:"order_value,omitempty"`
+	LifetimeValue []*Money               `protobuf:"bytes,8,rep,name=lifetime_value,json=lifetimeValue,proto3" json:"lifetime_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CustomerProfile) Reset() {
+	*x = CustomerProfile{}
+	mi := &file_proto_statistics_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomerProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomerProfile) ProtoMessage() {}
+
+func (x *CustomerProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomerProfile.ProtoReflect.Descriptor instead.
+func (*CustomerProfile) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CustomerProfile) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CustomerProfile) GetSegment() CustomerSegment {
+	if x != nil {
+		return x.Segment
+	}
+	return CustomerSegment_CUSTOMER_SEGMENT_UNSPECIFIED
+}
+
+func (x *CustomerProfile) GetOrderCount() int64 {
+	if x != nil {
+		return x.OrderCount
+	}
+	return 0
+}
+
+func (x *CustomerProfile) GetFirstOrderAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstOrderAt
+	}
+	return nil
+}
+
+func (x *CustomerProfile) GetLastOrderAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastOrderAt
+	}
+	return nil
+}
+
+func (x *CustomerProfile) GetRecencyDays() int32 {
+	if x != nil {
+		return x.RecencyDays
+	}
+	return 0
+}
+
+func (x *CustomerProfile) GetOrderValue() []*Money {
+	if x != nil {
+		return x.OrderValue
+	}
+	return nil
+}
+
+func (x *CustomerProfile) GetLifetimeValue() []*Money {
+	if x != nil {
+		return x.LifetimeValue
+	}
+	return nil
+}
+
+type CustomerProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CustomerProfileRequest) Reset() {
+	*x = CustomerProfileRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomerProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomerProfileRequest) ProtoMessage() {}
+
+func (x *CustomerProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomerProfileRequest.ProtoReflect.Descriptor instead.
+func (*CustomerProfileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CustomerProfileRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type CustomerProfileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Profile       *CustomerProfile       `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CustomerProfileResponse) Reset() {
+	*x = CustomerProfileResponse{}
+	mi := &file_proto_statistics_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomerProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomerProfileResponse) ProtoMessage() {}
+
+func (x *CustomerProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomerProfileResponse.ProtoReflect.Descriptor instead.
+func (*CustomerProfileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CustomerProfileResponse) GetProfile() *CustomerProfile {
+	if x != nil {
+		return x.Profile
+	}
+	return nil
+}
+
+type SegmentMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Segment       CustomerSegment        `protobuf:"varint,1,opt,name=segment,proto3,enum=statistics.CustomerSegment" json:"segment,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                         // 100 by default, at most 1000
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // next_page_token of the previous page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SegmentMembersRequest) Reset() {
+	*x = SegmentMembersRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SegmentMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentMembersRequest) ProtoMessage() {}
+
+func (x *SegmentMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentMembersRequest.ProtoReflect.Descriptor instead.
+func (*SegmentMembersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SegmentMembersRequest) GetSegment() CustomerSegment {
+	if x != nil {
+		return x.Segment
+	}
+	return CustomerSegment_CUSTOMER_SEGMENT_UNSPECIFIED
+}
+
+func (x *SegmentMembersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SegmentMembersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type SegmentMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Segment       CustomerSegment        `protobuf:"varint,1,opt,name=segment,proto3,enum=statistics.CustomerSegment" json:"segment,omitempty"`
+	Customers     []*CustomerProfile     `protobuf:"bytes,2,rep,name=customers,proto3" json:"customers,omitempty"`                                // by user ID
+	NextPageToken string                 `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SegmentMembersResponse) Reset() {
+	*x = SegmentMembersResponse{}
+	mi := &file_proto_statistics_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SegmentMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentMembersResponse) ProtoMessage() {}
+
+func (x *SegmentMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentMembersResponse.ProtoReflect.Descriptor instead.
+func (*SegmentMembersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SegmentMembersResponse) GetSegment() CustomerSegment {
+	if x != nil {
+		return x.Segment
+	}
+	return CustomerSegment_CUSTOMER_SEGMENT_UNSPECIFIED
+}
+
+func (x *SegmentMembersResponse) GetCustomers() []*CustomerProfile {
+	if x != nil {
+		return x.Customers
+	}
+	return nil
+}
+
+func (x *SegmentMembersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Products bought together with product_id, from the items of the orders.
+type RelatedProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // 10 by default, at most 50
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelatedProductsRequest) Reset() {
+	*x = RelatedProductsRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelatedProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelatedProductsRequest) ProtoMessage() {}
+
+func (x *RelatedProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelatedProductsRequest.ProtoReflect.Descriptor instead.
+func (*RelatedProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RelatedProductsRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *RelatedProductsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type RelatedProduct struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	CategoryId    string                 `protobuf:"bytes,3,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	Orders        int64                  `protobuf:"varint,4,opt,name=orders,proto3" json:"orders,omitempty"`          // orders with both products, 0 for top sellers
+	Support       float64                `protobuf:"fixed64,5,opt,name=support,proto3" json:"support,omitempty"`       // orders / all orders
+	Confidence    float64                `protobuf:"fixed64,6,opt,name=confidence,proto3" json:"confidence,omitempty"` // orders / orders with the requested product
+	Source        RelatedSource          `protobuf:"varint,7,opt,name=source,proto3,enum=statistics.RelatedSource" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelatedProduct) Reset() {
+	*x = RelatedProduct{}
+	mi := &file_proto_statistics_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelatedProduct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelatedProduct) ProtoMessage() {}
+
+func (x *RelatedProduct) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelatedProduct.ProtoReflect.Descriptor instead.
+func (*RelatedProduct) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RelatedProduct) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *RelatedProduct) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RelatedProduct) GetCategoryId() string {
+	if x != nil {
+		return x.CategoryId
+	}
+	return ""
+}
+
+func (x *RelatedProduct) GetOrders() int64 {
+	if x != nil {
+		return x.Orders
+	}
+	return 0
+}
+
+func (x *RelatedProduct) GetSupport() float64 {
+	if x != nil {
+		return x.Support
+	}
+	return 0
+}
+
+func (x *RelatedProduct) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *RelatedProduct) GetSource() RelatedSource {
+	if x != nil {
+		return x.Source
+	}
+	return RelatedSource_RELATED_SOURCE_UNSPECIFIED
+}
+
+type RelatedProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Orders        int64                  `protobuf:"varint,2,opt,name=orders,proto3" json:"orders,omitempty"`    // orders with the product
+	Products      []*RelatedProduct      `protobuf:"bytes,3,rep,name=products,proto3" json:"products,omitempty"` // bought together first, most orders first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelatedProductsResponse) Reset() {
+	*x = RelatedProductsResponse{}
+	mi := &file_proto_statistics_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelatedProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelatedProductsResponse) ProtoMessage() {}
+
+func (x *RelatedProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelatedProductsResponse.ProtoReflect.Descriptor instead.
+func (*RelatedProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RelatedProductsResponse) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *RelatedProductsResponse) GetOrders() int64 {
+	if x != nil {
+		return x.Orders
+	}
+	return 0
+}
+
+func (x *RelatedProductsResponse) GetProducts() []*RelatedProduct {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+// Visitors, by session or else by user, who took the steps in order within
+// [from, to), at most 90 days.
+type ConversionFunnelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Steps         []FunnelStep           `protobuf:"varint,3,rep,packed,name=steps,proto3,enum=statistics.FunnelStep" json:"steps,omitempty"` // product viewed, order created, payment attempted, payment completed by default
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversionFunnelRequest) Reset() {
+	*x = ConversionFunnelRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversionFunnelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversionFunnelRequest) ProtoMessage() {}
+
+func (x *ConversionFunnelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversionFunnelRequest.ProtoReflect.Descriptor instead.
+func (*ConversionFunnelRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ConversionFunnelRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ConversionFunnelRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ConversionFunnelRequest) GetSteps() []FunnelStep {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+type FunnelStepStatistics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Step          FunnelStep             `protobuf:"varint,1,opt,name=step,proto3,enum=statistics.FunnelStep" json:"step,omitempty"`
+	Visitors      int64                  `protobuf:"varint,2,opt,name=visitors,proto3" json:"visitors,omitempty"`               // who got to this step
+	Conversion    float64                `protobuf:"fixed64,3,opt,name=conversion,proto3" json:"conversion,omitempty"`          // visitors / visitors of the previous step, 1 for the first step
+	DropOff       float64                `protobuf:"fixed64,4,opt,name=drop_off,json=dropOff,proto3" json:"drop_off,omitempty"` // 1 - conversion
+	Overall       float64                `protobuf:"fixed64,5,opt,name=overall,proto3" json:"overall,omitempty"`                // visitors / visitors of the first step
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FunnelStepStatistics) Reset() {
+	*x = FunnelStepStatistics{}
+	mi := &file_proto_statistics_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FunnelStepStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FunnelStepStatistics) ProtoMessage() {}
+
+func (x *FunnelStepStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FunnelStepStatistics.ProtoReflect.Descriptor instead.
+func (*FunnelStepStatistics) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *FunnelStepStatistics) GetStep() FunnelStep {
+	if x != nil {
+		return x.Step
+	}
+	return FunnelStep_FUNNEL_STEP_UNSPECIFIED
+}
+
+func (x *FunnelStepStatistics) GetVisitors() int64 {
+	if x != nil {
+		return x.Visitors
+	}
+	return 0
+}
+
+func (x *FunnelStepStatistics) GetConversion() float64 {
+	if x != nil {
+		return x.Conversion
+	}
+	return 0
+}
+
+func (x *FunnelStepStatistics) GetDropOff() float64 {
+	if x != nil {
+		return x.DropOff
+	}
+	return 0
+}
+
+func (x *FunnelStepStatistics) GetOverall() float64 {
+	if x != nil {
+		return x.Overall
+	}
+	return 0
+}
+
+type ConversionFunnelResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	From          *timestamppb.Timestamp  `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp  `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Steps         []*FunnelStepStatistics `protobuf:"bytes,3,rep,name=steps,proto3" json:"steps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConversionFunnelResponse) Reset() {
+	*x = ConversionFunnelResponse{}
+	mi := &file_proto_statistics_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversionFunnelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversionFunnelResponse) ProtoMessage() {}
+
+func (x *ConversionFunnelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversionFunnelResponse.ProtoReflect.Descriptor instead.
+func (*ConversionFunnelResponse) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ConversionFunnelResponse) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ConversionFunnelResponse) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ConversionFunnelResponse) GetSteps() []*FunnelStepStatistics {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+// Live statistics are pushed when events change them, at most once per
+// min_interval and never more often than the service allows (1s by
+// default), and refreshed at least once a minute.
+type WatchStatisticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinInterval   *durationpb.Duration   `protobuf:"bytes,1,opt,name=min_interval,json=minInterval,proto3" json:"min_interval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchStatisticsRequest) Reset() {
+	*x = WatchStatisticsRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchStatisticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchStatisticsRequest) ProtoMessage() {}
+
+func (x *WatchStatisticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchStatisticsRequest.ProtoReflect.Descriptor instead.
+func (*WatchStatisticsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *WatchStatisticsRequest) GetMinInterval() *durationpb.Duration {
+	if x != nil {
+		return x.MinInterval
+	}
+	return nil
+}
+
+type LowStockProduct struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Stock         int32                  `protobuf:"varint,3,opt,name=stock,proto3" json:"stock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LowStockProduct) Reset() {
+	*x = LowStockProduct{}
+	mi := &file_proto_statistics_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LowStockProduct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LowStockProduct) ProtoMessage() {}
+
+func (x *LowStockProduct) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LowStockProduct.ProtoReflect.Descriptor instead.
+func (*LowStockProduct) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *LowStockProduct) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *LowStockProduct) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LowStockProduct) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+type LiveStatistics struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	At               *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=at,proto3" json:"at,omitempty"`
+	OrdersPerMinute  float64                `protobuf:"fixed64,2,opt,name=orders_per_minute,json=ordersPerMinute,proto3" json:"orders_per_minute,omitempty"`   // average of the last 5 whole minutes
+	RevenueToday     []*Money               `protobuf:"bytes,3,rep,name=revenue_today,json=revenueToday,proto3" json:"revenue_today,omitempty"`                // completed payments since midnight UTC, per currency
+	ActiveUsersToday int64                  `protobuf:"varint,4,opt,name=active_users_today,json=activeUsersToday,proto3" json:"active_users_today,omitempty"` // since midnight UTC
+	LowStockProducts []*LowStockProduct     `protobuf:"bytes,5,rep,name=low_stock_products,json=lowStockProducts,proto3" json:"low_stock_products,omitempty"`  // at or below LOW_STOCK_THRESHOLD, fewest first, at most 20
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *LiveStatistics) Reset() {
+	*x = LiveStatistics{}
+	mi := &file_proto_statistics_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LiveStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LiveStatistics) ProtoMessage() {}
+
+func (x *LiveStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LiveStatistics.ProtoReflect.Descriptor instead.
+func (*LiveStatistics) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *LiveStatistics) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *LiveStatistics) GetOrdersPerMinute() float64 {
+	if x != nil {
+		return x.OrdersPerMinute
+	}
+	return 0
+}
+
+func (x *LiveStatistics) GetRevenueToday() []*Money {
+	if x != nil {
+		return x.RevenueToday
+	}
+	return nil
+}
+
+func (x *LiveStatistics) GetActiveUsersToday() int64 {
+	if x != nil {
+		return x.ActiveUsersToday
+	}
+	return 0
+}
+
+func (x *LiveStatistics) GetLowStockProducts() []*LowStockProduct {
+	if x != nil {
+		return x.LowStockProducts
+	}
+	return nil
+}
+
+// Recomputes the rollups of [from, to) from the stored events. The range is
+// widened to whole UTC days and may span at most 31 of them. The rollups are
+// rebuilt side by side and swapped in at once at the end; cancelling the
+// call leaves the live rollups as they were.
+type RebuildRollupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RebuildRollupsRequest) Reset() {
+	*x = RebuildRollupsRequest{}
+	mi := &file_proto_statistics_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RebuildRollupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RebuildRollupsRequest) ProtoMessage() {}
+
+func (x *RebuildRollupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RebuildRollupsRequest.ProtoReflect.Descriptor instead.
+func (*RebuildRollupsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *RebuildRollupsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *RebuildRollupsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type RebuildRollupsProgress struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RebuildId       string                 `protobuf:"bytes,1,opt,name=rebuild_id,json=rebuildId,proto3" json:"rebuild_id,omitempty"`
+	Phase           RebuildPhase           `protobuf:"varint,2,opt,name=phase,proto3,enum=statistics.RebuildPhase" json:"phase,omitempty"`
+	EventsProcessed int64                  `protobuf:"varint,3,opt,name=events_processed,json=eventsProcessed,proto3" json:"events_processed,omitempty"`
+	LastEventTime   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_event_time,json=lastEventTime,proto3" json:"last_event_time,omitempty"` // of the last event counted
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RebuildRollupsProgress) Reset() {
+	*x = RebuildRollupsProgress{}
+	mi := &file_proto_statistics_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RebuildRollupsProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RebuildRollupsProgress) ProtoMessage() {}
+
+func (x *RebuildRollupsProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_statistics_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RebuildRollupsProgress.ProtoReflect.Descriptor instead.
+func (*RebuildRollupsProgress) Descriptor() ([]byte, []int) {
+	return file_proto_statistics_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RebuildRollupsProgress) GetRebuildId() string {
+	if x != nil {
+		return x.RebuildId
+	}
+	return ""
+}
+
+func (x *RebuildRollupsProgress) GetPhase() RebuildPhase {
+	if x != nil {
+		return x.Phase
+	}
+	return RebuildPhase_REBUILD_PHASE_UNSPECIFIED
+}
+
+func (x *RebuildRollupsProgress) GetEventsProcessed() int64 {
+	if x != nil {
+		return x.EventsProcessed
+	}
+	return 0
+}
+
+func (x *RebuildRollupsProgress) GetLastEventTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastEventTime
+	}
+	return nil
+}
+
+var File_proto_statistics_proto protoreflect.FileDescriptor
+
+const file_proto_statistics_proto_rawDesc = "" +
+	"\n" +
+	"\x16proto/statistics.proto\x12\n" +
+	"statistics\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"R\n" +
+	"\x1aUserOrderStatisticsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\ttime_zone\x18\x02 \x01(\tR\btimeZone\"h\n" +
+	"\x1bUserOrderStatisticsResponse\x12!\n" +
+	"\ftotal_orders\x18\x01 \x01(\x05R\vtotalOrders\x12&\n" +
+	"\x0fpeak_order_hour\x18\x02 \x01(\tR\rpeakOrderHour\"\x17\n" +
+	"\x15UserStatisticsRequest\"\xc9\x01\n" +
+	"\x16UserStatisticsResponse\x12\x1f\n" +
+	"\vtotal_users\x18\x01 \x01(\x05R\n" +
+	"totalUsers\x12,\n" +
+	"\x12daily_active_users\x18\x02 \x01(\x05R\x10dailyActiveUsers\x12.\n" +
+	"\x13weekly_active_users\x18\x03 \x01(\x05R\x11weeklyActiveUsers\x120\n" +
+	"\x14monthly_active_users\x18\x04 \x01(\x05R\x12monthlyActiveUsers\"\x9b\x01\n" +
+	"\x12ActiveUsersRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12)\n" +
+	"\x04mode\x18\x03 \x01(\x0e2\x15.statistics.CountModeR\x04mode\"\xaa\x01\n" +
+	"\x13ActiveUsersResponse\x12!\n" +
+	"\factive_users\x18\x01 \x01(\x03R\vactiveUsers\x12\x14\n" +
+	"\x05exact\x18\x02 \x01(\bR\x05exact\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"X\n" +
+	"\x05Money\x12#\n" +
+	"\rcurrency_code\x18\x01 \x01(\tR\fcurrencyCode\x12\x14\n" +
+	"\x05units\x18\x02 \x01(\x03R\x05units\x12\x14\n" +
+	"\x05nanos\x18\x03 \x01(\x05R\x05nanos\"\xd4\x01\n" +
+	"\x16SalesStatisticsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x129\n" +
+	"\vgranularity\x18\x03 \x01(\x0e2\x17.statistics.GranularityR\vgranularity\x12#\n" +
+	"\rcurrency_code\x18\x04 \x01(\tR\fcurrencyCode\"\xb4\x02\n" +
+	"\x0fSalesStatistics\x12=\n" +
+	"\fperiod_start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vperiodStart\x12+\n" +
+	"\arevenue\x18\x02 \x01(\v2\x11.statistics.MoneyR\arevenue\x12\x1f\n" +
+	"\vorder_count\x18\x03 \x01(\x03R\n" +
+	"orderCount\x122\n" +
+	"\vorder_value\x18\x04 \x01(\v2\x11.statistics.MoneyR\n" +
+	"orderValue\x12A\n" +
+	"\x13average_order_value\x18\x05 \x01(\v2\x11.statistics.MoneyR\x11averageOrderValue\x12\x1d\n" +
+	"\n" +
+	"units_sold\x18\x06 \x01(\x03R\tunitsSold\"\xa8\x01\n" +
+	"\x17SalesStatisticsResponse\x12#\n" +
+	"\rcurrency_code\x18\x01 \x01(\tR\fcurrencyCode\x121\n" +
+	"\x05total\x18\x02 \x01(\v2\x1b.statistics.SalesStatisticsR\x05total\x125\n" +
+	"\aperiods\x18\x03 \x03(\v2\x1b.statistics.SalesStatisticsR\aperiods\"\x9a\x02\n" +
+	"\x12TopProductsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x121\n" +
+	"\x06metric\x18\x03 \x01(\x0e2\x19.statistics.RankingMetricR\x06metric\x12:\n" +
+	"\tdimension\x18\x04 \x01(\x0e2\x1c.statistics.RankingDimensionR\tdimension\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12#\n" +
+	"\rcurrency_code\x18\x06 \x01(\tR\fcurrencyCode\"\xd8\x01\n" +
+	"\x10TopProductsEntry\x12\x12\n" +
+	"\x04rank\x18\x01 \x01(\x05R\x04rank\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1f\n" +
+	"\vcategory_id\x18\x04 \x01(\tR\n" +
+	"categoryId\x12\x1d\n" +
+	"\n" +
+	"units_sold\x18\x05 \x01(\x03R\tunitsSold\x12+\n" +
+	"\arevenue\x18\x06 \x01(\v2\x11.statistics.MoneyR\arevenue\x12\x1f\n" +
+	"\vorder_count\x18\a \x01(\x03R\n" +
+	"orderCount\"\xce\x01\n" +
+	"\x13TopProductsResponse\x12#\n" +
+	"\rcurrency_code\x18\x01 \x01(\tR\fcurrencyCode\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x126\n" +
+	"\aentries\x18\x04 \x03(\v2\x1c.statistics.TopProductsEntryR\aentries\"\xa7\x01\n" +
+	"\x13OrderHeatmapRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\ttime_zone\x18\x02 \x01(\tR\btimeZone\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"\\\n" +
+	"\vHeatmapCell\x12\x18\n" +
+	"\aweekday\x18\x01 \x01(\x05R\aweekday\x12\x12\n" +
+	"\x04hour\x18\x02 \x01(\x05R\x04hour\x12\x1f\n" +
+	"\vorder_count\x18\x03 \x01(\x03R\n" +
+	"orderCount\"\x85\x01\n" +
+	"\x14OrderHeatmapResponse\x12\x1b\n" +
+	"\ttime_zone\x18\x01 \x01(\tR\btimeZone\x12!\n" +
+	"\ftotal_orders\x18\x02 \x01(\x03R\vtotalOrders\x12-\n" +
+	"\x05cells\x18\x03 \x03(\v2\x17.statistics.HeatmapCellR\x05cells\"\xca\x01\n" +
+	"\x17RetentionCohortsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x129\n" +
+	"\vgranularity\x18\x03 \x01(\x0e2\x17.statistics.GranularityR\vgranularity\x12\x18\n" +
+	"\aperiods\x18\x04 \x01(\x05R\aperiods\"\x9e\x01\n" +
+	"\x0fRetentionCohort\x12=\n" +
+	"\fperiod_start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vperiodStart\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x1a\n" +
+	"\bretained\x18\x03 \x03(\x03R\bretained\x12\x1c\n" +
+	"\tretention\x18\x04 \x03(\x01R\tretention\"\xa6\x01\n" +
+	"\x18RetentionCohortsResponse\x129\n" +
+	"\vgranularity\x18\x01 \x01(\x0e2\x17.statistics.GranularityR\vgranularity\x12\x18\n" +
+	"\aperiods\x18\x02 \x01(\x05R\aperiods\x125\n" +
+	"\acohorts\x18\x03 \x03(\v2\x1b.statistics.RetentionCohortR\acohorts\"\x95\x03\n" +
+	"\x0fCustomerProfile\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x125\n" +
+	"\asegment\x18\x02 \x01(\x0e2\x1b.statistics.CustomerSegmentR\asegment\x12\x1f\n" +
+	"\vorder_count\x18\x03 \x01(\x03R\n" +
+	"orderCount\x12@\n" +
+	"\x0efirst_order_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ffirstOrderAt\x12>\n" +
+	"\rlast_order_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vlastOrderAt\x12!\n" +
+	"\frecency_days\x18\x06 \x01(\x05R\vrecencyDays\x122\n" +
+	"\vorder_value\x18\a \x03(\v2\x11.statistics.MoneyR\n" +
+	"orderValue\x128\n" +
+	"\x0elifetime_value\x18\b \x03(\v2\x11.statistics.MoneyR\rlifetimeValue\"1\n" +
+	"\x16CustomerProfileRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"P\n" +
+	"\x17CustomerProfileResponse\x125\n" +
+	"\aprofile\x18\x01 \x01(\v2\x1b.statistics.CustomerProfileR\aprofile\"\x83\x01\n" +
+	"\x15SegmentMembersRequest\x125\n" +
+	"\asegment\x18\x01 \x01(\x0e2\x1b.statistics.CustomerSegmentR\asegment\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\xb2\x01\n" +
+	"\x16SegmentMembersResponse\x125\n" +
+	"\asegment\x18\x01 \x01(\x0e2\x1b.statistics.CustomerSegmentR\asegment\x129\n" +
+	"\tcustomers\x18\x02 \x03(\v2\x1b.statistics.CustomerProfileR\tcustomers\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\"M\n" +
+	"\x16RelatedProductsRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\xe9\x01\n" +
+	"\x0eRelatedProduct\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
+	"\vcategory_id\x18\x03 \x01(\tR\n" +
+	"categoryId\x12\x16\n" +
+	"\x06orders\x18\x04 \x01(\x03R\x06orders\x12\x18\n" +
+	"\asupport\x18\x05 \x01(\x01R\asupport\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x06 \x01(\x01R\n" +
+	"confidence\x121\n" +
+	"\x06source\x18\a \x01(\x0e2\x19.statistics.RelatedSourceR\x06source\"\x88\x01\n" +
+	"\x17RelatedProductsResponse\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x16\n" +
+	"\x06orders\x18\x02 \x01(\x03R\x06orders\x126\n" +
+	"\bproducts\x18\x03 \x03(\v2\x1a.statistics.RelatedProductR\bproducts\"\xa3\x01\n" +
+	"\x17ConversionFunnelRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12,\n" +
+	"\x05steps\x18\x03 \x03(\x0e2\x16.statistics.FunnelStepR\x05steps\"\xb3\x01\n" +
+	"\x14FunnelStepStatistics\x12*\n" +
+	"\x04step\x18\x01 \x01(\x0e2\x16.statistics.FunnelStepR\x04step\x12\x1a\n" +
+	"\bvisitors\x18\x02 \x01(\x03R\bvisitors\x12\x1e\n" +
+	"\n" +
+	"conversion\x18\x03 \x01(\x01R\n" +
+	"conversion\x12\x19\n" +
+	"\bdrop_off\x18\x04 \x01(\x01R\adropOff\x12\x18\n" +
+	"\aoverall\x18\x05 \x01(\x01R\aoverall\"\xae\x01\n" +
+	"\x18ConversionFunnelResponse\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x126\n" +
+	"\x05steps\x18\x03 \x03(\v2 .statistics.FunnelStepStatisticsR\x05steps\"V\n" +
+	"\x16WatchStatisticsRequest\x12<\n" +
+	"\fmin_interval\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\vminInterval\"Z\n" +
+	"\x0fLowStockProduct\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05stock\x18\x03 \x01(\x05R\x05stock\"\x99\x02\n" +
+	"\x0eLiveStatistics\x12*\n" +
+	"\x02at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\x12*\n" +
+	"\x11orders_per_minute\x18\x02 \x01(\x01R\x0fordersPerMinute\x126\n" +
+	"\rrevenue_today\x18\x03 \x03(\v2\x11.statistics.MoneyR\frevenueToday\x12,\n" +
+	"\x12active_users_today\x18\x04 \x01(\x03R\x10activeUsersToday\x12I\n" +
+	"\x12low_stock_products\x18\x05 \x03(\v2\x1b.statistics.LowStockProductR\x10lowStockProducts\"s\n" +
+	"\x15RebuildRollupsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"\xd6\x01\n" +
+	"\x16RebuildRollupsProgress\x12\x1d\n" +
+	"\n" +
+	"rebuild_id\x18\x01 \x01(\tR\trebuildId\x12.\n" +
+	"\x05phase\x18\x02 \x01(\x0e2\x18.statistics.RebuildPhaseR\x05phase\x12)\n" +
+	"\x10events_processed\x18\x03 \x01(\x03R\x0feventsProcessed\x12B\n" +
+	"\x0flast_event_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rlastEventTime*R\n" +
+	"\tCountMode\x12\x13\n" +
+	"\x0fCOUNT_MODE_AUTO\x10\x00\x12\x14\n" +
+	"\x10COUNT_MODE_EXACT\x10\x01\x12\x1a\n" +
+	"\x16COUNT_MODE_APPROXIMATE\x10\x02*l\n" +
+	"\vGranularity\x12\x1b\n" +
+	"\x17GRANULARITY_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fGRANULARITY_DAY\x10\x01\x12\x14\n" +
+	"\x10GRANULARITY_WEEK\x10\x02\x12\x15\n" +
+	"\x11GRANULARITY_MONTH\x10\x03*\x8a\x01\n" +
+	"\rRankingMetric\x12\x1e\n" +
+	"\x1aRANKING_METRIC_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19RANKING_METRIC_UNITS_SOLD\x10\x01\x12\x1a\n" +
+	"\x16RANKING_METRIC_REVENUE\x10\x02\x12\x1e\n" +
+	"\x1aRANKING_METRIC_ORDER_COUNT\x10\x03*t\n" +
+	"\x10RankingDimension\x12!\n" +
+	"\x1dRANKING_DIMENSION_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19RANKING_DIMENSION_PRODUCT\x10\x01\x12\x1e\n" +
+	"\x1aRANKING_DIMENSION_CATEGORY\x10\x02*\x8f\x02\n" +
+	"\x0fCustomerSegment\x12 \n" +
+	"\x1cCUSTOMER_SEGMENT_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aCUSTOMER_SEGMENT_CHAMPIONS\x10\x01\x12\x1a\n" +
+	"\x16CUSTOMER_SEGMENT_LOYAL\x10\x02\x12\x18\n" +
+	"\x14CUSTOMER_SEGMENT_NEW\x10\x03\x12\x1e\n" +
+	"\x1aCUSTOMER_SEGMENT_PROMISING\x10\x04\x12$\n" +
+	" CUSTOMER_SEGMENT_NEEDS_ATTENTION\x10\x05\x12\x1c\n" +
+	"\x18CUSTOMER_SEGMENT_AT_RISK\x10\x06\x12 \n" +
+	"\x1cCUSTOMER_SEGMENT_HIBERNATING\x10\a*{\n" +
+	"\rRelatedSource\x12\x1e\n" +
+	"\x1aRELATED_SOURCE_UNSPECIFIED\x10\x00\x12\"\n" +
+	"\x1eRELATED_SOURCE_BOUGHT_TOGETHER\x10\x01\x12&\n" +
+	"\"RELATED_SOURCE_CATEGORY_TOP_SELLER\x10\x02*\xd0\x01\n" +
+	"\n" +
+	"FunnelStep\x12\x1b\n" +
+	"\x17FUNNEL_STEP_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cFUNNEL_STEP_CATEGORY_BROWSED\x10\x01\x12\x1e\n" +
+	"\x1aFUNNEL_STEP_PRODUCT_VIEWED\x10\x02\x12\x1d\n" +
+	"\x19FUNNEL_STEP_ORDER_CREATED\x10\x03\x12!\n" +
+	"\x1dFUNNEL_STEP_PAYMENT_ATTEMPTED\x10\x04\x12!\n" +
+	"\x1dFUNNEL_STEP_PAYMENT_COMPLETED\x10\x05*\x91\x01\n" +
+	"\fRebuildPhase\x12\x1d\n" +
+	"\x19REBUILD_PHASE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12REBUILD_PHASE_SCAN\x10\x01\x12\x1a\n" +
+	"\x16REBUILD_PHASE_CATCH_UP\x10\x02\x12\x16\n" +
+	"\x12REBUILD_PHASE_SWAP\x10\x03\x12\x16\n" +
+	"\x12REBUILD_PHASE_DONE\x10\x042\xc5\t\n" +
 	"\x11StatisticsService\x12j\n" +
 	"\x17GetUserOrdersStatistics\x12&.statistics.UserOrderStatisticsRequest\x1a'.statistics.UserOrderStatisticsResponse\x12Z\n" +
-	"\x11GetUserStatistics\x12!.statistics.UserStatisticsRequest\x1a\".statistics.UserStatisticsResponseBOZMgithub.com/Neroframe/ecommerce-platform/statistics-service/proto;statisticspbb\x06proto3"
+	"\x11GetUserStatistics\x12!.statistics.UserStatisticsRequest\x1a\".statistics.UserStatisticsResponse\x12]\n" +
+	"\x12GetSalesStatistics\x12\".statistics.SalesStatisticsRequest\x1a#.statistics.SalesStatisticsResponse\x12Q\n" +
+	"\x0eGetTopProducts\x12\x1e.statistics.TopProductsRequest\x1a\x1f.statistics.TopProductsResponse\x12T\n" +
+	"\x0fGetOrderHeatmap\x12\x1f.statistics.OrderHeatmapRequest\x1a .statistics.OrderHeatmapResponse\x12Q\n" +
+	"\x0eGetActiveUsers\x12\x1e.statistics.ActiveUsersRequest\x1a\x1f.statistics.ActiveUsersResponse\x12`\n" +
+	"\x13GetRetentionCohorts\x12#.statistics.RetentionCohortsRequest\x1a$.statistics.RetentionCohortsResponse\x12]\n" +
+	"\x12GetCustomerProfile\x12\".statistics.CustomerProfileRequest\x1a#.statistics.CustomerProfileResponse\x12[\n" +
+	"\x12ListSegmentMembers\x12!.statistics.SegmentMembersRequest\x1a\".statistics.SegmentMembersResponse\x12`\n" +
+	"\x13GetConversionFunnel\x12#.statistics.ConversionFunnelRequest\x1a$.statistics.ConversionFunnelResponse\x12]\n" +
+	"\x12GetRelatedProducts\x12\".statistics.RelatedProductsRequest\x1a#.statistics.RelatedProductsResponse\x12S\n" +
+	"\x0fWatchStatistics\x12\".statistics.WatchStatisticsRequest\x1a\x1a.statistics.LiveStatistics0\x01\x12Y\n" +
+	"\x0eRebuildRollups\x12!.statistics.RebuildRollupsRequest\x1a\".statistics.RebuildRollupsProgress0\x01BOZMgithub.com/Neroframe/ecommerce-platform/statistics-service/proto;statisticspbb\x06proto3"
 
 var (
 	file_proto_statistics_proto_rawDescOnce sync.Once
@@ -230,23 +2983,144 @@ func file_proto_statistics_proto_rawDescGZIP() []byte {
 	return file_proto_statistics_proto_rawDescData
 }
 
-var file_proto_statistics_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_statistics_proto_enumTypes = make([]protoimpl.EnumInfo, 8)
+var file_proto_statistics_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
 var file_proto_statistics_proto_goTypes = []any{
-	(*UserOrderStatisticsRequest)(nil),  // 0: statistics.UserOrderStatisticsRequest
-	(*UserOrderStatisticsResponse)(nil), // 1: statistics.UserOrderStatisticsResponse
-	(*UserStatisticsRequest)(nil),       // 2: statistics.UserStatisticsRequest
-	(*UserStatisticsResponse)(nil),      // 3: statistics.UserStatisticsResponse
+	(CountMode)(0),                      // 0: statistics.CountMode
+	(Granularity)(0),                    // 1: statistics.Granularity
+	(RankingMetric)(0),                  // 2: statistics.RankingMetric
+	(RankingDimension)(0),               // 3: statistics.RankingDimension
+	(CustomerSegment)(0),                // 4: statistics.CustomerSegment
+	(RelatedSource)(0),                  // 5: statistics.RelatedSource
+	(FunnelStep)(0),                     // 6: statistics.FunnelStep
+	(RebuildPhase)(0),                   // 7: statistics.RebuildPhase
+	(*UserOrderStatisticsRequest)(nil),  // 8: statistics.UserOrderStatisticsRequest
+	(*UserOrderStatisticsResponse)(nil), // 9: statistics.UserOrderStatisticsResponse
+	(*UserStatisticsRequest)(nil),       // 10: statistics.UserStatisticsRequest
+	(*UserStatisticsResponse)(nil),      // 11: statistics.UserStatisticsResponse
+	(*ActiveUsersRequest)(nil),          // 12: statistics.ActiveUsersRequest
+	(*ActiveUsersResponse)(nil),         // 13: statistics.ActiveUsersResponse
+	(*Money)(nil),                       // 14: statistics.Money
+	(*SalesStatisticsRequest)(nil),      // 15: statistics.SalesStatisticsRequest
+	(*SalesStatistics)(nil),             // 16: statistics.SalesStatistics
+	(*SalesStatisticsResponse)(nil),     // 17: statistics.SalesStatisticsResponse
+	(*TopProductsRequest)(nil),          // 18: statistics.TopProductsRequest
+	(*TopProductsEntry)(nil),            // 19: statistics.TopProductsEntry
+	(*TopProductsResponse)(nil),         // 20: statistics.TopProductsResponse
+	(*OrderHeatmapRequest)(nil),         // 21: statistics.OrderHeatmapRequest
+	(*HeatmapCell)(nil),                 // 22: statistics.HeatmapCell
+	(*OrderHeatmapResponse)(nil),        // 23: statistics.OrderHeatmapResponse
+	(*RetentionCohortsRequest)(nil),     // 24: statistics.RetentionCohortsRequest
+	(*RetentionCohort)(nil),             // 25: statistics.RetentionCohort
+	(*RetentionCohortsResponse)(nil),    // 26: statistics.RetentionCohortsResponse
+	(*CustomerProfile)(nil),             // 27: statistics.CustomerProfile
+	(*CustomerProfileRequest)(nil),      // 28: statistics.CustomerProfileRequest
+	(*CustomerProfileResponse)(nil),     // 29: statistics.CustomerProfileResponse
+	(*SegmentMembersRequest)(nil),       // 30: statistics.SegmentMembersRequest
+	(*SegmentMembersResponse)(nil),      // 31: statistics.SegmentMembersResponse
+	(*RelatedProductsRequest)(nil),      // 32: statistics.RelatedProductsRequest
+	(*RelatedProduct)(nil),              // 33: statistics.RelatedProduct
+	(*RelatedProductsResponse)(nil),     // 34: statistics.RelatedProductsResponse
+	(*ConversionFunnelRequest)(nil),     // 35: statistics.ConversionFunnelRequest
+	(*FunnelStepStatistics)(nil),        // 36: statistics.FunnelStepStatistics
+	(*ConversionFunnelResponse)(nil),    // 37: statistics.ConversionFunnelResponse
+	(*WatchStatisticsRequest)(nil),      // 38: statistics.WatchStatisticsRequest
+	(*LowStockProduct)(nil),             // 39: statistics.LowStockProduct
+	(*LiveStatistics)(nil),              // 40: statistics.LiveStatistics
+	(*RebuildRollupsRequest)(nil),       // 41: statistics.RebuildRollupsRequest
+	(*RebuildRollupsProgress)(nil),      // 42: statistics.RebuildRollupsProgress
+	(*timestamppb.Timestamp)(nil),       // 43: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),         // 44: google.protobuf.Duration
 }
 var file_proto_statistics_proto_depIdxs = []int32{
-	0, // 0: statistics.StatisticsService.GetUserOrdersStatistics:input_type -> statistics.UserOrderStatisticsRequest
-	2, // 1: statistics.StatisticsService.GetUserStatistics:input_type -> statistics.UserStatisticsRequest
-	1, // 2: statistics.StatisticsService.GetUserOrdersStatistics:output_type -> statistics.UserOrderStatisticsResponse
-	3, // 3: statistics.StatisticsService.GetUserStatistics:output_type -> statistics.UserStatisticsResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	43, // 0: statistics.ActiveUsersRequest.from:type_name -> google.protobuf.Timestamp
+	43, // 1: statistics.ActiveUsersRequest.to:type_name -> google.protobuf.Timestamp
+	0,  // 2: statistics.ActiveUsersRequest.mode:type_name -> statistics.CountMode
+	43, // 3: statistics.ActiveUsersResponse.from:type_name -> google.protobuf.Timestamp
+	43, // 4: statistics.ActiveUsersResponse.to:type_name -> google.protobuf.Timestamp
+	43, // 5: statistics.SalesStatisticsRequest.from:type_name -> google.protobuf.Timestamp
+	43, // 6: statistics.SalesStatisticsRequest.to:type_name -> google.protobuf.Timestamp
+	1,  // 7: statistics.SalesStatisticsRequest.granularity:type_name -> statistics.Granularity
+	43, // 8: statistics.SalesStatistics.period_start:type_name -> google.protobuf.Timestamp
+	14, // 9: statistics.SalesStatistics.revenue:type_name -> statistics.Money
+	14, // 10: statistics.SalesStatistics.order_value:type_name -> statistics.Money
+	14, // 11: statistics.SalesStatistics.average_order_value:type_name -> statistics.Money
+	16, // 12: statistics.SalesStatisticsResponse.total:type_name -> statistics.SalesStatistics
+	16, // 13: statistics.SalesStatisticsResponse.periods:type_name -> statistics.SalesStatistics
+	43, // 14: statistics.TopProductsRequest.from:type_name -> google.protobuf.Timestamp
+	43, // 15: statistics.TopProductsRequest.to:type_name -> google.protobuf.Timestamp
+	2,  // 16: statistics.TopProductsRequest.metric:type_name -> statistics.RankingMetric
+	3,  // 17: statistics.TopProductsRequest.dimension:type_name -> statistics.RankingDimension
+	14, // 18: statistics.TopProductsEntry.revenue:type_name -> statistics.Money
+	43, // 19: statistics.TopProductsResponse.from:type_name -> google.protobuf.Timestamp
+	43, // 20: statistics.TopProductsResponse.to:type_name -> google.protobuf.Timestamp
+	19, // 21: statistics.TopProductsResponse.entries:type_name -> statistics.TopProductsEntry
+	43, // 22: statistics.OrderHeatmapRequest.from:type_name -> google.protobuf.Timestamp
+	43, // 23: statistics.OrderHeatmapRequest.to:type_name -> google.protobuf.Timestamp
+	22, // 24: statistics.OrderHeatmapResponse.cells:type_name -> statistics.HeatmapCell
+	43, // 25: statistics.RetentionCohortsRequest.from:type_name -> google.protobuf.Timestamp
+	43, // 26: statistics.RetentionCohortsRequest.to:type_name -> google.protobuf.Timestamp
+	1,  // 27: statistics.RetentionCohortsRequest.granularity:type_name -> statistics.Granularity
+	43, // 28: statistics.RetentionCohort.period_start:type_name -> google.protobuf.Timestamp
+	1,  // 29: statistics.RetentionCohortsResponse.granularity:type_name -> statistics.Granularity
+	25, // 30: statistics.RetentionCohortsResponse.cohorts:type_name -> statistics.RetentionCohort
+	4,  // 31: statistics.CustomerProfile.segment:type_name -> statistics.CustomerSegment
+	43, // 32: statistics.CustomerProfile.first_order_at:type_name -> google.protobuf.Timestamp
+	43, // 33: statistics.CustomerProfile.last_order_at:type_name -> google.protobuf.Timestamp
+	14, // 34: statistics.CustomerProfile.order_value:type_name -> statistics.Money
+	14, // 35: statistics.CustomerProfile.lifetime_value:type_name -> statistics.Money
+	27, // 36: statistics.CustomerProfileResponse.profile:type_name -> statistics.CustomerProfile
+	4,  // 37: statistics.SegmentMembersRequest.segment:type_name -> statistics.CustomerSegment
+	4,  // 38: statistics.SegmentMembersResponse.segment:type_name -> statistics.CustomerSegment
+	27, // 39: statistics.SegmentMembersResponse.customers:type_name -> statistics.CustomerProfile
+	5,  // 40: statistics.RelatedProduct.source:type_name -> statistics.RelatedSource
+	33, // 41: statistics.RelatedProductsResponse.products:type_name -> statistics.RelatedProduct
+	43, // 42: statistics.ConversionFunnelRequest.from:type_name -> google.protobuf.Timestamp
+	43, // 43: statistics.ConversionFunnelRequest.to:type_name -> google.protobuf.Timestamp
+	6,  // 44: statistics.ConversionFunnelRequest.steps:type_name -> statistics.FunnelStep
+	6,  // 45: statistics.FunnelStepStatistics.step:type_name -> statistics.FunnelStep
+	43, // 46: statistics.ConversionFunnelResponse.from:type_name -> google.protobuf.Timestamp
+	43, // 47: statistics.ConversionFunnelResponse.to:type_name -> google.protobuf.Timestamp
+	36, // 48: statistics.ConversionFunnelResponse.steps:type_name -> statistics.FunnelStepStatistics
+	44, // 49: statistics.WatchStatisticsRequest.min_interval:type_name -> google.protobuf.Duration
+	43, // 50: statistics.LiveStatistics.at:type_name -> google.protobuf.Timestamp
+	14, // 51: statistics.LiveStatistics.revenue_today:type_name -> statistics.Money
+	39, // 52: statistics.LiveStatistics.low_stock_products:type_name -> statistics.LowStockProduct
+	43, // 53: statistics.RebuildRollupsRequest.from:type_name -> google.protobuf.Timestamp
+	43, // 54: statistics.RebuildRollupsRequest.to:type_name -> google.protobuf.Timestamp
+	7,  // 55: statistics.RebuildRollupsProgress.phase:type_name -> statistics.RebuildPhase
+	43, // 56: statistics.RebuildRollupsProgress.last_event_time:type_name -> google.protobuf.Timestamp
+	8,  // 57: statistics.StatisticsService.GetUserOrdersStatistics:input_type -> statistics.UserOrderStatisticsRequest
+	10, // 58: statistics.StatisticsService.GetUserStatistics:input_type -> statistics.UserStatisticsRequest
+	15, // 59: statistics.StatisticsService.GetSalesStatistics:input_type -> statistics.SalesStatisticsRequest
+	18, // 60: statistics.StatisticsService.GetTopProducts:input_type -> statistics.TopProductsRequest
+	21, // 61: statistics.StatisticsService.GetOrderHeatmap:input_type -> statistics.OrderHeatmapRequest
+	12, // 62: statistics.StatisticsService.GetActiveUsers:input_type -> statistics.ActiveUsersRequest
+	24, // 63: statistics.StatisticsService.GetRetentionCohorts:input_type -> statistics.RetentionCohortsRequest
+	28, // 64: statistics.StatisticsService.GetCustomerProfile:input_type -> statistics.CustomerProfileRequest
+	30, // 65: statistics.StatisticsService.ListSegmentMembers:input_type -> statistics.SegmentMembersRequest
+	35, // 66: statistics.StatisticsService.GetConversionFunnel:input_type -> statistics.ConversionFunnelRequest
+	32, // 67: statistics.StatisticsService.GetRelatedProducts:input_type -> statistics.RelatedProductsRequest
+	38, // 68: statistics.StatisticsService.WatchStatistics:input_type -> statistics.WatchStatisticsRequest
+	41, // 69: statistics.StatisticsService.RebuildRollups:input_type -> statistics.RebuildRollupsRequest
+	9,  // 70: statistics.StatisticsService.GetUserOrdersStatistics:output_type -> statistics.UserOrderStatisticsResponse
+	11, // 71: statistics.StatisticsService.GetUserStatistics:output_type -> statistics.UserStatisticsResponse
+	17, // 72: statistics.StatisticsService.GetSalesStatistics:output_type -> statistics.SalesStatisticsResponse
+	20, // 73: statistics.StatisticsService.GetTopProducts:output_type -> statistics.TopProductsResponse
+	23, // 74: statistics.StatisticsService.GetOrderHeatmap:output_type -> statistics.OrderHeatmapResponse
+	13, // 75: statistics.StatisticsService.GetActiveUsers:output_type -> statistics.ActiveUsersResponse
+	26, // 76: statistics.StatisticsService.GetRetentionCohorts:output_type -> statistics.RetentionCohortsResponse
+	29, // 77: statistics.StatisticsService.GetCustomerProfile:output_type -> statistics.CustomerProfileResponse
+	31, // 78: statistics.StatisticsService.ListSegmentMembers:output_type -> statistics.SegmentMembersResponse
+	37, // 79: statistics.StatisticsService.GetConversionFunnel:output_type -> statistics.ConversionFunnelResponse
+	34, // 80: statistics.StatisticsService.GetRelatedProducts:output_type -> statistics.RelatedProductsResponse
+	40, // 81: statistics.StatisticsService.WatchStatistics:output_type -> statistics.LiveStatistics
+	42, // 82: statistics.StatisticsService.RebuildRollups:output_type -> statistics.RebuildRollupsProgress
+	70, // [70:83] is the sub-list for method output_type
+	57, // [57:70] is the sub-list for method input_type
+	57, // [57:57] is the sub-list for extension type_name
+	57, // [57:57] is the sub-list for extension extendee
+	0,  // [0:57] is the sub-list for field type_name
 }
 
 func init() { file_proto_statistics_proto_init() }
@@ -259,13 +3133,14 @@ func file_proto_statistics_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_statistics_proto_rawDesc), len(file_proto_statistics_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      8,
+			NumMessages:   35,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_statistics_proto_goTypes,
 		DependencyIndexes: file_proto_statistics_proto_depIdxs,
+		EnumInfos:         file_proto_statistics_proto_enumTypes,
 		MessageInfos:      file_proto_statistics_proto_msgTypes,
 	}.Build()
 	File_proto_statistics_proto = out.File